@@ -20,7 +20,6 @@ package client
 import (
 	"context"
 	"fmt"
-	"net/url"
 	"strings"
 
 	"github.com/bloodhoundad/azurehound/client/query"
@@ -52,51 +51,10 @@ func (s *azureClient) GetAzureADAppRoleAssignments(ctx context.Context, serviceP
 }
 
 func (s *azureClient) ListAzureADAppRoleAssignments(ctx context.Context, servicePrincipal, filter, search, orderBy, expand string, selectCols []string) <-chan azure.AppRoleAssignmentResult {
-	out := make(chan azure.AppRoleAssignmentResult)
-
-	go func() {
-		defer close(out)
-
-		var (
-			errResult = azure.AppRoleAssignmentResult{}
-			nextLink  string
-		)
-
-		if list, err := s.GetAzureADAppRoleAssignments(ctx, servicePrincipal, filter, search, orderBy, expand, selectCols, 999, false); err != nil {
-			errResult.Error = err
-			out <- errResult
-		} else {
-			for _, u := range list.Value {
-				out <- azure.AppRoleAssignmentResult{Ok: u}
-			}
-
-			nextLink = list.NextLink
-			for nextLink != "" {
-				var list azure.AppRoleAssignmentList
-				if url, err := url.Parse(nextLink); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else if req, err := rest.NewRequest(ctx, "GET", url, nil, nil, nil); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else if res, err := s.msgraph.Send(req); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else if err := rest.Decode(res.Body, &list); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else {
-					for _, u := range list.Value {
-						out <- azure.AppRoleAssignmentResult{Ok: u}
-					}
-					nextLink = list.NextLink
-				}
-			}
-		}
-	}()
-	return out
+	return paginate(ctx, nextLinks(s.msgraph, func() (page[azure.AppRoleAssignment], error) {
+		list, err := s.GetAzureADAppRoleAssignments(ctx, servicePrincipal, filter, search, orderBy, expand, selectCols, graphPageSize, false)
+		return page[azure.AppRoleAssignment]{Value: list.Value, NextLink: list.NextLink}, err
+	}), func(u azure.AppRoleAssignment, err error) azure.AppRoleAssignmentResult {
+		return azure.AppRoleAssignmentResult{Error: err, Ok: u}
+	})
 }
@@ -19,8 +19,8 @@ package client
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
-	"net/url"
 	"strings"
 
 	"github.com/bloodhoundad/azurehound/client/query"
@@ -101,167 +101,39 @@ func (s *azureClient) GetAzureADApps(ctx context.Context, filter, search, orderB
 }
 
 func (s *azureClient) ListAzureADApps(ctx context.Context, filter, search, orderBy, expand string, selectCols []string) <-chan azure.ApplicationResult {
-	out := make(chan azure.ApplicationResult)
-
-	go func() {
-		defer close(out)
-
-		var (
-			errResult = azure.ApplicationResult{}
-			nextLink  string
-		)
-
-		if list, err := s.GetAzureADApps(ctx, filter, search, orderBy, expand, selectCols, 999, false); err != nil {
-			errResult.Error = err
-			out <- errResult
-		} else {
-			for _, u := range list.Value {
-				out <- azure.ApplicationResult{Ok: u}
-			}
-
-			nextLink = list.NextLink
-			for nextLink != "" {
-				var list azure.ApplicationList
-				if url, err := url.Parse(nextLink); err != nil {
-					errResult.Error = err
-					out <- errResult
-					return
-				} else if req, err := rest.NewRequest(ctx, "GET", url, nil, nil, nil); err != nil {
-					errResult.Error = err
-					out <- errResult
-					return
-				} else if res, err := s.msgraph.Send(req); err != nil {
-					errResult.Error = err
-					out <- errResult
-					return
-				} else if err := rest.Decode(res.Body, &list); err != nil {
-					errResult.Error = err
-					out <- errResult
-					return
-				} else {
-					for _, u := range list.Value {
-						out <- azure.ApplicationResult{Ok: u}
-					}
-					nextLink = list.NextLink
-				}
-			}
-		}
-	}()
-	return out
+	return paginate(ctx, nextLinks(s.msgraph, func() (page[azure.Application], error) {
+		list, err := s.GetAzureADApps(ctx, filter, search, orderBy, expand, selectCols, graphPageSize, false)
+		return page[azure.Application]{Value: list.Value, NextLink: list.NextLink}, err
+	}), func(u azure.Application, err error) azure.ApplicationResult {
+		return azure.ApplicationResult{Error: err, Ok: u}
+	})
 }
 
 func (s *azureClient) ListAzureADAppOwners(ctx context.Context, objectId string, filter, search, orderBy string, selectCols []string) <-chan azure.AppOwnerResult {
-	out := make(chan azure.AppOwnerResult)
-
-	go func() {
-		defer close(out)
-
-		var (
-			errResult = azure.AppOwnerResult{}
-			nextLink  string
-		)
-
-		if list, err := s.GetAzureADAppOwners(ctx, objectId, filter, search, orderBy, selectCols, 999, false); err != nil {
-			errResult.Error = err
-			out <- errResult
-		} else {
-			for _, u := range list.Value {
-				out <- azure.AppOwnerResult{
-					AppId: objectId,
-					Ok:    u,
-				}
-			}
-
-			nextLink = list.NextLink
-			for nextLink != "" {
-				var list azure.DirectoryObjectList
-				if url, err := url.Parse(nextLink); err != nil {
-					errResult.Error = err
-					out <- errResult
-					return
-				} else if req, err := rest.NewRequest(ctx, "GET", url, nil, nil, nil); err != nil {
-					errResult.Error = err
-					out <- errResult
-					return
-				} else if res, err := s.msgraph.Send(req); err != nil {
-					errResult.Error = err
-					out <- errResult
-					return
-				} else if err := rest.Decode(res.Body, &list); err != nil {
-					errResult.Error = err
-					out <- errResult
-					return
-				} else {
-					for _, u := range list.Value {
-						out <- azure.AppOwnerResult{
-							AppId: objectId,
-							Ok:    u,
-						}
-					}
-					nextLink = list.NextLink
-				}
-			}
+	ctx = rest.WithBatching(ctx)
+
+	return paginate(ctx, nextLinks(s.msgraph, func() (page[json.RawMessage], error) {
+		list, err := s.GetAzureADAppOwners(ctx, objectId, filter, search, orderBy, selectCols, graphPageSize, false)
+		return page[json.RawMessage]{Value: list.Value, NextLink: list.NextLink}, err
+	}), func(u json.RawMessage, err error) azure.AppOwnerResult {
+		return azure.AppOwnerResult{
+			Error: err,
+			AppId: objectId,
+			Ok:    u,
 		}
-	}()
-	return out
+	})
 }
 
 func (s *azureClient) ListAzureADAppMemberObjects(ctx context.Context, objectId string, securityEnabledOnly bool) <-chan azure.MemberObjectResult {
-	out := make(chan azure.MemberObjectResult)
-
-	go func() {
-		defer close(out)
-
-		var (
-			errResult = azure.MemberObjectResult{
-				ParentId:   objectId,
-				ParentType: string(enums.EntityApplication),
-			}
-			nextLink string
-		)
-		if list, err := s.GetAzureADAppMemberObjects(ctx, objectId, securityEnabledOnly); err != nil {
-			errResult.Error = err
-			out <- errResult
-		} else {
-			for _, u := range list.Value {
-				out <- azure.MemberObjectResult{
-					ParentId:   objectId,
-					ParentType: string(enums.EntityApplication),
-					Ok:         u,
-				}
-			}
-
-			nextLink = list.NextLink
-			for nextLink != "" {
-				var list azure.MemberObjectList
-				if url, err := url.Parse(nextLink); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else if req, err := rest.NewRequest(ctx, "GET", url, nil, nil, nil); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else if res, err := s.msgraph.Send(req); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else if err := rest.Decode(res.Body, &list); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else {
-					for _, u := range list.Value {
-						out <- azure.MemberObjectResult{
-							ParentId:   objectId,
-							ParentType: string(enums.EntityApplication),
-							Ok:         u,
-						}
-					}
-					nextLink = list.NextLink
-				}
-			}
+	return paginate(ctx, nextLinks(s.msgraph, func() (page[json.RawMessage], error) {
+		list, err := s.GetAzureADAppMemberObjects(ctx, objectId, securityEnabledOnly)
+		return page[json.RawMessage]{Value: list.Value, NextLink: list.NextLink}, err
+	}), func(u json.RawMessage, err error) azure.MemberObjectResult {
+		return azure.MemberObjectResult{
+			Error:      err,
+			ParentId:   objectId,
+			ParentType: string(enums.EntityApplication),
+			Ok:         u,
 		}
-	}()
-	return out
+	})
 }
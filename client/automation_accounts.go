@@ -20,7 +20,6 @@ package client
 import (
 	"context"
 	"fmt"
-	"net/url"
 
 	"github.com/bloodhoundad/azurehound/client/query"
 	"github.com/bloodhoundad/azurehound/client/rest"
@@ -61,56 +60,10 @@ func (s *azureClient) GetAzureAutomationAccounts(ctx context.Context, subscripti
 }
 
 func (s *azureClient) ListAzureAutomationAccounts(ctx context.Context, subscriptionId string) <-chan azure.AutomationAccountResult {
-	out := make(chan azure.AutomationAccountResult)
-
-	go func() {
-		defer close(out)
-
-		var (
-			errResult = azure.AutomationAccountResult{
-				SubscriptionId: subscriptionId,
-			}
-			nextLink string
-		)
-
-		if result, err := s.GetAzureAutomationAccounts(ctx, subscriptionId); err != nil {
-			errResult.Error = err
-			out <- errResult
-		} else {
-			for _, u := range result.Value {
-				out <- azure.AutomationAccountResult{SubscriptionId: subscriptionId, Ok: u}
-			}
-
-			nextLink = result.NextLink
-			for nextLink != "" {
-				var list azure.AutomationAccountList
-				if url, err := url.Parse(nextLink); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else if req, err := rest.NewRequest(ctx, "GET", url, nil, nil, nil); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else if res, err := s.resourceManager.Send(req); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else if err := rest.Decode(res.Body, &list); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else {
-					for _, u := range list.Value {
-						out <- azure.AutomationAccountResult{
-							SubscriptionId: "/subscriptions/" + subscriptionId,
-							Ok:             u,
-						}
-					}
-					nextLink = list.NextLink
-				}
-			}
-		}
-	}()
-	return out
+	return paginate(ctx, nextLinks(s.resourceManager, func() (page[azure.AutomationAccount], error) {
+		result, err := s.GetAzureAutomationAccounts(ctx, subscriptionId)
+		return page[azure.AutomationAccount]{Value: result.Value, NextLink: result.NextLink}, err
+	}), func(u azure.AutomationAccount, err error) azure.AutomationAccountResult {
+		return azure.AutomationAccountResult{Error: err, SubscriptionId: subscriptionId, Ok: u}
+	})
 }
@@ -36,21 +36,30 @@ func NewClient(config config.Config) (AzureClient, error) {
 	} else if resourceManager, err := rest.NewRestClient(config.ResourceManagerUrl(), config); err != nil {
 		return nil, err
 	} else {
+		// per-object relationship requests are combined into JSON batches, except when recording or replaying since which
+		// requests share a batch depends on their timing and a replay couldn't match the batches of the recording
+		if config.RecordPath == "" && config.ReplayPath == "" {
+			msgraph = rest.NewBatchClient(msgraph, rest.NewRetryPolicy(config.MaxRetries, config.MaxBackoff))
+		}
 
-		if config.JWT != "" {
-			if aud, err := rest.ParseAud(config.JWT); err != nil {
+		var rmJWT string
+		for _, jwt := range config.JWT {
+			if aud, err := rest.ParseAud(jwt); err != nil {
 				return nil, err
-			} else if aud == config.GraphUrl() {
-				return initClientViaGraph(msgraph, resourceManager)
-			} else if aud == config.ResourceManagerUrl() {
-				if body, err := rest.ParseBody(config.JWT); err != nil {
-					return nil, err
-				} else {
-					return initClientViaRM(msgraph, resourceManager, body["tid"])
-				}
-			} else {
+			} else if rest.SameAudience(aud, config.ResourceManagerUrl()) {
+				rmJWT = jwt
+			} else if !rest.SameAudience(aud, config.GraphUrl()) {
 				return nil, fmt.Errorf("error: invalid token audience")
 			}
+		}
+
+		if graphJWT, _ := rest.SelectJWT(config.GraphUrl(), config.JWT); graphJWT == "" && rmJWT != "" && config.RefreshToken == "" {
+			// without a Graph token or a refresh token to mint one, the tenant has to be looked up via ARM
+			if body, err := rest.ParseBody(rmJWT); err != nil {
+				return nil, err
+			} else {
+				return initClientViaRM(msgraph, resourceManager, body["tid"])
+			}
 		} else {
 			return initClientViaGraph(msgraph, resourceManager)
 		}
@@ -98,9 +107,21 @@ func (s azureClient) TenantInfo() azure.Tenant {
 	return s.tenant
 }
 
+func (s azureClient) GraphToken(ctx context.Context) (rest.Token, error) {
+	return s.msgraph.Token(ctx)
+}
+
+func (s azureClient) ResourceManagerToken(ctx context.Context) (rest.Token, error) {
+	return s.resourceManager.Token(ctx)
+}
+
 type AzureClient interface {
 	GetAzureADApp(ctx context.Context, objectId string, selectCols []string) (*azure.Application, error)
 	GetAzureADApps(ctx context.Context, filter, search, orderBy, expand string, selectCols []string, top int32, count bool) (azure.ApplicationList, error)
+	GetAzureADDeletedApps(ctx context.Context, filter, search, orderBy string, selectCols []string, top int32, count bool) (azure.ApplicationList, error)
+	GetAzureADDeletedGroups(ctx context.Context, filter, search, orderBy string, selectCols []string, top int32, count bool) (azure.GroupList, error)
+	GetAzureADDeletedServicePrincipals(ctx context.Context, filter, search, orderBy string, selectCols []string, top int32, count bool) (azure.ServicePrincipalList, error)
+	GetAzureADDeletedUsers(ctx context.Context, filter, search, orderBy string, selectCols []string, top int32, count bool) (azure.UserList, error)
 	GetAzureADDirectoryObject(ctx context.Context, objectId string) (json.RawMessage, error)
 	GetAzureADGroup(ctx context.Context, objectId string, selectCols []string) (*azure.Group, error)
 	GetAzureADGroupOwners(ctx context.Context, objectId string, filter string, search string, orderBy string, selectCols []string, top int32, count bool) (azure.DirectoryObjectList, error)
@@ -124,6 +145,7 @@ type AzureClient interface {
 	GetAzureManagementGroups(ctx context.Context) (azure.ManagementGroupList, error)
 	GetAzureResourceGroup(ctx context.Context, subscriptionId, groupName string) (*azure.ResourceGroup, error)
 	GetAzureResourceGroups(ctx context.Context, subscriptionId string, filter string, top int32) (azure.ResourceGroupList, error)
+	GetAzureResources(ctx context.Context, subscriptionId string, filter string, expand string, top int32) (azure.ResourceList, error)
 	GetAzureSubscription(ctx context.Context, objectId string) (*azure.Subscription, error)
 	GetAzureSubscriptions(ctx context.Context) (azure.SubscriptionList, error)
 	GetAzureVirtualMachine(ctx context.Context, subscriptionId, groupName, vmName, expand string) (*azure.VirtualMachine, error)
@@ -131,25 +153,35 @@ type AzureClient interface {
 	GetAzureStorageAccount(ctx context.Context, subscriptionId, groupName, saName, expand string) (*azure.StorageAccount, error)
 	GetAzureStorageAccounts(ctx context.Context, subscriptionId string) (azure.StorageAccountList, error)
 	GetResourceRoleAssignments(ctx context.Context, subscriptionId string, filter string, expand string) (azure.RoleAssignmentList, error)
+	GetResourceGraphQuery(ctx context.Context, request azure.ResourceGraphQueryRequest) (azure.ResourceGraphQueryResponse, error)
 	GetRoleAssignmentsForResource(ctx context.Context, resourceId string, filter string) (azure.RoleAssignmentList, error)
 	ListAzureADAppMemberObjects(ctx context.Context, objectId string, securityEnabledOnly bool) <-chan azure.MemberObjectResult
 	ListAzureADAppOwners(ctx context.Context, objectId string, filter, search, orderBy string, selectCols []string) <-chan azure.AppOwnerResult
 	ListAzureADApps(ctx context.Context, filter, search, orderBy, expand string, selectCols []string) <-chan azure.ApplicationResult
+	ListAzureADAppsDelta(ctx context.Context, deltaLink string, selectCols []string) <-chan azure.ApplicationResult
+	ListAzureADDeletedApps(ctx context.Context, filter, search, orderBy string, selectCols []string) <-chan azure.ApplicationResult
+	ListAzureADDeletedGroups(ctx context.Context, filter, search, orderBy string, selectCols []string) <-chan azure.GroupResult
+	ListAzureADDeletedServicePrincipals(ctx context.Context, filter, search, orderBy string, selectCols []string) <-chan azure.ServicePrincipalResult
+	ListAzureADDeletedUsers(ctx context.Context, filter, search, orderBy string, selectCols []string) <-chan azure.UserResult
 	ListAzureADGroupMembers(ctx context.Context, objectId string, filter, search, orderBy string, selectCols []string) <-chan azure.MemberObjectResult
 	ListAzureADGroupOwners(ctx context.Context, objectId string, filter, search, orderBy string, selectCols []string) <-chan azure.GroupOwnerResult
 	ListAzureADGroups(ctx context.Context, filter, search, orderBy, expand string, selectCols []string) <-chan azure.GroupResult
+	ListAzureADGroupsDelta(ctx context.Context, deltaLink string, selectCols []string) <-chan azure.GroupResult
 	ListAzureADRoleAssignments(ctx context.Context, filter, search, orderBy, expand string, selectCols []string) <-chan azure.UnifiedRoleAssignmentResult
 	ListAzureADRoles(ctx context.Context, filter, expand string) <-chan azure.RoleResult
 	ListAzureADServicePrincipalOwners(ctx context.Context, objectId string, filter, search, orderBy string, selectCols []string) <-chan azure.ServicePrincipalOwnerResult
 	ListAzureADServicePrincipals(ctx context.Context, filter, search, orderBy, expand string, selectCols []string) <-chan azure.ServicePrincipalResult
+	ListAzureADServicePrincipalsDelta(ctx context.Context, deltaLink string, selectCols []string) <-chan azure.ServicePrincipalResult
 	ListAzureADTenants(ctx context.Context, includeAllTenantCategories bool) <-chan azure.TenantResult
 	ListAzureADUsers(ctx context.Context, filter string, search string, orderBy string, selectCols []string) <-chan azure.UserResult
+	ListAzureADUsersDelta(ctx context.Context, deltaLink string, selectCols []string) <-chan azure.UserResult
 	ListAzureDeviceRegisteredOwners(ctx context.Context, objectId string, securityEnabledOnly bool) <-chan azure.DeviceRegisteredOwnerResult
 	ListAzureDevices(ctx context.Context, filter, search, orderBy, expand string, selectCols []string) <-chan azure.DeviceResult
 	ListAzureKeyVaults(ctx context.Context, subscriptionId string, top int32) <-chan azure.KeyVaultResult
 	ListAzureManagementGroupDescendants(ctx context.Context, groupId string) <-chan azure.DescendantInfoResult
 	ListAzureManagementGroups(ctx context.Context) <-chan azure.ManagementGroupResult
 	ListAzureResourceGroups(ctx context.Context, subscriptionId, filter string) <-chan azure.ResourceGroupResult
+	ListAzureResources(ctx context.Context, subscriptionId string, filter string, expand string) <-chan azure.ResourceResult
 	ListAzureSubscriptions(ctx context.Context) <-chan azure.SubscriptionResult
 	ListAzureVirtualMachines(ctx context.Context, subscriptionId string, statusOnly bool) <-chan azure.VirtualMachineResult
 	ListAzureStorageAccounts(ctx context.Context, subscriptionId string) <-chan azure.StorageAccountResult
@@ -158,7 +190,10 @@ type AzureClient interface {
 	ListAzureWorkflows(ctx context.Context, subscriptionId string, filter string, top int32) <-chan azure.WorkflowResult
 	ListAzureFunctionApps(ctx context.Context, subscriptionId string) <-chan azure.FunctionAppResult
 	ListResourceRoleAssignments(ctx context.Context, subscriptionId string, filter string, expand string) <-chan azure.RoleAssignmentResult
+	ListResourceGraphQuery(ctx context.Context, subscriptionIds []string, managementGroupIds []string, kql string) <-chan azure.ResourceGraphResult
 	ListRoleAssignmentsForResource(ctx context.Context, resourceId string, filter string) <-chan azure.RoleAssignmentResult
 	ListAzureADAppRoleAssignments(ctx context.Context, servicePrincipal, filter, search, orderBy, expand string, selectCols []string) <-chan azure.AppRoleAssignmentResult
 	TenantInfo() azure.Tenant
+	GraphToken(ctx context.Context) (rest.Token, error)
+	ResourceManagerToken(ctx context.Context) (rest.Token, error)
 }
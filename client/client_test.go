@@ -0,0 +1,94 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package client
+
+import (
+	"context"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/bloodhoundad/azurehound/client/config"
+	"github.com/bloodhoundad/azurehound/internal/fakeazure"
+)
+
+// listGroupMembers lists the members of every group, either concurrently the way group members are collected or one
+// group at a time
+func listGroupMembers(t *testing.T, client AzureClient, tenant *fakeazure.Tenant, concurrent bool) int {
+	var (
+		wg      sync.WaitGroup
+		mutex   sync.Mutex
+		members = 0
+	)
+	for _, group := range tenant.Groups {
+		wg.Add(1)
+		go func(groupId string) {
+			defer wg.Done()
+			for item := range client.ListAzureADGroupMembers(context.Background(), groupId, "", "", "", nil) {
+				mutex.Lock()
+				if item.Error != nil {
+					t.Error(item.Error)
+				} else {
+					members++
+				}
+				mutex.Unlock()
+			}
+		}(group.Id)
+		if !concurrent {
+			wg.Wait()
+		}
+	}
+	wg.Wait()
+	return members
+}
+
+func TestReplayRelationships(t *testing.T) {
+	var (
+		tenant   = fakeazure.NewTenant(fakeazure.TenantOptions{Seed: 1, Users: 20, Groups: 30})
+		server   = fakeazure.NewServer(tenant, fakeazure.Config{})
+		cassette = filepath.Join(t.TempDir(), "cassette.jsonl")
+		want     = 0
+	)
+	for _, group := range tenant.Groups {
+		want += len(tenant.Members[group.Id])
+	}
+
+	clientConfig := config.Config{
+		ApplicationId: "app",
+		ClientSecret:  "secret",
+		Tenant:        tenant.Id,
+		Authority:     server.URL,
+		Graph:         server.URL,
+		Management:    server.URL,
+		RecordPath:    cassette,
+	}
+	if client, err := NewClient(clientConfig); err != nil {
+		t.Fatal(err)
+	} else if got := listGroupMembers(t, client, tenant, true); got != want {
+		t.Fatalf("got %d members recorded; want %d", got, want)
+	}
+	server.Close()
+
+	// relationship requests batched differently than when they were recorded would find no recorded response
+	clientConfig.RecordPath, clientConfig.ReplayPath = "", cassette
+	if client, err := NewClient(clientConfig); err != nil {
+		t.Fatal(err)
+	} else if got := listGroupMembers(t, client, tenant, false); got != want {
+		t.Errorf("got %d members replayed; want %d", got, want)
+	}
+}
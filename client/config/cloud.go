@@ -0,0 +1,113 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/bloodhoundad/azurehound/constants"
+)
+
+const CloudMetadataApiVersion = "2022-09-01"
+
+// cloudMetadata is a cloud as described by the metadata endpoint of Azure Resource Manager
+type cloudMetadata struct {
+	Name           string `json:"name"`
+	Authentication struct {
+		LoginEndpoint string `json:"loginEndpoint"`
+	} `json:"authentication"`
+	MicrosoftGraphResourceId string `json:"microsoftGraphResourceId"`
+	ResourceManager          string `json:"resourceManager"`
+	Suffixes                 struct {
+		KeyVaultDns string `json:"keyVaultDns"`
+		Storage     string `json:"storage"`
+	} `json:"suffixes"`
+}
+
+// CloudMetadataUrl returns the metadata endpoint of the Azure Resource Manager at resourceManagerUrl, unless it
+// already points to one
+func CloudMetadataUrl(resourceManagerUrl string) (*url.URL, error) {
+	if metadataUrl, err := url.Parse(resourceManagerUrl); err != nil {
+		return nil, err
+	} else {
+		if !strings.HasSuffix(metadataUrl.Path, "/metadata/endpoints") {
+			metadataUrl.Path = strings.TrimSuffix(metadataUrl.Path, "/") + "/metadata/endpoints"
+		}
+		if metadataUrl.Query().Get("api-version") == "" {
+			metadataUrl.RawQuery = url.Values{"api-version": {CloudMetadataApiVersion}}.Encode()
+		}
+		return metadataUrl, nil
+	}
+}
+
+// ParseCloudMetadata reads the endpoints of a cloud from the output of the Azure Resource Manager metadata endpoint.
+// Newer api versions list every cloud the endpoint knows of, in which case the one served by resourceManagerUrl is
+// used.
+func ParseCloudMetadata(data []byte, resourceManagerUrl string) (constants.Environment, error) {
+	var clouds []cloudMetadata
+	if data = bytes.TrimSpace(data); bytes.HasPrefix(data, []byte("[")) {
+		if err := json.Unmarshal(data, &clouds); err != nil {
+			return constants.Environment{}, fmt.Errorf("unable to parse cloud metadata: %w", err)
+		}
+	} else {
+		var cloud cloudMetadata
+		if err := json.Unmarshal(data, &cloud); err != nil {
+			return constants.Environment{}, fmt.Errorf("unable to parse cloud metadata: %w", err)
+		} else {
+			clouds = append(clouds, cloud)
+		}
+	}
+
+	if cloud, err := selectCloud(clouds, resourceManagerUrl); err != nil {
+		return constants.Environment{}, err
+	} else if cloud.Authentication.LoginEndpoint == "" {
+		return constants.Environment{}, fmt.Errorf("cloud metadata for %s has no login endpoint", cloud.Name)
+	} else {
+		if cloud.ResourceManager == "" {
+			cloud.ResourceManager = resourceManagerUrl
+		}
+		return constants.Environment{
+			ActiveDirectoryAuthority: strings.TrimSuffix(cloud.Authentication.LoginEndpoint, "/"),
+			MicrosoftGraphUrl:        strings.TrimSuffix(cloud.MicrosoftGraphResourceId, "/"),
+			ResourceManagerUrl:       strings.TrimSuffix(cloud.ResourceManager, "/"),
+			KeyVaultDNSSuffix:        strings.TrimPrefix(cloud.Suffixes.KeyVaultDns, "."),
+			StorageEndpointSuffix:    strings.TrimPrefix(cloud.Suffixes.Storage, "."),
+		}, nil
+	}
+}
+
+func selectCloud(clouds []cloudMetadata, resourceManagerUrl string) (cloudMetadata, error) {
+	if len(clouds) == 1 {
+		return clouds[0], nil
+	} else if resourceManagerUrl == "" {
+		return cloudMetadata{}, fmt.Errorf("cloud metadata lists %d clouds and no resource manager url to choose one by", len(clouds))
+	} else if arm, err := url.Parse(resourceManagerUrl); err != nil {
+		return cloudMetadata{}, err
+	} else {
+		for _, cloud := range clouds {
+			if cloudArm, err := url.Parse(cloud.ResourceManager); err == nil && strings.EqualFold(cloudArm.Host, arm.Host) {
+				return cloud, nil
+			}
+		}
+		return cloudMetadata{}, fmt.Errorf("cloud metadata lists %d clouds and none is served by %s", len(clouds), resourceManagerUrl)
+	}
+}
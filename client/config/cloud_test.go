@@ -0,0 +1,95 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package config
+
+import (
+	"testing"
+
+	"github.com/bloodhoundad/azurehound/constants"
+)
+
+const testCloudMetadata = `[
+	{
+		"name": "AzureCloud",
+		"authentication": {"loginEndpoint": "https://login.microsoftonline.com/"},
+		"microsoftGraphResourceId": "https://graph.microsoft.com/",
+		"resourceManager": "https://management.azure.com/",
+		"suffixes": {"keyVaultDns": "vault.azure.net", "storage": "core.windows.net"}
+	},
+	{
+		"name": "AzureStack",
+		"authentication": {"loginEndpoint": "https://login.stack.contoso.com/"},
+		"microsoftGraphResourceId": "https://graph.stack.contoso.com/",
+		"resourceManager": "https://management.stack.contoso.com/",
+		"suffixes": {"keyVaultDns": ".vault.stack.contoso.com", "storage": "stack.contoso.com"}
+	}
+]`
+
+func TestParseCloudMetadata(t *testing.T) {
+	want := constants.Environment{
+		ActiveDirectoryAuthority: "https://login.stack.contoso.com",
+		MicrosoftGraphUrl:        "https://graph.stack.contoso.com",
+		ResourceManagerUrl:       "https://management.stack.contoso.com",
+		KeyVaultDNSSuffix:        "vault.stack.contoso.com",
+		StorageEndpointSuffix:    "stack.contoso.com",
+	}
+
+	if got, err := ParseCloudMetadata([]byte(testCloudMetadata), "https://Management.stack.contoso.com"); err != nil {
+		t.Fatal(err)
+	} else if got != want {
+		t.Errorf("got %+v; want %+v", got, want)
+	}
+
+	if _, err := ParseCloudMetadata([]byte(testCloudMetadata), "https://management.fabrikam.com"); err == nil {
+		t.Error("expected an error when no cloud is served by the resource manager")
+	} else if _, err := ParseCloudMetadata([]byte(testCloudMetadata), ""); err == nil {
+		t.Error("expected an error when there is no resource manager to choose a cloud by")
+	}
+
+	// older api versions and Azure Stack Hub describe a single cloud
+	single := `{"authentication": {"loginEndpoint": "https://login.stack.contoso.com/"}}`
+	if got, err := ParseCloudMetadata([]byte(single), "https://management.stack.contoso.com"); err != nil {
+		t.Fatal(err)
+	} else if got.ResourceManagerUrl != "https://management.stack.contoso.com" {
+		t.Errorf("got %s; want the resource manager the metadata was read from", got.ResourceManagerUrl)
+	}
+}
+
+func TestCloudMetadataUrl(t *testing.T) {
+	for input, want := range map[string]string{
+		"https://management.stack.contoso.com":                                           "https://management.stack.contoso.com/metadata/endpoints?api-version=2022-09-01",
+		"https://management.stack.contoso.com/":                                          "https://management.stack.contoso.com/metadata/endpoints?api-version=2022-09-01",
+		"https://management.stack.contoso.com/metadata/endpoints?api-version=2015-01-01": "https://management.stack.contoso.com/metadata/endpoints?api-version=2015-01-01",
+	} {
+		if got, err := CloudMetadataUrl(input); err != nil {
+			t.Error(err)
+		} else if got.String() != want {
+			t.Errorf("got %s; want %s", got, want)
+		}
+	}
+}
+
+func TestConfigUrls(t *testing.T) {
+	config := Config{Region: constants.USGovL4, Management: "https://management.stack.contoso.com"}
+
+	if got := config.AuthorityUrl(); got != constants.AzureUSGovernment().ActiveDirectoryAuthority {
+		t.Errorf("got %s; want the authority of the region", got)
+	} else if got := config.ResourceManagerUrl(); got != config.Management {
+		t.Errorf("got %s; want the configured resource manager", got)
+	}
+}
@@ -18,88 +18,110 @@
 package config
 
 import (
+	"time"
+
 	"github.com/bloodhoundad/azurehound/constants"
 )
 
 type Config struct {
-	ApplicationId  string   // The Application Id that the  Azure app registration portal assigned when the app was registered.
-	Authority      string   // The Azure ActiveDirectory Authority URL
-	ClientSecret   string   // The Application Secret that was generated for the app in the app registration portal.
-	ClientCert     string   // The certificate uploaded to the app registration portal."
-	ClientKey      string   // The key for a certificate uploaded to the app registration portal."
-	ClientKeyPass  string   // The passphrase to use in conjuction with the associated key of a certificate uploaded to the app registration portal."
-	Graph          string   // The Microsoft Graph URL
-	JWT            string   // The JSON web token that will be used to authenticate requests sent to Azure APIs
-	Management     string   // The Azure ResourceManager URL
-	MgmtGroupId    []string // The Management Group Id to use as a filter
-	Password       string   // The password associated with the user principal name associated with the Azure portal.
-	ProxyUrl       string   // The forward proxy url
-	RefreshToken   string   // The refresh token that will be used to authenticate requests sent to Azure APIs
-	Region         string   // The region of the Azure Cloud deployment.
-	SubscriptionId []string // The Subscription Id(s) to use as a filter
-	Tenant         string   // The directory tenant that you want to request permission from. This can be in GUID or friendly name format
-	Username       string   // The user principal name associated with the Azure portal.
+	ApplicationId         string        // The Application Id that the  Azure app registration portal assigned when the app was registered.
+	AuthMode              string        // The flow used to acquire tokens instead of the provided credentials, e.g. managed-identity
+	Authority             string        // The Azure ActiveDirectory Authority URL
+	ClientSecret          string        // The Application Secret that was generated for the app in the app registration portal.
+	ClientCert            string        // The certificate uploaded to the app registration portal."
+	ClientKey             string        // The key for a certificate uploaded to the app registration portal."
+	ClientKeyPass         string        // The passphrase to use in conjuction with the associated key of a certificate uploaded to the app registration portal."
+	FederatedTokenCommand string        // A command printing the OIDC token used for workload identity federation
+	FederatedTokenFile    string        // The file holding the OIDC token used for workload identity federation
+	Graph                 string        // The Microsoft Graph URL
+	IdentityEndpoint      string        // The managed identity token endpoint, IMDS if empty
+	IdentityHeader        string        // The secret header value expected by an App Service or Functions identity endpoint
+	JWT                   []string      // The JSON web tokens, one per API audience, that will be used to authenticate requests sent to Azure APIs
+	Management            string        // The Azure ResourceManager URL
+	MaxBackoff            time.Duration // The longest time to wait before retrying a failed request
+	MaxRetries            int           // The number of times a failed request is retried
+	MSALCache             string        // The path of the Azure CLI/MSAL token cache to take refresh tokens from
+	MgmtGroupId           []string      // The Management Group Id to use as a filter
+	Password              string        // The password associated with the user principal name associated with the Azure portal.
+	ProxyUrl              string        // The forward proxy url
+	RateLimit             int           // The number of requests per second sent to each host or ARM resource provider, or 0 to adapt to throttling only
+	RateLimitBurst        int           // The number of requests that may be sent at once before RateLimit applies
+	RecordPath            string        // The cassette file every request and response is recorded to
+	RefreshToken          string        // The refresh token that will be used to authenticate requests sent to Azure APIs
+	Region                string        // The region of the Azure Cloud deployment.
+	ReplayPath            string        // The cassette file responses are replayed from instead of sending requests
+	SubscriptionId        []string      // The Subscription Id(s) to use as a filter
+	Tenant                string        // The directory tenant that you want to request permission from. This can be in GUID or friendly name format
+	TokenCache            string        // The path of an encrypted file that keeps the device code refresh token between runs
+	TokenCacheKey         string        // The passphrase the token cache is encrypted with
+	Username              string        // The user principal name associated with the Azure portal.
 }
 
-func AuthorityUrl(region string, defaultUrl string) string {
+// Environment returns the endpoints of the Azure Cloud deployment in region, if it is a known one
+func Environment(region string) (constants.Environment, bool) {
 	switch region {
 	case constants.China:
-		return constants.AzureChina().ActiveDirectoryAuthority
+		return constants.AzureChina(), true
 	case constants.Cloud:
-		return constants.AzureCloud().ActiveDirectoryAuthority
+		return constants.AzureCloud(), true
 	case constants.Germany:
-		return constants.AzureGermany().ActiveDirectoryAuthority
+		return constants.AzureGermany(), true
 	case constants.USGovL4:
-		return constants.AzureUSGovernment().ActiveDirectoryAuthority
+		return constants.AzureUSGovernment(), true
 	case constants.USGovL5:
-		return constants.AzureUSGovernmentL5().ActiveDirectoryAuthority
+		return constants.AzureUSGovernmentL5(), true
 	default:
+		return constants.Environment{}, false
+	}
+}
+
+func AuthorityUrl(region string, defaultUrl string) string {
+	if env, ok := Environment(region); ok {
+		return env.ActiveDirectoryAuthority
+	} else {
 		return defaultUrl
 	}
 }
 
+// AuthorityUrl returns the configured authority, falling back to the one of the region
 func (s Config) AuthorityUrl() string {
-	return AuthorityUrl(s.Region, s.Authority)
+	if s.Authority != "" {
+		return s.Authority
+	} else {
+		return AuthorityUrl(s.Region, constants.AzureCloud().ActiveDirectoryAuthority)
+	}
 }
 
 func GraphUrl(region string, defaultUrl string) string {
-	switch region {
-	case constants.China:
-		return constants.AzureChina().MicrosoftGraphUrl
-	case constants.Cloud:
-		return constants.AzureCloud().MicrosoftGraphUrl
-	case constants.Germany:
-		return constants.AzureGermany().MicrosoftGraphUrl
-	case constants.USGovL4:
-		return constants.AzureUSGovernment().MicrosoftGraphUrl
-	case constants.USGovL5:
-		return constants.AzureUSGovernmentL5().MicrosoftGraphUrl
-	default:
+	if env, ok := Environment(region); ok {
+		return env.MicrosoftGraphUrl
+	} else {
 		return defaultUrl
 	}
 }
 
+// GraphUrl returns the configured Microsoft Graph URL, falling back to the one of the region
 func (s Config) GraphUrl() string {
-	return GraphUrl(s.Region, s.Graph)
+	if s.Graph != "" {
+		return s.Graph
+	} else {
+		return GraphUrl(s.Region, constants.AzureCloud().MicrosoftGraphUrl)
+	}
 }
 
 func ResourceManagerUrl(region string, defaultUrl string) string {
-	switch region {
-	case constants.China:
-		return constants.AzureChina().ResourceManagerUrl
-	case constants.Cloud:
-		return constants.AzureCloud().ResourceManagerUrl
-	case constants.Germany:
-		return constants.AzureGermany().ResourceManagerUrl
-	case constants.USGovL4:
-		return constants.AzureUSGovernment().ResourceManagerUrl
-	case constants.USGovL5:
-		return constants.AzureUSGovernmentL5().ResourceManagerUrl
-	default:
+	if env, ok := Environment(region); ok {
+		return env.ResourceManagerUrl
+	} else {
 		return defaultUrl
 	}
 }
 
+// ResourceManagerUrl returns the configured Azure Resource Manager URL, falling back to the one of the region
 func (s Config) ResourceManagerUrl() string {
-	return ResourceManagerUrl(s.Region, s.Graph)
+	if s.Management != "" {
+		return s.Management
+	} else {
+		return ResourceManagerUrl(s.Region, constants.AzureCloud().ResourceManagerUrl)
+	}
 }
@@ -0,0 +1,153 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/bloodhoundad/azurehound/client/query"
+	"github.com/bloodhoundad/azurehound/client/rest"
+	"github.com/bloodhoundad/azurehound/constants"
+	"github.com/bloodhoundad/azurehound/models/azure"
+)
+
+func (s *azureClient) GetAzureADDeletedUsers(ctx context.Context, filter, search, orderBy string, selectCols []string, top int32, count bool) (azure.UserList, error) {
+	var (
+		path     = fmt.Sprintf("/%s/directory/deletedItems/microsoft.graph.user", constants.GraphApiVersion)
+		params   = query.Params{Filter: filter, Search: search, OrderBy: orderBy, Select: selectCols, Top: top, Count: count}
+		headers  map[string]string
+		response azure.UserList
+	)
+
+	count = count || search != "" || (filter != "" && orderBy != "") || strings.Contains(filter, "endsWith")
+	if count {
+		headers = make(map[string]string)
+		headers["ConsistencyLevel"] = "eventual"
+	}
+	if res, err := s.msgraph.Get(ctx, path, params.AsMap(), headers); err != nil {
+		return response, err
+	} else if err := rest.Decode(res.Body, &response); err != nil {
+		return response, err
+	} else {
+		return response, nil
+	}
+}
+
+func (s *azureClient) ListAzureADDeletedUsers(ctx context.Context, filter, search, orderBy string, selectCols []string) <-chan azure.UserResult {
+	return paginate(ctx, nextLinks(s.msgraph, func() (page[azure.User], error) {
+		list, err := s.GetAzureADDeletedUsers(ctx, filter, search, orderBy, selectCols, graphPageSize, false)
+		return page[azure.User]{Value: list.Value, NextLink: list.NextLink}, err
+	}), func(u azure.User, err error) azure.UserResult {
+		return azure.UserResult{Error: err, Ok: u}
+	})
+}
+
+func (s *azureClient) GetAzureADDeletedGroups(ctx context.Context, filter, search, orderBy string, selectCols []string, top int32, count bool) (azure.GroupList, error) {
+	var (
+		path     = fmt.Sprintf("/%s/directory/deletedItems/microsoft.graph.group", constants.GraphApiVersion)
+		params   = query.Params{Filter: filter, Search: search, OrderBy: orderBy, Select: selectCols, Top: top, Count: count}
+		headers  map[string]string
+		response azure.GroupList
+	)
+
+	count = count || search != "" || (filter != "" && orderBy != "") || strings.Contains(filter, "endsWith")
+	if count {
+		headers = make(map[string]string)
+		headers["ConsistencyLevel"] = "eventual"
+	}
+	if res, err := s.msgraph.Get(ctx, path, params.AsMap(), headers); err != nil {
+		return response, err
+	} else if err := rest.Decode(res.Body, &response); err != nil {
+		return response, err
+	} else {
+		return response, nil
+	}
+}
+
+func (s *azureClient) ListAzureADDeletedGroups(ctx context.Context, filter, search, orderBy string, selectCols []string) <-chan azure.GroupResult {
+	return paginate(ctx, nextLinks(s.msgraph, func() (page[azure.Group], error) {
+		list, err := s.GetAzureADDeletedGroups(ctx, filter, search, orderBy, selectCols, graphPageSize, false)
+		return page[azure.Group]{Value: list.Value, NextLink: list.NextLink}, err
+	}), func(u azure.Group, err error) azure.GroupResult {
+		return azure.GroupResult{Error: err, Ok: u}
+	})
+}
+
+func (s *azureClient) GetAzureADDeletedApps(ctx context.Context, filter, search, orderBy string, selectCols []string, top int32, count bool) (azure.ApplicationList, error) {
+	var (
+		path     = fmt.Sprintf("/%s/directory/deletedItems/microsoft.graph.application", constants.GraphApiVersion)
+		params   = query.Params{Filter: filter, Search: search, OrderBy: orderBy, Select: selectCols, Top: top, Count: count}
+		headers  map[string]string
+		response azure.ApplicationList
+	)
+
+	count = count || search != "" || (filter != "" && orderBy != "") || strings.Contains(filter, "endsWith")
+	if count {
+		headers = make(map[string]string)
+		headers["ConsistencyLevel"] = "eventual"
+	}
+	if res, err := s.msgraph.Get(ctx, path, params.AsMap(), headers); err != nil {
+		return response, err
+	} else if err := rest.Decode(res.Body, &response); err != nil {
+		return response, err
+	} else {
+		return response, nil
+	}
+}
+
+func (s *azureClient) ListAzureADDeletedApps(ctx context.Context, filter, search, orderBy string, selectCols []string) <-chan azure.ApplicationResult {
+	return paginate(ctx, nextLinks(s.msgraph, func() (page[azure.Application], error) {
+		list, err := s.GetAzureADDeletedApps(ctx, filter, search, orderBy, selectCols, graphPageSize, false)
+		return page[azure.Application]{Value: list.Value, NextLink: list.NextLink}, err
+	}), func(u azure.Application, err error) azure.ApplicationResult {
+		return azure.ApplicationResult{Error: err, Ok: u}
+	})
+}
+
+func (s *azureClient) GetAzureADDeletedServicePrincipals(ctx context.Context, filter, search, orderBy string, selectCols []string, top int32, count bool) (azure.ServicePrincipalList, error) {
+	var (
+		path     = fmt.Sprintf("/%s/directory/deletedItems/microsoft.graph.servicePrincipal", constants.GraphApiVersion)
+		params   = query.Params{Filter: filter, Search: search, OrderBy: orderBy, Select: selectCols, Top: top, Count: count}
+		headers  map[string]string
+		response azure.ServicePrincipalList
+	)
+
+	count = count || search != "" || (filter != "" && orderBy != "") || strings.Contains(filter, "endsWith")
+	if count {
+		headers = make(map[string]string)
+		headers["ConsistencyLevel"] = "eventual"
+	}
+	if res, err := s.msgraph.Get(ctx, path, params.AsMap(), headers); err != nil {
+		return response, err
+	} else if err := rest.Decode(res.Body, &response); err != nil {
+		return response, err
+	} else {
+		return response, nil
+	}
+}
+
+func (s *azureClient) ListAzureADDeletedServicePrincipals(ctx context.Context, filter, search, orderBy string, selectCols []string) <-chan azure.ServicePrincipalResult {
+	return paginate(ctx, nextLinks(s.msgraph, func() (page[azure.ServicePrincipal], error) {
+		list, err := s.GetAzureADDeletedServicePrincipals(ctx, filter, search, orderBy, selectCols, graphPageSize, false)
+		return page[azure.ServicePrincipal]{Value: list.Value, NextLink: list.NextLink}, err
+	}), func(u azure.ServicePrincipal, err error) azure.ServicePrincipalResult {
+		return azure.ServicePrincipalResult{Error: err, Ok: u}
+	})
+}
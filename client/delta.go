@@ -0,0 +1,129 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+
+	"github.com/bloodhoundad/azurehound/client/query"
+	"github.com/bloodhoundad/azurehound/client/rest"
+	"github.com/bloodhoundad/azurehound/constants"
+	"github.com/bloodhoundad/azurehound/models/azure"
+)
+
+type deltaPage[T any] struct {
+	Value     []T    `json:"value"`
+	NextLink  string `json:"@odata.nextLink,omitempty"`
+	DeltaLink string `json:"@odata.deltaLink,omitempty"`
+}
+
+// listAzureADDelta paginates a round of a delta query, continuing from deltaLink or starting a new round at path when
+// there is none. Once every page has been received a final result carrying the delta link of the next round is
+// emitted.
+func listAzureADDelta[T, R any](ctx context.Context, client rest.RestClient, path, deltaLink string, selectCols []string, result func(T, error) R, final func(deltaLink string) R) <-chan R {
+	var (
+		out    = make(chan R)
+		next   string
+		failed bool
+	)
+
+	fetch := func(ctx context.Context, link string) (page[T], error) {
+		var response deltaPage[T]
+		if link == "" {
+			link = deltaLink
+		}
+
+		if link == "" {
+			params := query.Params{Select: selectCols}.AsMap()
+			if res, err := client.Get(ctx, path, params, nil); err != nil {
+				return page[T]{}, err
+			} else if err := rest.Decode(res.Body, &response); err != nil {
+				return page[T]{}, err
+			}
+		} else if url, err := url.Parse(link); err != nil {
+			return page[T]{}, err
+		} else if req, err := rest.NewRequest(ctx, "GET", url, nil, nil, nil); err != nil {
+			return page[T]{}, err
+		} else if res, err := client.Send(req); err != nil {
+			return page[T]{}, err
+		} else if err := rest.Decode(res.Body, &response); err != nil {
+			return page[T]{}, err
+		}
+
+		if response.DeltaLink != "" {
+			next = response.DeltaLink
+		}
+		return page[T]{Value: response.Value, NextLink: response.NextLink}, nil
+	}
+
+	go func() {
+		defer close(out)
+
+		for item := range paginate(ctx, fetch, func(u T, err error) R {
+			failed = failed || err != nil
+			return result(u, err)
+		}) {
+			if ok := send(ctx, out, item); !ok {
+				return
+			}
+		}
+
+		if !failed && next != "" {
+			send(ctx, out, final(next))
+		}
+	}()
+	return out
+}
+
+func (s *azureClient) ListAzureADUsersDelta(ctx context.Context, deltaLink string, selectCols []string) <-chan azure.UserResult {
+	path := fmt.Sprintf("/%s/users/delta", constants.GraphApiVersion)
+	return listAzureADDelta(ctx, s.msgraph, path, deltaLink, selectCols, func(u azure.User, err error) azure.UserResult {
+		return azure.UserResult{Error: err, Ok: u}
+	}, func(deltaLink string) azure.UserResult {
+		return azure.UserResult{DeltaLink: deltaLink}
+	})
+}
+
+func (s *azureClient) ListAzureADGroupsDelta(ctx context.Context, deltaLink string, selectCols []string) <-chan azure.GroupResult {
+	path := fmt.Sprintf("/%s/groups/delta", constants.GraphApiVersion)
+	return listAzureADDelta(ctx, s.msgraph, path, deltaLink, selectCols, func(u azure.Group, err error) azure.GroupResult {
+		return azure.GroupResult{Error: err, Ok: u}
+	}, func(deltaLink string) azure.GroupResult {
+		return azure.GroupResult{DeltaLink: deltaLink}
+	})
+}
+
+func (s *azureClient) ListAzureADAppsDelta(ctx context.Context, deltaLink string, selectCols []string) <-chan azure.ApplicationResult {
+	path := fmt.Sprintf("/%s/applications/delta", constants.GraphApiVersion)
+	return listAzureADDelta(ctx, s.msgraph, path, deltaLink, selectCols, func(u azure.Application, err error) azure.ApplicationResult {
+		return azure.ApplicationResult{Error: err, Ok: u}
+	}, func(deltaLink string) azure.ApplicationResult {
+		return azure.ApplicationResult{DeltaLink: deltaLink}
+	})
+}
+
+func (s *azureClient) ListAzureADServicePrincipalsDelta(ctx context.Context, deltaLink string, selectCols []string) <-chan azure.ServicePrincipalResult {
+	path := fmt.Sprintf("/%s/servicePrincipals/delta", constants.GraphApiVersion)
+	return listAzureADDelta(ctx, s.msgraph, path, deltaLink, selectCols, func(u azure.ServicePrincipal, err error) azure.ServicePrincipalResult {
+		return azure.ServicePrincipalResult{Error: err, Ok: u}
+	}, func(deltaLink string) azure.ServicePrincipalResult {
+		return azure.ServicePrincipalResult{DeltaLink: deltaLink}
+	})
+}
@@ -19,8 +19,8 @@ package client
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
-	"net/url"
 	"strings"
 
 	"github.com/bloodhoundad/azurehound/client/query"
@@ -82,109 +82,25 @@ func (s *azureClient) GetAzureDevices(ctx context.Context, filter, search, order
 }
 
 func (s *azureClient) ListAzureDevices(ctx context.Context, filter, search, orderBy, expand string, selectCols []string) <-chan azure.DeviceResult {
-	out := make(chan azure.DeviceResult)
-
-	go func() {
-		defer close(out)
-
-		var (
-			errResult = azure.DeviceResult{}
-			nextLink  string
-		)
-
-		if list, err := s.GetAzureDevices(ctx, filter, search, orderBy, expand, selectCols, 999, false); err != nil {
-			errResult.Error = err
-			out <- errResult
-		} else {
-			for _, u := range list.Value {
-				out <- azure.DeviceResult{Ok: u}
-			}
-
-			nextLink = list.NextLink
-			for nextLink != "" {
-				var list azure.DeviceList
-				if url, err := url.Parse(nextLink); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else if req, err := rest.NewRequest(ctx, "GET", url, nil, nil, nil); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else if res, err := s.msgraph.Send(req); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else if err := rest.Decode(res.Body, &list); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else {
-					for _, u := range list.Value {
-						out <- azure.DeviceResult{Ok: u}
-					}
-					nextLink = list.NextLink
-				}
-			}
-		}
-	}()
-	return out
+	return paginate(ctx, nextLinks(s.msgraph, func() (page[azure.Device], error) {
+		list, err := s.GetAzureDevices(ctx, filter, search, orderBy, expand, selectCols, graphPageSize, false)
+		return page[azure.Device]{Value: list.Value, NextLink: list.NextLink}, err
+	}), func(u azure.Device, err error) azure.DeviceResult {
+		return azure.DeviceResult{Error: err, Ok: u}
+	})
 }
 
 func (s *azureClient) ListAzureDeviceRegisteredOwners(ctx context.Context, objectId string, securityEnabledOnly bool) <-chan azure.DeviceRegisteredOwnerResult {
-	out := make(chan azure.DeviceRegisteredOwnerResult)
-
-	go func() {
-		defer close(out)
-
-		var (
-			errResult = azure.DeviceRegisteredOwnerResult{
-				DeviceId: objectId,
-			}
-			nextLink string
-		)
-
-		if list, err := s.GetAzureDeviceRegisteredOwners(ctx, objectId, "", "", false); err != nil {
-			errResult.Error = err
-			out <- errResult
-		} else {
-			for _, u := range list.Value {
-				out <- azure.DeviceRegisteredOwnerResult{
-					DeviceId: objectId,
-					Ok:       u,
-				}
-			}
-
-			nextLink = list.NextLink
-			for nextLink != "" {
-				var list azure.DirectoryObjectList
-				if url, err := url.Parse(nextLink); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else if req, err := rest.NewRequest(ctx, "GET", url, nil, nil, nil); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else if res, err := s.msgraph.Send(req); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else if err := rest.Decode(res.Body, &list); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else {
-					for _, u := range list.Value {
-						out <- azure.DeviceRegisteredOwnerResult{
-							DeviceId: objectId,
-							Ok:       u,
-						}
-					}
-					nextLink = list.NextLink
-				}
-			}
+	ctx = rest.WithBatching(ctx)
+
+	return paginate(ctx, nextLinks(s.msgraph, func() (page[json.RawMessage], error) {
+		list, err := s.GetAzureDeviceRegisteredOwners(ctx, objectId, "", "", false)
+		return page[json.RawMessage]{Value: list.Value, NextLink: list.NextLink}, err
+	}), func(u json.RawMessage, err error) azure.DeviceRegisteredOwnerResult {
+		return azure.DeviceRegisteredOwnerResult{
+			Error:    err,
+			DeviceId: objectId,
+			Ok:       u,
 		}
-	}()
-	return out
+	})
 }
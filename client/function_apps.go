@@ -20,7 +20,6 @@ package client
 import (
 	"context"
 	"fmt"
-	"net/url"
 
 	"github.com/bloodhoundad/azurehound/client/query"
 	"github.com/bloodhoundad/azurehound/client/rest"
@@ -61,56 +60,10 @@ func (s *azureClient) GetAzureFunctionApps(ctx context.Context, subscriptionId s
 }
 
 func (s *azureClient) ListAzureFunctionApps(ctx context.Context, subscriptionId string) <-chan azure.FunctionAppResult {
-	out := make(chan azure.FunctionAppResult)
-
-	go func() {
-		defer close(out)
-
-		var (
-			errResult = azure.FunctionAppResult{
-				SubscriptionId: subscriptionId,
-			}
-			nextLink string
-		)
-
-		if result, err := s.GetAzureFunctionApps(ctx, subscriptionId); err != nil {
-			errResult.Error = err
-			out <- errResult
-		} else {
-			for _, u := range result.Value {
-				out <- azure.FunctionAppResult{SubscriptionId: subscriptionId, Ok: u}
-			}
-
-			nextLink = result.NextLink
-			for nextLink != "" {
-				var list azure.FunctionAppList
-				if url, err := url.Parse(nextLink); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else if req, err := rest.NewRequest(ctx, "GET", url, nil, nil, nil); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else if res, err := s.resourceManager.Send(req); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else if err := rest.Decode(res.Body, &list); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else {
-					for _, u := range list.Value {
-						out <- azure.FunctionAppResult{
-							SubscriptionId: "/subscriptions/" + subscriptionId,
-							Ok:             u,
-						}
-					}
-					nextLink = list.NextLink
-				}
-			}
-		}
-	}()
-	return out
+	return paginate(ctx, nextLinks(s.resourceManager, func() (page[azure.FunctionApp], error) {
+		result, err := s.GetAzureFunctionApps(ctx, subscriptionId)
+		return page[azure.FunctionApp]{Value: result.Value, NextLink: result.NextLink}, err
+	}), func(u azure.FunctionApp, err error) azure.FunctionAppResult {
+		return azure.FunctionAppResult{Error: err, SubscriptionId: subscriptionId, Ok: u}
+	})
 }
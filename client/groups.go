@@ -19,8 +19,8 @@ package client
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
-	"net/url"
 	"strings"
 
 	"github.com/bloodhoundad/azurehound/client/query"
@@ -98,168 +98,41 @@ func (s *azureClient) GetAzureADGroups(ctx context.Context, filter, search, orde
 }
 
 func (s *azureClient) ListAzureADGroups(ctx context.Context, filter, search, orderBy, expand string, selectCols []string) <-chan azure.GroupResult {
-	out := make(chan azure.GroupResult)
-
-	go func() {
-		defer close(out)
-
-		var (
-			errResult = azure.GroupResult{}
-			nextLink  string
-		)
-
-		if list, err := s.GetAzureADGroups(ctx, filter, search, orderBy, expand, selectCols, 999, false); err != nil {
-			errResult.Error = err
-			out <- errResult
-		} else {
-			for _, u := range list.Value {
-				out <- azure.GroupResult{Ok: u}
-			}
-
-			nextLink = list.NextLink
-			for nextLink != "" {
-				var list azure.GroupList
-				if url, err := url.Parse(nextLink); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else if req, err := rest.NewRequest(ctx, "GET", url, nil, nil, nil); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else if res, err := s.msgraph.Send(req); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else if err := rest.Decode(res.Body, &list); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else {
-					for _, u := range list.Value {
-						out <- azure.GroupResult{Ok: u}
-					}
-					nextLink = list.NextLink
-				}
-			}
-		}
-	}()
-	return out
+	return paginate(ctx, nextLinks(s.msgraph, func() (page[azure.Group], error) {
+		list, err := s.GetAzureADGroups(ctx, filter, search, orderBy, expand, selectCols, graphPageSize, false)
+		return page[azure.Group]{Value: list.Value, NextLink: list.NextLink}, err
+	}), func(u azure.Group, err error) azure.GroupResult {
+		return azure.GroupResult{Error: err, Ok: u}
+	})
 }
 
 func (s *azureClient) ListAzureADGroupOwners(ctx context.Context, objectId string, filter, search, orderBy string, selectCols []string) <-chan azure.GroupOwnerResult {
-	out := make(chan azure.GroupOwnerResult)
-
-	go func() {
-		defer close(out)
-
-		var (
-			errResult = azure.GroupOwnerResult{}
-			nextLink  string
-		)
-
-		if list, err := s.GetAzureADGroupOwners(ctx, objectId, filter, search, orderBy, selectCols, 999, false); err != nil {
-			errResult.Error = err
-			out <- errResult
-		} else {
-			for _, u := range list.Value {
-				out <- azure.GroupOwnerResult{
-					GroupId: objectId,
-					Ok:      u,
-				}
-			}
-
-			nextLink = list.NextLink
-			for nextLink != "" {
-				var list azure.DirectoryObjectList
-				if url, err := url.Parse(nextLink); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else if req, err := rest.NewRequest(ctx, "GET", url, nil, nil, nil); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else if res, err := s.msgraph.Send(req); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else if err := rest.Decode(res.Body, &list); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else {
-					for _, u := range list.Value {
-						out <- azure.GroupOwnerResult{
-							GroupId: objectId,
-							Ok:      u,
-						}
-					}
-					nextLink = list.NextLink
-				}
-			}
+	ctx = rest.WithBatching(ctx)
+
+	return paginate(ctx, nextLinks(s.msgraph, func() (page[json.RawMessage], error) {
+		list, err := s.GetAzureADGroupOwners(ctx, objectId, filter, search, orderBy, selectCols, graphPageSize, false)
+		return page[json.RawMessage]{Value: list.Value, NextLink: list.NextLink}, err
+	}), func(u json.RawMessage, err error) azure.GroupOwnerResult {
+		return azure.GroupOwnerResult{
+			Error:   err,
+			GroupId: objectId,
+			Ok:      u,
 		}
-	}()
-	return out
+	})
 }
 
 func (s *azureClient) ListAzureADGroupMembers(ctx context.Context, objectId string, filter, search, orderBy string, selectCols []string) <-chan azure.MemberObjectResult {
-	out := make(chan azure.MemberObjectResult)
-
-	go func() {
-		defer close(out)
-
-		var (
-			errResult = azure.MemberObjectResult{
-				ParentId:   objectId,
-				ParentType: string(enums.EntityGroup),
-			}
-			nextLink string
-		)
-
-		if list, err := s.GetAzureADGroupMembers(ctx, objectId, filter, search, false); err != nil {
-			errResult.Error = err
-			out <- errResult
-		} else {
-			for _, u := range list.Value {
-				out <- azure.MemberObjectResult{
-					ParentId:   objectId,
-					ParentType: string(enums.EntityGroup),
-					Ok:         u,
-				}
-			}
-
-			nextLink = list.NextLink
-			for nextLink != "" {
-				var list azure.MemberObjectList
-				if url, err := url.Parse(nextLink); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else if req, err := rest.NewRequest(ctx, "GET", url, nil, nil, nil); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else if res, err := s.msgraph.Send(req); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else if err := rest.Decode(res.Body, &list); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else {
-					for _, u := range list.Value {
-						out <- azure.MemberObjectResult{
-							ParentId:   objectId,
-							ParentType: string(enums.EntityGroup),
-							Ok:         u,
-						}
-					}
-					nextLink = list.NextLink
-				}
-			}
+	ctx = rest.WithBatching(ctx)
+
+	return paginate(ctx, nextLinks(s.msgraph, func() (page[json.RawMessage], error) {
+		list, err := s.GetAzureADGroupMembers(ctx, objectId, filter, search, false)
+		return page[json.RawMessage]{Value: list.Value, NextLink: list.NextLink}, err
+	}), func(u json.RawMessage, err error) azure.MemberObjectResult {
+		return azure.MemberObjectResult{
+			Error:      err,
+			ParentId:   objectId,
+			ParentType: string(enums.EntityGroup),
+			Ok:         u,
 		}
-	}()
-	return out
+	})
 }
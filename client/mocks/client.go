@@ -9,6 +9,7 @@ import (
 	json "encoding/json"
 	reflect "reflect"
 
+	rest "github.com/bloodhoundad/azurehound/client/rest"
 	azure "github.com/bloodhoundad/azurehound/models/azure"
 	gomock "github.com/golang/mock/gomock"
 )
@@ -66,6 +67,66 @@ func (mr *MockAzureClientMockRecorder) GetAzureADApps(arg0, arg1, arg2, arg3, ar
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAzureADApps", reflect.TypeOf((*MockAzureClient)(nil).GetAzureADApps), arg0, arg1, arg2, arg3, arg4, arg5, arg6, arg7)
 }
 
+// GetAzureADDeletedApps mocks base method.
+func (m *MockAzureClient) GetAzureADDeletedApps(arg0 context.Context, arg1, arg2, arg3 string, arg4 []string, arg5 int32, arg6 bool) (azure.ApplicationList, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAzureADDeletedApps", arg0, arg1, arg2, arg3, arg4, arg5, arg6)
+	ret0, _ := ret[0].(azure.ApplicationList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAzureADDeletedApps indicates an expected call of GetAzureADDeletedApps.
+func (mr *MockAzureClientMockRecorder) GetAzureADDeletedApps(arg0, arg1, arg2, arg3, arg4, arg5, arg6 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAzureADDeletedApps", reflect.TypeOf((*MockAzureClient)(nil).GetAzureADDeletedApps), arg0, arg1, arg2, arg3, arg4, arg5, arg6)
+}
+
+// GetAzureADDeletedGroups mocks base method.
+func (m *MockAzureClient) GetAzureADDeletedGroups(arg0 context.Context, arg1, arg2, arg3 string, arg4 []string, arg5 int32, arg6 bool) (azure.GroupList, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAzureADDeletedGroups", arg0, arg1, arg2, arg3, arg4, arg5, arg6)
+	ret0, _ := ret[0].(azure.GroupList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAzureADDeletedGroups indicates an expected call of GetAzureADDeletedGroups.
+func (mr *MockAzureClientMockRecorder) GetAzureADDeletedGroups(arg0, arg1, arg2, arg3, arg4, arg5, arg6 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAzureADDeletedGroups", reflect.TypeOf((*MockAzureClient)(nil).GetAzureADDeletedGroups), arg0, arg1, arg2, arg3, arg4, arg5, arg6)
+}
+
+// GetAzureADDeletedServicePrincipals mocks base method.
+func (m *MockAzureClient) GetAzureADDeletedServicePrincipals(arg0 context.Context, arg1, arg2, arg3 string, arg4 []string, arg5 int32, arg6 bool) (azure.ServicePrincipalList, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAzureADDeletedServicePrincipals", arg0, arg1, arg2, arg3, arg4, arg5, arg6)
+	ret0, _ := ret[0].(azure.ServicePrincipalList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAzureADDeletedServicePrincipals indicates an expected call of GetAzureADDeletedServicePrincipals.
+func (mr *MockAzureClientMockRecorder) GetAzureADDeletedServicePrincipals(arg0, arg1, arg2, arg3, arg4, arg5, arg6 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAzureADDeletedServicePrincipals", reflect.TypeOf((*MockAzureClient)(nil).GetAzureADDeletedServicePrincipals), arg0, arg1, arg2, arg3, arg4, arg5, arg6)
+}
+
+// GetAzureADDeletedUsers mocks base method.
+func (m *MockAzureClient) GetAzureADDeletedUsers(arg0 context.Context, arg1, arg2, arg3 string, arg4 []string, arg5 int32, arg6 bool) (azure.UserList, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAzureADDeletedUsers", arg0, arg1, arg2, arg3, arg4, arg5, arg6)
+	ret0, _ := ret[0].(azure.UserList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAzureADDeletedUsers indicates an expected call of GetAzureADDeletedUsers.
+func (mr *MockAzureClientMockRecorder) GetAzureADDeletedUsers(arg0, arg1, arg2, arg3, arg4, arg5, arg6 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAzureADDeletedUsers", reflect.TypeOf((*MockAzureClient)(nil).GetAzureADDeletedUsers), arg0, arg1, arg2, arg3, arg4, arg5, arg6)
+}
+
 // GetAzureADDirectoryObject mocks base method.
 func (m *MockAzureClient) GetAzureADDirectoryObject(arg0 context.Context, arg1 string) (json.RawMessage, error) {
 	m.ctrl.T.Helper()
@@ -411,6 +472,21 @@ func (mr *MockAzureClientMockRecorder) GetAzureResourceGroups(arg0, arg1, arg2,
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAzureResourceGroups", reflect.TypeOf((*MockAzureClient)(nil).GetAzureResourceGroups), arg0, arg1, arg2, arg3)
 }
 
+// GetAzureResources mocks base method.
+func (m *MockAzureClient) GetAzureResources(arg0 context.Context, arg1, arg2, arg3 string, arg4 int32) (azure.ResourceList, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAzureResources", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(azure.ResourceList)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAzureResources indicates an expected call of GetAzureResources.
+func (mr *MockAzureClientMockRecorder) GetAzureResources(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAzureResources", reflect.TypeOf((*MockAzureClient)(nil).GetAzureResources), arg0, arg1, arg2, arg3, arg4)
+}
+
 // GetAzureStorageAccount mocks base method.
 func (m *MockAzureClient) GetAzureStorageAccount(arg0 context.Context, arg1, arg2, arg3, arg4 string) (*azure.StorageAccount, error) {
 	m.ctrl.T.Helper()
@@ -501,6 +577,21 @@ func (mr *MockAzureClientMockRecorder) GetAzureVirtualMachines(arg0, arg1, arg2
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAzureVirtualMachines", reflect.TypeOf((*MockAzureClient)(nil).GetAzureVirtualMachines), arg0, arg1, arg2)
 }
 
+// GetResourceGraphQuery mocks base method.
+func (m *MockAzureClient) GetResourceGraphQuery(arg0 context.Context, arg1 azure.ResourceGraphQueryRequest) (azure.ResourceGraphQueryResponse, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetResourceGraphQuery", arg0, arg1)
+	ret0, _ := ret[0].(azure.ResourceGraphQueryResponse)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetResourceGraphQuery indicates an expected call of GetResourceGraphQuery.
+func (mr *MockAzureClientMockRecorder) GetResourceGraphQuery(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetResourceGraphQuery", reflect.TypeOf((*MockAzureClient)(nil).GetResourceGraphQuery), arg0, arg1)
+}
+
 // GetResourceRoleAssignments mocks base method.
 func (m *MockAzureClient) GetResourceRoleAssignments(arg0 context.Context, arg1, arg2, arg3 string) (azure.RoleAssignmentList, error) {
 	m.ctrl.T.Helper()
@@ -531,6 +622,21 @@ func (mr *MockAzureClientMockRecorder) GetRoleAssignmentsForResource(arg0, arg1,
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRoleAssignmentsForResource", reflect.TypeOf((*MockAzureClient)(nil).GetRoleAssignmentsForResource), arg0, arg1, arg2)
 }
 
+// GraphToken mocks base method.
+func (m *MockAzureClient) GraphToken(arg0 context.Context) (rest.Token, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GraphToken", arg0)
+	ret0, _ := ret[0].(rest.Token)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GraphToken indicates an expected call of GraphToken.
+func (mr *MockAzureClientMockRecorder) GraphToken(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GraphToken", reflect.TypeOf((*MockAzureClient)(nil).GraphToken), arg0)
+}
+
 // ListAzureADAppMemberObjects mocks base method.
 func (m *MockAzureClient) ListAzureADAppMemberObjects(arg0 context.Context, arg1 string, arg2 bool) <-chan azure.MemberObjectResult {
 	m.ctrl.T.Helper()
@@ -587,6 +693,76 @@ func (mr *MockAzureClientMockRecorder) ListAzureADApps(arg0, arg1, arg2, arg3, a
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAzureADApps", reflect.TypeOf((*MockAzureClient)(nil).ListAzureADApps), arg0, arg1, arg2, arg3, arg4, arg5)
 }
 
+// ListAzureADAppsDelta mocks base method.
+func (m *MockAzureClient) ListAzureADAppsDelta(arg0 context.Context, arg1 string, arg2 []string) <-chan azure.ApplicationResult {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAzureADAppsDelta", arg0, arg1, arg2)
+	ret0, _ := ret[0].(<-chan azure.ApplicationResult)
+	return ret0
+}
+
+// ListAzureADAppsDelta indicates an expected call of ListAzureADAppsDelta.
+func (mr *MockAzureClientMockRecorder) ListAzureADAppsDelta(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAzureADAppsDelta", reflect.TypeOf((*MockAzureClient)(nil).ListAzureADAppsDelta), arg0, arg1, arg2)
+}
+
+// ListAzureADDeletedApps mocks base method.
+func (m *MockAzureClient) ListAzureADDeletedApps(arg0 context.Context, arg1, arg2, arg3 string, arg4 []string) <-chan azure.ApplicationResult {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAzureADDeletedApps", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(<-chan azure.ApplicationResult)
+	return ret0
+}
+
+// ListAzureADDeletedApps indicates an expected call of ListAzureADDeletedApps.
+func (mr *MockAzureClientMockRecorder) ListAzureADDeletedApps(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAzureADDeletedApps", reflect.TypeOf((*MockAzureClient)(nil).ListAzureADDeletedApps), arg0, arg1, arg2, arg3, arg4)
+}
+
+// ListAzureADDeletedGroups mocks base method.
+func (m *MockAzureClient) ListAzureADDeletedGroups(arg0 context.Context, arg1, arg2, arg3 string, arg4 []string) <-chan azure.GroupResult {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAzureADDeletedGroups", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(<-chan azure.GroupResult)
+	return ret0
+}
+
+// ListAzureADDeletedGroups indicates an expected call of ListAzureADDeletedGroups.
+func (mr *MockAzureClientMockRecorder) ListAzureADDeletedGroups(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAzureADDeletedGroups", reflect.TypeOf((*MockAzureClient)(nil).ListAzureADDeletedGroups), arg0, arg1, arg2, arg3, arg4)
+}
+
+// ListAzureADDeletedServicePrincipals mocks base method.
+func (m *MockAzureClient) ListAzureADDeletedServicePrincipals(arg0 context.Context, arg1, arg2, arg3 string, arg4 []string) <-chan azure.ServicePrincipalResult {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAzureADDeletedServicePrincipals", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(<-chan azure.ServicePrincipalResult)
+	return ret0
+}
+
+// ListAzureADDeletedServicePrincipals indicates an expected call of ListAzureADDeletedServicePrincipals.
+func (mr *MockAzureClientMockRecorder) ListAzureADDeletedServicePrincipals(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAzureADDeletedServicePrincipals", reflect.TypeOf((*MockAzureClient)(nil).ListAzureADDeletedServicePrincipals), arg0, arg1, arg2, arg3, arg4)
+}
+
+// ListAzureADDeletedUsers mocks base method.
+func (m *MockAzureClient) ListAzureADDeletedUsers(arg0 context.Context, arg1, arg2, arg3 string, arg4 []string) <-chan azure.UserResult {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAzureADDeletedUsers", arg0, arg1, arg2, arg3, arg4)
+	ret0, _ := ret[0].(<-chan azure.UserResult)
+	return ret0
+}
+
+// ListAzureADDeletedUsers indicates an expected call of ListAzureADDeletedUsers.
+func (mr *MockAzureClientMockRecorder) ListAzureADDeletedUsers(arg0, arg1, arg2, arg3, arg4 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAzureADDeletedUsers", reflect.TypeOf((*MockAzureClient)(nil).ListAzureADDeletedUsers), arg0, arg1, arg2, arg3, arg4)
+}
+
 // ListAzureADGroupMembers mocks base method.
 func (m *MockAzureClient) ListAzureADGroupMembers(arg0 context.Context, arg1, arg2, arg3, arg4 string, arg5 []string) <-chan azure.MemberObjectResult {
 	m.ctrl.T.Helper()
@@ -629,6 +805,20 @@ func (mr *MockAzureClientMockRecorder) ListAzureADGroups(arg0, arg1, arg2, arg3,
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAzureADGroups", reflect.TypeOf((*MockAzureClient)(nil).ListAzureADGroups), arg0, arg1, arg2, arg3, arg4, arg5)
 }
 
+// ListAzureADGroupsDelta mocks base method.
+func (m *MockAzureClient) ListAzureADGroupsDelta(arg0 context.Context, arg1 string, arg2 []string) <-chan azure.GroupResult {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAzureADGroupsDelta", arg0, arg1, arg2)
+	ret0, _ := ret[0].(<-chan azure.GroupResult)
+	return ret0
+}
+
+// ListAzureADGroupsDelta indicates an expected call of ListAzureADGroupsDelta.
+func (mr *MockAzureClientMockRecorder) ListAzureADGroupsDelta(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAzureADGroupsDelta", reflect.TypeOf((*MockAzureClient)(nil).ListAzureADGroupsDelta), arg0, arg1, arg2)
+}
+
 // ListAzureADRoleAssignments mocks base method.
 func (m *MockAzureClient) ListAzureADRoleAssignments(arg0 context.Context, arg1, arg2, arg3, arg4 string, arg5 []string) <-chan azure.UnifiedRoleAssignmentResult {
 	m.ctrl.T.Helper()
@@ -685,6 +875,20 @@ func (mr *MockAzureClientMockRecorder) ListAzureADServicePrincipals(arg0, arg1,
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAzureADServicePrincipals", reflect.TypeOf((*MockAzureClient)(nil).ListAzureADServicePrincipals), arg0, arg1, arg2, arg3, arg4, arg5)
 }
 
+// ListAzureADServicePrincipalsDelta mocks base method.
+func (m *MockAzureClient) ListAzureADServicePrincipalsDelta(arg0 context.Context, arg1 string, arg2 []string) <-chan azure.ServicePrincipalResult {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAzureADServicePrincipalsDelta", arg0, arg1, arg2)
+	ret0, _ := ret[0].(<-chan azure.ServicePrincipalResult)
+	return ret0
+}
+
+// ListAzureADServicePrincipalsDelta indicates an expected call of ListAzureADServicePrincipalsDelta.
+func (mr *MockAzureClientMockRecorder) ListAzureADServicePrincipalsDelta(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAzureADServicePrincipalsDelta", reflect.TypeOf((*MockAzureClient)(nil).ListAzureADServicePrincipalsDelta), arg0, arg1, arg2)
+}
+
 // ListAzureADTenants mocks base method.
 func (m *MockAzureClient) ListAzureADTenants(arg0 context.Context, arg1 bool) <-chan azure.TenantResult {
 	m.ctrl.T.Helper()
@@ -713,6 +917,20 @@ func (mr *MockAzureClientMockRecorder) ListAzureADUsers(arg0, arg1, arg2, arg3,
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAzureADUsers", reflect.TypeOf((*MockAzureClient)(nil).ListAzureADUsers), arg0, arg1, arg2, arg3, arg4)
 }
 
+// ListAzureADUsersDelta mocks base method.
+func (m *MockAzureClient) ListAzureADUsersDelta(arg0 context.Context, arg1 string, arg2 []string) <-chan azure.UserResult {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAzureADUsersDelta", arg0, arg1, arg2)
+	ret0, _ := ret[0].(<-chan azure.UserResult)
+	return ret0
+}
+
+// ListAzureADUsersDelta indicates an expected call of ListAzureADUsersDelta.
+func (mr *MockAzureClientMockRecorder) ListAzureADUsersDelta(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAzureADUsersDelta", reflect.TypeOf((*MockAzureClient)(nil).ListAzureADUsersDelta), arg0, arg1, arg2)
+}
+
 // ListAzureAutomationAccounts mocks base method.
 func (m *MockAzureClient) ListAzureAutomationAccounts(arg0 context.Context, arg1 string) <-chan azure.AutomationAccountResult {
 	m.ctrl.T.Helper()
@@ -825,6 +1043,20 @@ func (mr *MockAzureClientMockRecorder) ListAzureResourceGroups(arg0, arg1, arg2
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAzureResourceGroups", reflect.TypeOf((*MockAzureClient)(nil).ListAzureResourceGroups), arg0, arg1, arg2)
 }
 
+// ListAzureResources mocks base method.
+func (m *MockAzureClient) ListAzureResources(arg0 context.Context, arg1, arg2, arg3 string) <-chan azure.ResourceResult {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListAzureResources", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(<-chan azure.ResourceResult)
+	return ret0
+}
+
+// ListAzureResources indicates an expected call of ListAzureResources.
+func (mr *MockAzureClientMockRecorder) ListAzureResources(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAzureResources", reflect.TypeOf((*MockAzureClient)(nil).ListAzureResources), arg0, arg1, arg2, arg3)
+}
+
 // ListAzureStorageAccounts mocks base method.
 func (m *MockAzureClient) ListAzureStorageAccounts(arg0 context.Context, arg1 string) <-chan azure.StorageAccountResult {
 	m.ctrl.T.Helper()
@@ -895,6 +1127,20 @@ func (mr *MockAzureClientMockRecorder) ListAzureWorkflows(arg0, arg1, arg2, arg3
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListAzureWorkflows", reflect.TypeOf((*MockAzureClient)(nil).ListAzureWorkflows), arg0, arg1, arg2, arg3)
 }
 
+// ListResourceGraphQuery mocks base method.
+func (m *MockAzureClient) ListResourceGraphQuery(arg0 context.Context, arg1, arg2 []string, arg3 string) <-chan azure.ResourceGraphResult {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListResourceGraphQuery", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(<-chan azure.ResourceGraphResult)
+	return ret0
+}
+
+// ListResourceGraphQuery indicates an expected call of ListResourceGraphQuery.
+func (mr *MockAzureClientMockRecorder) ListResourceGraphQuery(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListResourceGraphQuery", reflect.TypeOf((*MockAzureClient)(nil).ListResourceGraphQuery), arg0, arg1, arg2, arg3)
+}
+
 // ListResourceRoleAssignments mocks base method.
 func (m *MockAzureClient) ListResourceRoleAssignments(arg0 context.Context, arg1, arg2, arg3 string) <-chan azure.RoleAssignmentResult {
 	m.ctrl.T.Helper()
@@ -923,6 +1169,21 @@ func (mr *MockAzureClientMockRecorder) ListRoleAssignmentsForResource(arg0, arg1
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListRoleAssignmentsForResource", reflect.TypeOf((*MockAzureClient)(nil).ListRoleAssignmentsForResource), arg0, arg1, arg2)
 }
 
+// ResourceManagerToken mocks base method.
+func (m *MockAzureClient) ResourceManagerToken(arg0 context.Context) (rest.Token, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResourceManagerToken", arg0)
+	ret0, _ := ret[0].(rest.Token)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ResourceManagerToken indicates an expected call of ResourceManagerToken.
+func (mr *MockAzureClientMockRecorder) ResourceManagerToken(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResourceManagerToken", reflect.TypeOf((*MockAzureClient)(nil).ResourceManagerToken), arg0)
+}
+
 // TenantInfo mocks base method.
 func (m *MockAzureClient) TenantInfo() azure.Tenant {
 	m.ctrl.T.Helper()
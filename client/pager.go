@@ -0,0 +1,147 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package client
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/url"
+	"time"
+
+	"github.com/bloodhoundad/azurehound/client/rest"
+)
+
+const (
+	// The page size requested from Microsoft Graph collections, which return at most 999 objects per page
+	graphPageSize int32 = 999
+
+	// How many times a page is requested before paginating is abandoned
+	maxPageAttempts = 3
+)
+
+// How long to wait before requesting a page again, multiplied by the number of attempts so far
+var pageRetryDelay = time.Second
+
+// page is a single page of a paginated list along with the link, or skip token, identifying the next page
+type page[T any] struct {
+	Value    []T
+	NextLink string
+}
+
+// linkPage is a page followed from a link of a previous page; Graph and ARM name the link differently
+type linkPage[T any] struct {
+	Value         []T    `json:"value"`
+	NextLink      string `json:"nextLink,omitempty"`
+	ODataNextLink string `json:"@odata.nextLink,omitempty"`
+}
+
+// pageFetcher fetches the page identified by the link, or skip token, of the previous page and the first page when
+// there is none
+type pageFetcher[T any] func(ctx context.Context, link string) (page[T], error)
+
+// nextLinks returns a pageFetcher that fetches the first page with first and every page after it by following the
+// Graph `@odata.nextLink` or ARM `nextLink` of the previous page with client
+func nextLinks[T any](client rest.RestClient, first func() (page[T], error)) pageFetcher[T] {
+	return func(ctx context.Context, link string) (page[T], error) {
+		var response linkPage[T]
+		if link == "" {
+			return first()
+		} else if url, err := url.Parse(link); err != nil {
+			return page[T]{}, err
+		} else if req, err := rest.NewRequest(ctx, "GET", url, nil, nil, nil); err != nil {
+			return page[T]{}, err
+		} else if res, err := client.Send(req); err != nil {
+			return page[T]{}, err
+		} else if err := rest.Decode(res.Body, &response); err != nil {
+			return page[T]{}, err
+		} else if response.ODataNextLink != "" {
+			return page[T]{Value: response.Value, NextLink: response.ODataNextLink}, nil
+		} else {
+			return page[T]{Value: response.Value, NextLink: response.NextLink}, nil
+		}
+	}
+}
+
+// paginate emits result for every value of every page fetched by fetch. A page that can't be fetched is retried
+// before paginating stops with a single error result; emitting stops as soon as ctx is done.
+func paginate[T, R any](ctx context.Context, fetch pageFetcher[T], result func(T, error) R) <-chan R {
+	out := make(chan R)
+
+	go func() {
+		defer close(out)
+
+		var (
+			zero T
+			link string
+		)
+
+		for {
+			if page, err := fetchPage(ctx, fetch, link); err != nil {
+				send(ctx, out, result(zero, err))
+				return
+			} else {
+				for _, u := range page.Value {
+					if ok := send(ctx, out, result(u, nil)); !ok {
+						return
+					}
+				}
+
+				if page.NextLink == "" {
+					return
+				} else {
+					link = page.NextLink
+				}
+			}
+		}
+	}()
+	return out
+}
+
+func fetchPage[T any](ctx context.Context, fetch pageFetcher[T], link string) (page[T], error) {
+	for attempt := 1; ; attempt++ {
+		if page, err := fetch(ctx, link); err == nil {
+			return page, nil
+		} else if attempt >= maxPageAttempts || !retryablePageError(ctx, err) {
+			return page, err
+		} else {
+			select {
+			case <-ctx.Done():
+				return page, ctx.Err()
+			case <-time.After(pageRetryDelay * time.Duration(attempt)):
+			}
+		}
+	}
+}
+
+// retryablePageError reports whether requesting the page again may succeed, which is only the case for a response cut
+// off while its body was read. Network errors and timeouts have already been retried by the rest client as part of
+// sending the request, and error responses, expired tokens and errors in the request itself won't change on a second
+// attempt.
+func retryablePageError(ctx context.Context, err error) bool {
+	return ctx.Err() == nil && errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+func send[T any](ctx context.Context, out chan<- T, value T) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case out <- value:
+		return true
+	}
+}
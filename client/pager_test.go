@@ -0,0 +1,139 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/bloodhoundad/azurehound/client/rest"
+)
+
+func TestPaginate(t *testing.T) {
+	var (
+		ctx      = context.Background()
+		attempts = 0
+		pages    = map[string]page[int]{
+			"":       {Value: []int{1, 2}, NextLink: "second"},
+			"second": {Value: []int{3}, NextLink: "third"},
+			"third":  {Value: []int{4}},
+		}
+	)
+
+	fetch := func(ctx context.Context, link string) (page[int], error) {
+		// the second page fails once before succeeding
+		if link == "second" && attempts == 0 {
+			attempts++
+			return page[int]{}, fmt.Errorf("unable to read response body: %w", io.ErrUnexpectedEOF)
+		}
+		return pages[link], nil
+	}
+
+	var got []int
+	for item := range paginate(ctx, fetch, func(u int, err error) error {
+		got = append(got, u)
+		return err
+	}) {
+		if item != nil {
+			t.Errorf("unexpected error: %v", item)
+		}
+	}
+
+	if fmt.Sprint(got) != "[1 2 3 4]" {
+		t.Errorf("got %v, want [1 2 3 4]", got)
+	}
+}
+
+func TestPaginateResponseError(t *testing.T) {
+	var (
+		ctx      = context.Background()
+		attempts = 0
+	)
+
+	fetch := func(ctx context.Context, link string) (page[int], error) {
+		attempts++
+		return page[int]{}, rest.ResponseError{StatusCode: 403}
+	}
+
+	count := 0
+	for item := range paginate(ctx, fetch, func(u int, err error) error { return err }) {
+		count++
+		if item == nil {
+			t.Error("expected an error result")
+		}
+	}
+
+	if count != 1 {
+		t.Errorf("got %d results, want 1", count)
+	}
+	if attempts != 1 {
+		t.Errorf("got %d attempts, want 1", attempts)
+	}
+}
+
+func TestPaginateRetryableErrors(t *testing.T) {
+	defer func(delay time.Duration) { pageRetryDelay = delay }(pageRetryDelay)
+	pageRetryDelay = time.Millisecond
+
+	ctx := context.Background()
+	tests := []struct {
+		err  error
+		want int
+	}{
+		{io.ErrUnexpectedEOF, maxPageAttempts},
+		{fmt.Errorf("unable to complete the request after 3 attempts: %w", &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}), 1},
+		{context.DeadlineExceeded, 1},
+		{rest.ErrTokenExpired, 1},
+		{rest.ErrNotRecorded, 1},
+		{fmt.Errorf("invalid audience"), 1},
+	}
+
+	for _, test := range tests {
+		attempts := 0
+		fetch := func(ctx context.Context, link string) (page[int], error) {
+			attempts++
+			return page[int]{}, test.err
+		}
+
+		for range paginate(ctx, fetch, func(u int, err error) error { return err }) {
+		}
+		if attempts != test.want {
+			t.Errorf("got %d attempts for %v, want %d", attempts, test.err, test.want)
+		}
+	}
+}
+
+func TestPaginateCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	fetch := func(ctx context.Context, link string) (page[int], error) {
+		return page[int]{Value: []int{1, 2, 3}, NextLink: "next"}, nil
+	}
+
+	channel := paginate(ctx, fetch, func(u int, err error) int { return u })
+	<-channel
+	cancel()
+
+	for range channel {
+	}
+}
@@ -0,0 +1,104 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/bloodhoundad/azurehound/client/query"
+	"github.com/bloodhoundad/azurehound/client/rest"
+	"github.com/bloodhoundad/azurehound/models/azure"
+)
+
+// Azure Resource Graph accepts at most this many subscriptions in a single query request
+const maxResourceGraphSubscriptions = 1000
+
+func (s *azureClient) GetResourceGraphQuery(ctx context.Context, request azure.ResourceGraphQueryRequest) (azure.ResourceGraphQueryResponse, error) {
+	var (
+		path     = "/providers/Microsoft.ResourceGraph/resources"
+		params   = query.Params{ApiVersion: "2021-03-01"}.AsMap()
+		headers  map[string]string
+		response azure.ResourceGraphQueryResponse
+	)
+
+	if request.Options.ResultFormat == "" {
+		request.Options.ResultFormat = "objectArray"
+	}
+
+	if res, err := s.resourceManager.Post(ctx, path, request, params, headers); err != nil {
+		return response, err
+	} else if err := rest.Decode(res.Body, &response); err != nil {
+		return response, err
+	} else {
+		return response, nil
+	}
+}
+
+func (s *azureClient) ListResourceGraphQuery(ctx context.Context, subscriptionIds []string, managementGroupIds []string, kql string) <-chan azure.ResourceGraphResult {
+	var (
+		out      = make(chan azure.ResourceGraphResult)
+		requests []azure.ResourceGraphQueryRequest
+	)
+
+	if len(subscriptionIds) == 0 {
+		requests = append(requests, azure.ResourceGraphQueryRequest{
+			ManagementGroups: managementGroupIds,
+			Query:            kql,
+		})
+	}
+
+	for i := 0; i < len(subscriptionIds); i += maxResourceGraphSubscriptions {
+		end := i + maxResourceGraphSubscriptions
+		if end > len(subscriptionIds) {
+			end = len(subscriptionIds)
+		}
+		requests = append(requests, azure.ResourceGraphQueryRequest{
+			Subscriptions:    subscriptionIds[i:end],
+			ManagementGroups: managementGroupIds,
+			Query:            kql,
+		})
+	}
+
+	go func() {
+		defer close(out)
+
+		for _, request := range requests {
+			request := request
+			request.Options.Top = 1000
+
+			// resource graph pages are identified by a skip token rather than a link
+			fetch := func(ctx context.Context, skipToken string) (page[json.RawMessage], error) {
+				request.Options.SkipToken = skipToken
+				result, err := s.GetResourceGraphQuery(ctx, request)
+				return page[json.RawMessage]{Value: result.Data, NextLink: result.SkipToken}, err
+			}
+
+			for item := range paginate(ctx, fetch, func(u json.RawMessage, err error) azure.ResourceGraphResult {
+				return azure.ResourceGraphResult{Error: err, Ok: u}
+			}) {
+				if ok := send(ctx, out, item); !ok {
+					return
+				} else if item.Error != nil {
+					return
+				}
+			}
+		}
+	}()
+	return out
+}
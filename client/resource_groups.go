@@ -20,7 +20,6 @@ package client
 import (
 	"context"
 	"fmt"
-	"net/url"
 
 	"github.com/bloodhoundad/azurehound/client/query"
 	"github.com/bloodhoundad/azurehound/client/rest"
@@ -61,58 +60,15 @@ func (s *azureClient) GetAzureResourceGroups(ctx context.Context, subscriptionId
 }
 
 func (s *azureClient) ListAzureResourceGroups(ctx context.Context, subscriptionId, filter string) <-chan azure.ResourceGroupResult {
-	out := make(chan azure.ResourceGroupResult)
-
-	go func() {
-		defer close(out)
-
-		var (
-			objectId  = fmt.Sprintf("/subscriptions/%s", subscriptionId)
-			errResult = azure.ResourceGroupResult{SubscriptionId: objectId}
-			nextLink  string
-		)
-
-		if result, err := s.GetAzureResourceGroups(ctx, subscriptionId, filter, 1000); err != nil {
-			errResult.Error = err
-			out <- errResult
-		} else {
-			for _, u := range result.Value {
-				out <- azure.ResourceGroupResult{
-					SubscriptionId: objectId,
-					Ok:             u,
-				}
-			}
-
-			nextLink = result.NextLink
-			for nextLink != "" {
-				var list azure.ResourceGroupList
-				if url, err := url.Parse(nextLink); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else if req, err := rest.NewRequest(ctx, "GET", url, nil, nil, nil); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else if res, err := s.resourceManager.Send(req); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else if err := rest.Decode(res.Body, &list); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else {
-					for _, u := range list.Value {
-						out <- azure.ResourceGroupResult{
-							SubscriptionId: objectId,
-							Ok:             u,
-						}
-					}
-					nextLink = list.NextLink
-				}
-			}
+	objectId := fmt.Sprintf("/subscriptions/%s", subscriptionId)
+	return paginate(ctx, nextLinks(s.resourceManager, func() (page[azure.ResourceGroup], error) {
+		result, err := s.GetAzureResourceGroups(ctx, subscriptionId, filter, 1000)
+		return page[azure.ResourceGroup]{Value: result.Value, NextLink: result.NextLink}, err
+	}), func(u azure.ResourceGroup, err error) azure.ResourceGroupResult {
+		return azure.ResourceGroupResult{
+			Error:          err,
+			SubscriptionId: objectId,
+			Ok:             u,
 		}
-	}()
-	return out
+	})
 }
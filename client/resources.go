@@ -0,0 +1,57 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bloodhoundad/azurehound/client/query"
+	"github.com/bloodhoundad/azurehound/client/rest"
+	"github.com/bloodhoundad/azurehound/models/azure"
+)
+
+func (s *azureClient) GetAzureResources(ctx context.Context, subscriptionId string, filter string, expand string, top int32) (azure.ResourceList, error) {
+	var (
+		path     = fmt.Sprintf("/subscriptions/%s/resources", subscriptionId)
+		params   = query.Params{ApiVersion: "2021-04-01", Filter: filter, Expand: expand, Top: top}.AsMap()
+		headers  map[string]string
+		response azure.ResourceList
+	)
+
+	if res, err := s.resourceManager.Get(ctx, path, params, headers); err != nil {
+		return response, err
+	} else if err := rest.Decode(res.Body, &response); err != nil {
+		return response, err
+	} else {
+		return response, nil
+	}
+}
+
+func (s *azureClient) ListAzureResources(ctx context.Context, subscriptionId string, filter string, expand string) <-chan azure.ResourceResult {
+	return paginate(ctx, nextLinks(s.resourceManager, func() (page[azure.Resource], error) {
+		result, err := s.GetAzureResources(ctx, subscriptionId, filter, expand, 1000)
+		return page[azure.Resource]{Value: result.Value, NextLink: result.NextLink}, err
+	}), func(u azure.Resource, err error) azure.ResourceResult {
+		return azure.ResourceResult{
+			Error:          err,
+			SubscriptionId: subscriptionId,
+			Ok:             u,
+		}
+	})
+}
@@ -0,0 +1,338 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package rest
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// Microsoft Graph accepts at most 20 requests in a single JSON batch
+	maxBatchSize = 20
+
+	// How long a request waits for others to share its batch before the batch is sent
+	batchLinger = 10 * time.Millisecond
+)
+
+type batchKey struct{}
+
+// WithBatching marks requests made with the returned context as eligible to be combined into Microsoft Graph JSON
+// batches by a client created with NewBatchClient
+func WithBatching(ctx context.Context) context.Context {
+	return context.WithValue(ctx, batchKey{}, true)
+}
+
+func isBatchable(req *http.Request) bool {
+	batchable, _ := req.Context().Value(batchKey{}).(bool)
+	return batchable && req.Method == http.MethodGet
+}
+
+type batchRequestItem struct {
+	Id      string            `json:"id"`
+	Method  string            `json:"method"`
+	Url     string            `json:"url"`
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+type batchRequest struct {
+	Requests []batchRequestItem `json:"requests"`
+}
+
+type batchResponseItem struct {
+	Id      string            `json:"id"`
+	Status  int               `json:"status"`
+	Headers map[string]string `json:"headers"`
+	Body    json.RawMessage   `json:"body"`
+}
+
+type batchResponse struct {
+	Responses []batchResponseItem `json:"responses"`
+}
+
+type batchItem struct {
+	req      *http.Request
+	version  string
+	url      string
+	attempts int
+	result   chan batchResult
+}
+
+type batchResult struct {
+	res *http.Response
+	err error
+}
+
+// NewBatchClient wraps a Microsoft Graph RestClient so that concurrent GET requests marked with WithBatching are sent
+// in JSON batches of up to 20 requests instead of individually. Throttled items are retried in a later batch as
+// decided by the retry policy and slow down the wrapped client's rate limiter like throttled requests do; every other
+// request is passed through to the wrapped client unchanged.
+func NewBatchClient(client RestClient, retry RetryPolicy) RestClient {
+	var limiter RateLimiter = unlimited{}
+	if restClient, ok := client.(*restClient); ok {
+		limiter = restClient.limiter
+	}
+	return &batchClient{
+		RestClient: client,
+		retry:      retry,
+		limiter:    limiter,
+		pending:    make(map[string][]*batchItem),
+		timers:     make(map[string]*time.Timer),
+	}
+}
+
+type batchClient struct {
+	RestClient
+	retry   RetryPolicy
+	limiter RateLimiter
+	mutex   sync.Mutex
+	pending map[string][]*batchItem
+	timers  map[string]*time.Timer
+}
+
+func (s *batchClient) Get(ctx context.Context, path string, params, headers map[string]string) (*http.Response, error) {
+	if req, err := NewRequest(ctx, http.MethodGet, &url.URL{Path: path}, nil, params, headers); err != nil {
+		return nil, err
+	} else if !isBatchable(req) {
+		return s.RestClient.Get(ctx, path, params, headers)
+	} else {
+		return s.Send(req)
+	}
+}
+
+func (s *batchClient) Send(req *http.Request) (*http.Response, error) {
+	if !isBatchable(req) {
+		return s.RestClient.Send(req)
+	}
+
+	// batch item urls are relative to the api version, e.g. /v1.0/groups/{id}/owners becomes /groups/{id}/owners
+	var (
+		path    = strings.TrimPrefix(req.URL.Path, "/")
+		version = path
+		rest    = ""
+	)
+	if i := strings.Index(path, "/"); i >= 0 {
+		version, rest = path[:i], path[i:]
+	}
+	if req.URL.RawQuery != "" {
+		rest += "?" + req.URL.RawQuery
+	}
+
+	item := &batchItem{
+		req:     req,
+		version: version,
+		url:     rest,
+		result:  make(chan batchResult, 1),
+	}
+	s.enqueue(item)
+
+	select {
+	case <-req.Context().Done():
+		return nil, req.Context().Err()
+	case result := <-item.result:
+		return result.res, result.err
+	}
+}
+
+func (s *batchClient) enqueue(item *batchItem) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	pending := append(s.pending[item.version], item)
+	if len(pending) >= maxBatchSize {
+		s.pending[item.version] = nil
+		if timer, ok := s.timers[item.version]; ok {
+			timer.Stop()
+			delete(s.timers, item.version)
+		}
+		go s.flush(item.version, pending)
+	} else {
+		s.pending[item.version] = pending
+		if _, ok := s.timers[item.version]; !ok {
+			s.timers[item.version] = time.AfterFunc(batchLinger, func() {
+				s.flushPending(item.version)
+			})
+		}
+	}
+}
+
+func (s *batchClient) flushPending(version string) {
+	s.mutex.Lock()
+	items := s.pending[version]
+	s.pending[version] = nil
+	delete(s.timers, version)
+	s.mutex.Unlock()
+
+	if len(items) > 0 {
+		s.flush(version, items)
+	}
+}
+
+func (s *batchClient) flush(version string, items []*batchItem) {
+	// requests that were cancelled while waiting for their batch have already returned
+	var waiting []*batchItem
+	for _, item := range items {
+		if item.req.Context().Err() == nil {
+			waiting = append(waiting, item)
+		}
+	}
+	if len(waiting) == 0 {
+		return
+	}
+
+	var (
+		path        = fmt.Sprintf("/%s/$batch", version)
+		body        = batchRequest{}
+		byId        = make(map[string]*batchItem, len(waiting))
+		results     batchResponse
+		ctx, cancel = batchContext(waiting)
+	)
+	defer cancel()
+
+	for i, item := range waiting {
+		id := strconv.Itoa(i)
+		byId[id] = item
+		body.Requests = append(body.Requests, batchRequestItem{
+			Id:      id,
+			Method:  http.MethodGet,
+			Url:     item.url,
+			Headers: batchHeaders(item.req.Header),
+		})
+	}
+
+	if res, err := s.RestClient.Post(ctx, path, body, nil, nil); err != nil {
+		for _, item := range waiting {
+			item.result <- batchResult{err: err}
+		}
+	} else if err := Decode(res.Body, &results); err != nil {
+		for _, item := range waiting {
+			item.result <- batchResult{err: err}
+		}
+	} else {
+		for _, response := range results.Responses {
+			if item, ok := byId[response.Id]; ok {
+				delete(byId, response.Id)
+				s.complete(item, response, res.Request)
+			}
+		}
+		for _, item := range byId {
+			item.result <- batchResult{err: fmt.Errorf("batch response is missing a response for %s", item.url)}
+		}
+	}
+}
+
+// batchContext returns a context for sending a batch that is cancelled once the contexts of all of its requests are
+func batchContext(items []*batchItem) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		for _, item := range items {
+			select {
+			case <-ctx.Done():
+				return
+			case <-item.req.Context().Done():
+			}
+		}
+		cancel()
+	}()
+	return ctx, cancel
+}
+
+// complete hands the response of a batch item to the request waiting for it, or queues the item again if it was
+// throttled. The limiter observes each item under the batch request, which shares the host's throttling limit.
+func (s *batchClient) complete(item *batchItem, response batchResponseItem, batchReq *http.Request) {
+	item.attempts++
+
+	header := http.Header{}
+	for key, value := range response.Headers {
+		header.Set(key, value)
+	}
+	res := &http.Response{
+		Status:     fmt.Sprintf("%d %s", response.Status, http.StatusText(response.Status)),
+		StatusCode: response.Status,
+		Header:     header,
+		Request:    item.req,
+	}
+	if batchReq != nil {
+		s.limiter.Observe(batchReq, res)
+	}
+
+	if body, err := batchBody(response.Body); err != nil {
+		item.result <- batchResult{err: err}
+	} else if response.Status >= http.StatusOK && response.Status < http.StatusBadRequest {
+		res.Body = io.NopCloser(bytes.NewReader(body))
+		res.ContentLength = int64(len(body))
+		item.result <- batchResult{res: res}
+	} else if delay, retry := s.retry.Retry(item.attempts, res, nil); retry {
+		// throttled items are sent again in a later batch
+		if item.req.Context().Err() == nil {
+			time.AfterFunc(delay, func() {
+				s.enqueue(item)
+			})
+		}
+	} else {
+		var (
+			errRes map[string]interface{}
+			err    error = ResponseError{StatusCode: response.Status}
+		)
+		if json.Unmarshal(body, &errRes) == nil {
+			err = ResponseError{StatusCode: response.Status, Body: errRes}
+		}
+		if item.attempts > 1 {
+			err = fmt.Errorf("unable to complete the request after %d attempts: %w", item.attempts, err)
+		}
+		item.result <- batchResult{err: err}
+	}
+}
+
+// batchHeaders returns the request headers that need to be forwarded with a batch item; authorization is provided by
+// the batch request itself
+func batchHeaders(header http.Header) map[string]string {
+	headers := make(map[string]string)
+	for key := range header {
+		switch http.CanonicalHeaderKey(key) {
+		case "Authorization", "User-Agent":
+		default:
+			headers[key] = header.Get(key)
+		}
+	}
+	return headers
+}
+
+// batchBody returns the raw body of a batch item; Graph base64 encodes bodies that are not JSON
+func batchBody(body json.RawMessage) ([]byte, error) {
+	var encoded string
+	if len(body) == 0 || body[0] != '"' {
+		return body, nil
+	} else if err := json.Unmarshal(body, &encoded); err != nil {
+		return nil, err
+	} else if decoded, err := base64.StdEncoding.DecodeString(encoded); err != nil {
+		return []byte(encoded), nil
+	} else {
+		return decoded, nil
+	}
+}
@@ -0,0 +1,231 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package rest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bloodhoundad/azurehound/client/config"
+)
+
+type fakeGraph struct {
+	RestClient
+	mutex     sync.Mutex
+	batches   []batchRequest
+	throttled map[string]bool
+}
+
+func (s *fakeGraph) Post(ctx context.Context, path string, body interface{}, params, headers map[string]string) (*http.Response, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var (
+		batch    = body.(batchRequest)
+		response batchResponse
+	)
+	s.batches = append(s.batches, batch)
+
+	if path != "/beta/$batch" {
+		return nil, fmt.Errorf("unexpected batch path %s", path)
+	}
+
+	for _, item := range batch.Requests {
+		path := strings.SplitN(item.Url, "?", 2)[0]
+		if path == "/groups/throttled/owners" && !s.throttled[path] {
+			s.throttled[path] = true
+			response.Responses = append(response.Responses, batchResponseItem{
+				Id:      item.Id,
+				Status:  http.StatusTooManyRequests,
+				Headers: map[string]string{"Retry-After": "0"},
+			})
+		} else if path == "/groups/missing/owners" {
+			response.Responses = append(response.Responses, batchResponseItem{
+				Id:     item.Id,
+				Status: http.StatusNotFound,
+				Body:   json.RawMessage(`{"error":{"code":"Request_ResourceNotFound"}}`),
+			})
+		} else {
+			response.Responses = append(response.Responses, batchResponseItem{
+				Id:     item.Id,
+				Status: http.StatusOK,
+				Body:   json.RawMessage(fmt.Sprintf(`{"url":%q}`, item.Url)),
+			})
+		}
+	}
+
+	data, _ := json.Marshal(response)
+	return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(bytes.NewReader(data))}, nil
+}
+
+func TestBatchClient(t *testing.T) {
+	var (
+		graph  = &fakeGraph{throttled: make(map[string]bool)}
+		client = NewBatchClient(graph, NewRetryPolicy(DefaultMaxRetries, DefaultMaxBackoff))
+		ctx    = WithBatching(context.Background())
+		wg     sync.WaitGroup
+	)
+
+	get := func(path string) (string, error) {
+		var body struct {
+			Url string `json:"url"`
+		}
+		if res, err := client.Get(ctx, path, map[string]string{"$top": "999"}, nil); err != nil {
+			return "", err
+		} else if err := Decode(res.Body, &body); err != nil {
+			return "", err
+		} else {
+			return body.Url, nil
+		}
+	}
+
+	wg.Add(25)
+	for i := 0; i < 25; i++ {
+		path := fmt.Sprintf("/beta/groups/%d/owners", i)
+		go func() {
+			defer wg.Done()
+			if got, err := get(path); err != nil {
+				t.Errorf("unexpected error: %v", err)
+			} else if want := path[len("/beta"):] + "?%24top=999"; got != want {
+				t.Errorf("got %s, want %s", got, want)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(graph.batches) != 2 {
+		t.Errorf("got %d batches, want 2", len(graph.batches))
+	} else if len(graph.batches[0].Requests) != maxBatchSize {
+		t.Errorf("got %d requests in the first batch, want %d", len(graph.batches[0].Requests), maxBatchSize)
+	}
+
+	if endpoint, err := url.Parse("https://graph.microsoft.com/beta/groups/throttled/owners"); err != nil {
+		t.Fatal(err)
+	} else if req, err := NewRequest(ctx, http.MethodGet, endpoint, nil, nil, nil); err != nil {
+		t.Fatal(err)
+	} else if res, err := client.Send(req); err != nil {
+		t.Errorf("throttled request should have been retried: %v", err)
+	} else if res.StatusCode != http.StatusOK {
+		t.Errorf("got status code %d, want %d", res.StatusCode, http.StatusOK)
+	}
+
+	if _, err := get("/beta/groups/missing/owners"); err == nil {
+		t.Error("expected an error for a not found response")
+	}
+}
+
+// hangingGraph holds batch requests until they are cancelled
+type hangingGraph struct {
+	RestClient
+	cancelled chan struct{}
+}
+
+func (s *hangingGraph) Post(ctx context.Context, path string, body interface{}, params, headers map[string]string) (*http.Response, error) {
+	<-ctx.Done()
+	close(s.cancelled)
+	return nil, ctx.Err()
+}
+
+func TestBatchClientCancel(t *testing.T) {
+	var (
+		graph       = &hangingGraph{cancelled: make(chan struct{})}
+		client      = NewBatchClient(graph, NewRetryPolicy(DefaultMaxRetries, DefaultMaxBackoff))
+		ctx, cancel = context.WithCancel(WithBatching(context.Background()))
+		wg          sync.WaitGroup
+	)
+
+	for _, path := range []string{"/beta/groups/a/owners", "/beta/groups/b/owners"} {
+		path := path
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := client.Get(ctx, path, nil, nil); !errors.Is(err, context.Canceled) {
+				t.Errorf("got error %v, want %v", err, context.Canceled)
+			}
+		}()
+	}
+
+	time.Sleep(2 * batchLinger)
+	cancel()
+	wg.Wait()
+
+	select {
+	case <-graph.cancelled:
+	case <-time.After(time.Second):
+		t.Error("batch request was not cancelled with the requests waiting for it")
+	}
+}
+
+func TestBatchClientThrottling(t *testing.T) {
+	var throttled int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var batch batchRequest
+		if r.URL.Path == "/tenant/oauth2/v2.0/token" {
+			json.NewEncoder(w).Encode(map[string]interface{}{"access_token": "token", "expires_in": 3600})
+		} else if err := json.NewDecoder(r.Body).Decode(&batch); err != nil || len(batch.Requests) != 1 {
+			w.WriteHeader(http.StatusBadRequest)
+		} else if atomic.AddInt32(&throttled, 1) == 1 {
+			json.NewEncoder(w).Encode(batchResponse{Responses: []batchResponseItem{{
+				Id:      batch.Requests[0].Id,
+				Status:  http.StatusTooManyRequests,
+				Headers: map[string]string{"Retry-After": "0"},
+			}}})
+		} else {
+			json.NewEncoder(w).Encode(batchResponse{Responses: []batchResponseItem{{
+				Id:     batch.Requests[0].Id,
+				Status: http.StatusOK,
+				Body:   json.RawMessage(`{}`),
+			}}})
+		}
+	}))
+	defer server.Close()
+
+	graph, err := NewRestClient(server.URL, config.Config{Authority: server.URL, ApplicationId: "app", ClientSecret: "secret", Tenant: "tenant", RateLimit: 20, RateLimitBurst: 40})
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := NewBatchClient(graph, NewRetryPolicy(DefaultMaxRetries, DefaultMaxBackoff))
+
+	if res, err := client.Get(WithBatching(context.Background()), "/beta/groups/a/owners", nil, nil); err != nil {
+		t.Fatal(err)
+	} else {
+		res.Body.Close()
+	}
+
+	var (
+		endpoint = mustParseURL(t, server.URL)
+		b        = graph.(*restClient).limiter.(*adaptiveLimiter).bucket(endpoint)
+	)
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	if b.rate >= 20 {
+		t.Errorf("rate is %v after a throttled batch item; want it slowed down", b.rate)
+	}
+}
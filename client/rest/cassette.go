@@ -0,0 +1,379 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package rest
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+const scrubbed = "REDACTED"
+
+var (
+	ErrNotRecorded = errors.New("no recorded response")
+
+	// Headers that carry credentials and are never written to a cassette
+	scrubbedHeaders = []string{"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie", "X-Identity-Header"}
+
+	// Form fields of token requests that carry credentials
+	scrubbedFormFields = []string{"client_secret", "client_assertion", "refresh_token", "password", "code", "device_code"}
+
+	// Fields of token responses that carry credentials. Access and ID tokens keep their claims but lose their
+	// signature so that replays can still inspect them while the recording can't be used to authenticate.
+	scrubbedJsonFields = []string{"refresh_token", "device_code", "user_code"}
+	unsignedJsonFields = []string{"access_token", "id_token"}
+
+	cassettesMu sync.Mutex
+	recorders   = map[string]*recorder{}
+	cassettes   = map[string]*cassette{}
+)
+
+// WithCassette makes client write every request and response to the cassette at recordPath or, if replayPath is
+// set, answer every request from the cassette at replayPath without touching the network. Clients given the same
+// path share the cassette.
+func WithCassette(client *http.Client, recordPath, replayPath string) error {
+	if recordPath != "" && replayPath != "" {
+		return fmt.Errorf("requests can't be recorded and replayed at the same time")
+	} else if recordPath != "" {
+		if recorder, err := openRecorder(recordPath); err != nil {
+			return err
+		} else {
+			client.Transport = recordingTransport{base: client.Transport, recorder: recorder}
+		}
+	} else if replayPath != "" {
+		if cassette, err := openCassette(replayPath); err != nil {
+			return err
+		} else {
+			client.Transport = cassette
+		}
+	}
+	return nil
+}
+
+// Interaction is a request and the response it received, as stored in a cassette
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+type RecordedRequest struct {
+	Method       string      `json:"method"`
+	Url          string      `json:"url"`
+	Header       http.Header `json:"header,omitempty"`
+	Body         string      `json:"body,omitempty"`
+	BodyEncoding string      `json:"bodyEncoding,omitempty"`
+}
+
+type RecordedResponse struct {
+	StatusCode   int         `json:"statusCode"`
+	Header       http.Header `json:"header,omitempty"`
+	Body         string      `json:"body,omitempty"`
+	BodyEncoding string      `json:"bodyEncoding,omitempty"`
+}
+
+// key identifies the requests a recorded response may answer. Since credentials are scrubbed before the key is
+// computed a replay doesn't need the credentials of the recording.
+func (s RecordedRequest) key() string {
+	if s.Body == "" {
+		return s.Method + " " + s.Url
+	} else {
+		sum := sha256.Sum256([]byte(s.Body))
+		return s.Method + " " + s.Url + " " + hex.EncodeToString(sum[:])
+	}
+}
+
+type recorder struct {
+	mu   sync.Mutex
+	file *os.File
+}
+
+func openRecorder(path string) (*recorder, error) {
+	cassettesMu.Lock()
+	defer cassettesMu.Unlock()
+
+	if existing, ok := recorders[path]; ok {
+		return existing, nil
+	} else if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, err
+	} else if file, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600); err != nil {
+		return nil, err
+	} else {
+		opened := &recorder{file: file}
+		recorders[path] = opened
+		return opened, nil
+	}
+}
+
+func (s *recorder) write(interaction Interaction) error {
+	if data, err := json.Marshal(interaction); err != nil {
+		return err
+	} else {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		_, err := s.file.Write(append(data, '\n'))
+		return err
+	}
+}
+
+type recordingTransport struct {
+	base     http.RoundTripper
+	recorder *recorder
+}
+
+func (s recordingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var reqBody []byte
+	if req.Body != nil {
+		if body, err := io.ReadAll(req.Body); err != nil {
+			return nil, err
+		} else {
+			req.Body.Close()
+			reqBody = body
+			req.Body = io.NopCloser(bytes.NewReader(body))
+		}
+	}
+
+	res, err := s.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	resBody, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	res.Body = io.NopCloser(bytes.NewReader(resBody))
+	if err != nil {
+		return nil, err
+	}
+
+	interaction := Interaction{
+		Request: recordRequest(req, reqBody),
+		Response: RecordedResponse{
+			StatusCode: res.StatusCode,
+			Header:     scrubHeader(res.Header),
+		},
+	}
+	interaction.Response.Body, interaction.Response.BodyEncoding = encodeBody(scrubJson(resBody))
+
+	if err := s.recorder.write(interaction); err != nil {
+		return nil, fmt.Errorf("unable to record %s %s: %w", req.Method, req.URL, err)
+	}
+	return res, nil
+}
+
+func recordRequest(req *http.Request, body []byte) RecordedRequest {
+	recorded := RecordedRequest{
+		Method: req.Method,
+		Url:    req.URL.String(),
+		Header: scrubHeader(req.Header),
+	}
+	if strings.HasPrefix(req.Header.Get("Content-Type"), "application/x-www-form-urlencoded") {
+		body = scrubForm(body)
+	}
+	recorded.Body, recorded.BodyEncoding = encodeBody(body)
+	return recorded
+}
+
+// cassette answers requests with the responses recorded for them, in the order they were recorded. Once a request
+// has used up its responses the last one is repeated.
+type cassette struct {
+	mu           sync.Mutex
+	interactions map[string][]RecordedResponse
+}
+
+func openCassette(path string) (*cassette, error) {
+	cassettesMu.Lock()
+	defer cassettesMu.Unlock()
+
+	if existing, ok := cassettes[path]; ok {
+		return existing, nil
+	} else if file, err := os.Open(path); err != nil {
+		return nil, err
+	} else {
+		defer file.Close()
+
+		loaded := &cassette{interactions: map[string][]RecordedResponse{}}
+		scanner := bufio.NewScanner(file)
+		scanner.Buffer(nil, 256*1024*1024)
+		for line := 1; scanner.Scan(); line++ {
+			var interaction Interaction
+			if err := json.Unmarshal(scanner.Bytes(), &interaction); err != nil {
+				return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+			} else {
+				key := interaction.Request.key()
+				loaded.interactions[key] = append(loaded.interactions[key], interaction.Response)
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+		cassettes[path] = loaded
+		return loaded, nil
+	}
+}
+
+func (s *cassette) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		defer req.Body.Close()
+		if data, err := io.ReadAll(req.Body); err != nil {
+			return nil, err
+		} else {
+			body = data
+		}
+	}
+
+	if recorded, ok := s.next(recordRequest(req, body).key()); !ok {
+		return nil, fmt.Errorf("%w for %s %s", ErrNotRecorded, req.Method, req.URL)
+	} else if data, err := decodeBody(recorded.Body, recorded.BodyEncoding); err != nil {
+		return nil, err
+	} else {
+		// the body may have been scrubbed since its length was recorded
+		header := recorded.Header.Clone()
+		if header == nil {
+			header = http.Header{}
+		}
+		header.Del("Content-Length")
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", recorded.StatusCode, http.StatusText(recorded.StatusCode)),
+			StatusCode:    recorded.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        header,
+			Body:          io.NopCloser(bytes.NewReader(data)),
+			ContentLength: int64(len(data)),
+			Request:       req,
+		}, nil
+	}
+}
+
+func (s *cassette) next(key string) (RecordedResponse, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if responses := s.interactions[key]; len(responses) == 0 {
+		return RecordedResponse{}, false
+	} else {
+		if len(responses) > 1 {
+			s.interactions[key] = responses[1:]
+		}
+		return responses[0], true
+	}
+}
+
+func scrubHeader(header http.Header) http.Header {
+	clone := header.Clone()
+	for _, name := range scrubbedHeaders {
+		if clone.Get(name) != "" {
+			clone.Set(name, scrubbed)
+		}
+	}
+	return clone
+}
+
+func scrubForm(body []byte) []byte {
+	if values, err := url.ParseQuery(string(body)); err != nil {
+		return body
+	} else {
+		changed := false
+		for _, field := range scrubbedFormFields {
+			if values.Has(field) {
+				values.Set(field, scrubbed)
+				changed = true
+			}
+		}
+		if changed {
+			return []byte(values.Encode())
+		} else {
+			return body
+		}
+	}
+}
+
+// scrubJson scrubs the credentials in the top level fields of a token response, leaving any other body untouched
+func scrubJson(body []byte) []byte {
+	var fields map[string]json.RawMessage
+	if !bytes.Contains(body, []byte("_token")) && !bytes.Contains(body, []byte("_code")) {
+		return body
+	} else if err := json.Unmarshal(body, &fields); err != nil {
+		return body
+	} else {
+		changed := false
+		for _, field := range scrubbedJsonFields {
+			if _, ok := fields[field]; ok {
+				fields[field], _ = json.Marshal(scrubbed)
+				changed = true
+			}
+		}
+		for _, field := range unsignedJsonFields {
+			var token string
+			if value, ok := fields[field]; ok && json.Unmarshal(value, &token) == nil {
+				fields[field], _ = json.Marshal(unsigned(token))
+				changed = true
+			}
+		}
+		if !changed {
+			return body
+		} else if data, err := json.Marshal(fields); err != nil {
+			return body
+		} else {
+			return data
+		}
+	}
+}
+
+// unsigned strips the signature of a JWT; anything else is scrubbed entirely
+func unsigned(token string) string {
+	if parts := strings.Split(token, "."); len(parts) != 3 {
+		return scrubbed
+	} else {
+		return parts[0] + "." + parts[1] + "."
+	}
+}
+
+func encodeBody(body []byte) (string, string) {
+	if utf8.Valid(body) {
+		return string(body), ""
+	} else {
+		return base64.StdEncoding.EncodeToString(body), "base64"
+	}
+}
+
+func decodeBody(body, encoding string) ([]byte, error) {
+	switch encoding {
+	case "":
+		return []byte(body), nil
+	case "base64":
+		return base64.StdEncoding.DecodeString(body)
+	default:
+		return nil, fmt.Errorf("unsupported body encoding %q", encoding)
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bloodhoundad/azurehound/client/config"
+)
+
+func TestCassette(t *testing.T) {
+	const accessToken = "eyJhbGciOiJub25lIn0.eyJhdWQiOiJ0ZXN0In0.signature"
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/oauth2/v2.0/token") {
+			json.NewEncoder(w).Encode(map[string]interface{}{"access_token": accessToken, "refresh_token": "r3fresh", "expires_in": 3600})
+		} else if r.Header.Get("Authorization") != "Bearer "+accessToken {
+			w.WriteHeader(http.StatusUnauthorized)
+		} else {
+			w.Header().Set("Set-Cookie", "session=c00kie")
+			json.NewEncoder(w).Encode(map[string]interface{}{"value": []string{r.URL.Query().Get("page")}})
+		}
+	}))
+
+	var (
+		ctx     = context.Background()
+		path    = filepath.Join(t.TempDir(), "recording", "tenant.jsonl")
+		cfg     = config.Config{ApplicationId: "app", ClientSecret: "s3cr3t", Authority: server.URL, Tenant: "tenant"}
+		getPage = func(client RestClient, page string) string {
+			if res, err := client.Get(ctx, "/v1.0/users", map[string]string{"page": page}, nil); err != nil {
+				t.Fatal(err)
+			} else if body, err := io.ReadAll(res.Body); err != nil {
+				t.Fatal(err)
+			} else {
+				res.Body.Close()
+				return string(body)
+			}
+			return ""
+		}
+	)
+
+	cfg.RecordPath = path
+	recorder, err := NewRestClient(server.URL, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, second := getPage(recorder, "1"), getPage(recorder, "2")
+	server.Close()
+
+	if data, err := os.ReadFile(path); err != nil {
+		t.Fatal(err)
+	} else {
+		for _, secret := range []string{"s3cr3t", "c00kie", "signature", "r3fresh"} {
+			if strings.Contains(string(data), secret) {
+				t.Errorf("the recording contains %q", secret)
+			}
+		}
+	}
+
+	// replays neither need the network nor the recorded credentials
+	cfg.RecordPath, cfg.ReplayPath, cfg.ClientSecret = "", path, "another secret"
+	replayer, err := NewRestClient(server.URL, cfg)
+	if err != nil {
+		t.Fatal(err)
+	} else if actual := getPage(replayer, "2"); actual != second {
+		t.Errorf("got %s; want %s", actual, second)
+	} else if actual := getPage(replayer, "1"); actual != first {
+		t.Errorf("got %s; want %s", actual, first)
+	} else if _, err := replayer.Get(ctx, "/v1.0/groups", nil, nil); !errors.Is(err, ErrNotRecorded) {
+		t.Errorf("got %v; want %v", err, ErrNotRecorded)
+	}
+}
@@ -0,0 +1,165 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package rest
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"strings"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/youmark/pkcs8"
+	"software.sslmate.com/src/go-pkcs12"
+)
+
+// certificateCredential is the certificate chain, leaf first, and private key an application signs client assertions with
+type certificateCredential struct {
+	chain []*x509.Certificate
+	key   crypto.Signer
+}
+
+// parseCertificateCredential reads a certificate credential from either a PKCS#12 (.pfx) archive or PEM data. The
+// PEM certificate may bundle its chain and private key, in which case the separate private key may be empty.
+func parseCertificateCredential(certificate, privateKey, password string) (*certificateCredential, error) {
+	var (
+		blocks     []*pem.Block
+		credential certificateCredential
+	)
+
+	if strings.Contains(certificate, "-----BEGIN") {
+		blocks = append(pemBlocks(certificate), pemBlocks(privateKey)...)
+	} else if key, leaf, caCerts, err := pkcs12.DecodeChain([]byte(certificate), password); err != nil {
+		return nil, fmt.Errorf("unable to decode PKCS#12 certificate: %w", err)
+	} else if signer, ok := key.(crypto.Signer); !ok {
+		return nil, fmt.Errorf("unsupported private key type %T", key)
+	} else {
+		credential.chain = append([]*x509.Certificate{leaf}, caCerts...)
+		credential.key = signer
+	}
+
+	for _, block := range blocks {
+		if block.Type == "CERTIFICATE" {
+			if cert, err := x509.ParseCertificate(block.Bytes); err != nil {
+				return nil, fmt.Errorf("unable to parse certificate: %w", err)
+			} else {
+				credential.chain = append(credential.chain, cert)
+			}
+		} else if strings.HasSuffix(block.Type, "PRIVATE KEY") {
+			if key, err := parsePrivateKey(block, password); err != nil {
+				return nil, fmt.Errorf("unable to parse private key: %w", err)
+			} else {
+				credential.key = key
+			}
+		}
+	}
+
+	if credential.key == nil {
+		return nil, fmt.Errorf("no private key found")
+	}
+
+	// move the certificate of the private key to the front of the chain
+	for i, cert := range credential.chain {
+		if publicKey, ok := credential.key.Public().(interface{ Equal(crypto.PublicKey) bool }); ok && publicKey.Equal(cert.PublicKey) {
+			credential.chain[0], credential.chain[i] = credential.chain[i], credential.chain[0]
+			return &credential, nil
+		}
+	}
+	return nil, fmt.Errorf("no certificate matches the private key")
+}
+
+func pemBlocks(data string) []*pem.Block {
+	var (
+		blocks []*pem.Block
+		rest   = []byte(data)
+	)
+	for {
+		if block, remaining := pem.Decode(rest); block == nil {
+			return blocks
+		} else {
+			blocks = append(blocks, block)
+			rest = remaining
+		}
+	}
+}
+
+// parsePrivateKey parses a PKCS#8 key, encrypted or not, as well as PKCS#1 RSA and SEC 1 EC keys. The latter two may
+// also come with legacy OpenSSL PEM encryption.
+func parsePrivateKey(block *pem.Block, password string) (crypto.Signer, error) {
+	der := block.Bytes
+
+	// legacy PEM encryption is weak but still what `openssl rsa -des3` and friends produce
+	if x509.IsEncryptedPEMBlock(block) {
+		if decrypted, err := x509.DecryptPEMBlock(block, []byte(password)); err != nil {
+			return nil, err
+		} else {
+			der = decrypted
+		}
+	}
+
+	if key, _, err := pkcs8.ParsePrivateKey(der, []byte(password)); err == nil {
+		if signer, ok := key.(crypto.Signer); ok {
+			return signer, nil
+		} else {
+			return nil, fmt.Errorf("unsupported private key type %T", key)
+		}
+	} else if key, err := x509.ParsePKCS1PrivateKey(der); err == nil {
+		return key, nil
+	} else if key, err := x509.ParseECPrivateKey(der); err == nil {
+		return key, nil
+	} else {
+		return nil, fmt.Errorf("unsupported private key format")
+	}
+}
+
+func (s certificateCredential) signingMethod() (jwt.SigningMethod, error) {
+	switch key := s.key.(type) {
+	case *rsa.PrivateKey:
+		return jwt.SigningMethodRS256, nil
+	case *ecdsa.PrivateKey:
+		switch key.Curve.Params().BitSize {
+		case 256:
+			return jwt.SigningMethodES256, nil
+		case 384:
+			return jwt.SigningMethodES384, nil
+		case 521:
+			return jwt.SigningMethodES512, nil
+		}
+	}
+	return nil, fmt.Errorf("unsupported private key type %T", s.key)
+}
+
+// x5t returns the base64 encoded SHA-1 thumbprint of the leaf certificate
+func (s certificateCredential) x5t() string {
+	checksum := sha1.Sum(s.chain[0].Raw)
+	return base64.StdEncoding.EncodeToString(checksum[:])
+}
+
+// x5c returns the base64 encoded DER of each certificate in the chain so that subject name and issuer authentication works
+func (s certificateCredential) x5c() []string {
+	x5c := make([]string, len(s.chain))
+	for i, cert := range s.chain {
+		x5c[i] = base64.StdEncoding.EncodeToString(cert.Raw)
+	}
+	return x5c
+}
@@ -0,0 +1,189 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package rest
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/youmark/pkcs8"
+)
+
+// An EC P-256 certificate with its CA certificate, protected with the password "secret"
+const testPFX = `
+	MIIEwgIBAzCCBIgGCSqGSIb3DQEHAaCCBHkEggR1MIIEcTCCA2cGCSqGSIb3DQEHBqCCA1gwggNUAgEAMIIDTQYJKoZIhvcNAQcB
+	MBwGCiqGSIb3DQEMAQMwDgQISRbF17hc180CAggAgIIDIMEq9e2TphLccbi62Av5uELbmCN62H9e01pBWQdZdFrKBWLIcTat1My2
+	BLBkZ4XRs/EM0MBhsljHPI1shmFzKvzqqHDNaBaYYz4fSfEVKY3LlISVQl1Egui50i2HY3Fdt2keafF3qcFWxPeG7fkNk0crMPDN
+	B63l2eI8NgF+8TTF2ShP1nRN/IgQa9TmeA31yaB4Fb3w78vMHaKhcSpFFMo4w+oNbi2qa6DyQeyCLKzCzhoyCrrmgyu8f5IwOeSH
+	CjP0Lvd4/4oXiSboQDnpwSj9OHqsQcMIYJwDTEGiZEk0jH5yyV2YFktN3IFu0Pk/FhFxJ7CRv/vtnacUWXhy5rpxoq208Rn8+VxZ
+	YAD/3BtT2Ji6aeq15Kmt7b68HRDrrPek1vVuMnLjOd1XVzO+o3vSPPvWqc38/jSDLbN1qiay2i2dnE868vKJfEymO4oG4B4KGd5r
+	81qem2/g5P6E/bHnNHaIx2lp6pGswkbyplEPmGvjTwqh7efpJhkK7cYMzj7XLMjsnVInP9PIcCtD5I1wMBiZsKHfzJzI4pjOBLrs
+	UlU/nKAuoMirkHgRzvtRrJ2a59n7VOJ5tWO537gwzlSOp1ulZlu6pW3qDkKgKLiB5Hs29TPZ1h/6J446azxp7uDfKt6wre3p85Dv
+	j85DtvVynNQCgiOAXQs7LS0OdMFaDhdHDO8GIWbZXeG55bhbFnVN8YPcDSDY6Dud07XmbbA9PAhfz9t+eu6LGJ6+P1/aKHgUBPCU
+	ln1wPDhiQ4KociKWZL8mwmWPsPR8ETHwNo8oNJkGfTzeoOYpG7JnYvgQepxOqqeF/RJOExvgRlYQydS4CejA6tiy2hwoU0DapddP
+	sgLMqIW5pX7QP9fii9bTWLMyWb1uuZQQXVbKt9LImKc63sH+DFQ0KHdfyPg/k+EGX5HXR7yysYPPqDzXawCSg/NjXRdzjf08GREf
+	afAZNe5xXUdfSQyr2MxJyk2v5YFvpNV27cUVYGrKV23NTn5nL/UgWZzoH0aMiTNKA/2cFb2UEDGNMpnX/Jj/SobdXfCB0EuYbYVS
+	YUtA3oIhf+KgMIIBAgYJKoZIhvcNAQcBoIH0BIHxMIHuMIHrBgsqhkiG9w0BDAoBAqCBtDCBsTAcBgoqhkiG9w0BDAEDMA4ECHt2
+	X3Qs3vriAgIIAASBkOmhpm7ClBDQaniDFUswgugKOAtLpYqoCOLcjqME65hK0FaTwM7jXKNNIC+d5CYJHlMgzcQsNtpNZf3JMprA
+	N4o4cdn4rB6Y0Ep6VKg3H8/VAXM+4GnJK7vYrsWHm9Yhw+MH/Z2B9L8or7YA5tcHln1Z6gRW+x1t4aVTC0JwEJ02JeXQHHg89Oz3
+	LtiR81IAXzElMCMGCSqGSIb3DQEJFTEWBBR3nR/o8ryDspxefwM1fl15cN4YFzAxMCEwCQYFKw4DAhoFAAQUpGwh/s4tcyaOt/fK
+	yf4ljvmGXnAECLEA7IHbEptYAgIIAA==
+`
+
+// An EC P-256 certificate with its CA certificate, protected with the password "secret" by AES-256 and PBKDF2 as
+// current versions of OpenSSL, Windows and Key Vault export them
+const testModernPFX = `
+	MIIFbAIBAzCCBSIGCSqGSIb3DQEHAaCCBRMEggUPMIIFCzCCA8IGCSqGSIb3DQEHBqCCA7MwggOvAgEAMIIDqAYJKoZIhvcNAQcB
+	MFcGCSqGSIb3DQEFDTBKMCkGCSqGSIb3DQEFDDAcBAiazRcEx5RC3wICCAAwDAYIKoZIhvcNAgkFADAdBglghkgBZQMEASoEEBhu
+	HEihgrnWTFqNpJtYVGqAggNARwlSs32kEoZFYJw8pH/Z8hu0sZpiKBq8DFHalkJShLM0Scxnxlg1ENCOSb33VdMd7KqxQATvcSPf
+	BVP4hyEEVC0GsJ+4AqYYBU1vcPFYgyUA1psm6A6ovt4VDXcl6T6posEdeJ2b0a2RZ+hphVOjjgvOIAccvq/8C39ynQpb9jzq8YMq
+	S3EA1sfrzz2RTbYdaPgZr/nHduzuqH35BCgMoVr2yN+5pjOdmxi/gVLpNhMwR52v0PIKOYuQFpYWbz/2enaGubGJuBApeRiemSUE
+	WDWhj3TbGUN6WxyHcKtnWM64Us9wPw/WO6JO+Qh+x5n+v3v4RJKOVU9gYJIFngEic5leYzsTx7v0l4gXauN0rSj+2XJ9rAN5Hzqo
+	oyHLuv/xFkJGnjQ39sY8MrEx9Sf/oxNp2g9jTTXmjvTV1/kzkX7tpChcYlAoVAq7i158J4vzUzBBcFxjqspTO/kAGTQor+Wm/UoG
+	e+yUDpF9mSIAVYlumND31GUSL/kIKlN5x+cXpu1eLB0N0kq/hXysjbzTs7hGk+h983De2O/JNCPeoNRj20eNEQpVAheI21TyHV11
+	4eCHqCUTwm5PLDdlVZg60ENPgV4eESuRJ8mH47HBkw/Y9mU/W0uwCMBKiYPSSbgW38lsVyiVoig1b0SVw+4a+cL9oJ3ES/7UgsRu
+	sZlZsP20x2B+uIrZwEgYXUcUhDbfv3HC/VttRrC6VCLstMmfs2M5nFT7BlVB+R/rb07u9Nf6GkCwMTNx3wsE9pLfOKKDBq2R3ytR
+	HcDfS514dQLAnWVJ0s+mGX6EE1roRbpkPtst590VuIwQvR083fBfoq8t9P8oRSQ84RXx7Tv5TG14GXNDGueBlHFk4CpfIibICs2G
+	l6qKykNz/1EozFqDAXK69jhIvOmf/siBl4EAZ9V0L5pJIaW/rVlZswjLd5PysD9QWtCqEhePQnC4+fOjaOFjrkceFqw3ARaV124s
+	/Xh8O91/Jvtboc0hJ0matED2Ob7oJuP0pzevlSVw5DlUB/SphdQ7jVesKHAMqkz+Vz8pIdr7i2mIR/bcT4FRFZmf9pJaD5i2OK33
+	3MSMeQZ3PPLdPDVckbnBWwV4fbkVHOafqTCCAUEGCSqGSIb3DQEHAaCCATIEggEuMIIBKjCCASYGCyqGSIb3DQEMCgECoIHvMIHs
+	MFcGCSqGSIb3DQEFDTBKMCkGCSqGSIb3DQEFDDAcBAg0z344yC5TawICCAAwDAYIKoZIhvcNAgkFADAdBglghkgBZQMEASoEENJ7
+	E5N9FZjoOM0pupfj5icEgZA9++hPHSXfhvWGUHCJvJy09rSpEwi6ef88VvCNfogi+3ECBoWEExNpz3vgxpjHoHltety132QVX9s7
+	fwEhYz9Ja1LqnQHOR5xreV5blxcGpUye4K//EHGhTjbHERGO/O7xg2TCwzxtkBS4LzR9qWvmPCFDcUHF8BsnEiTmAt/IYOnisx0G
+	boxMxwiVLs2hSl0xJTAjBgkqhkiG9w0BCRUxFgQUN2Bq9oGOC6lhmNrAQ0ZLTLMijFgwQTAxMA0GCWCGSAFlAwQCAQUABCAOqb7p
+	HrMCXqN+kikNEOYjev5thJUj+CIb2Mw6ktZTxAQIOwmZUaPRaoACAggA
+`
+
+func selfSignedCert(t *testing.T, key crypto.Signer) string {
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "azurehound"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	if der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key); err != nil {
+		t.Fatal(err)
+		return ""
+	} else {
+		return string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+	}
+}
+
+// verifyAssertion checks the assertion is signed by the key of the first certificate in its x5c header
+func verifyAssertion(t *testing.T, assertion string, alg string, chainLength int) {
+	token, err := jwt.Parse(assertion, func(token *jwt.Token) (interface{}, error) {
+		x5c, _ := token.Header["x5c"].([]interface{})
+		if der, err := base64.StdEncoding.DecodeString(x5c[0].(string)); err != nil {
+			return nil, err
+		} else if cert, err := x509.ParseCertificate(der); err != nil {
+			return nil, err
+		} else {
+			return cert.PublicKey, nil
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	} else if token.Header["alg"] != alg {
+		t.Errorf("got alg %v; want %s", token.Header["alg"], alg)
+	} else if x5c := token.Header["x5c"].([]interface{}); len(x5c) != chainLength {
+		t.Errorf("got %d certificates in x5c; want %d", len(x5c), chainLength)
+	} else if token.Header["x5t"] == "" {
+		t.Error("missing x5t header")
+	}
+}
+
+func TestNewClientAssertionPFX(t *testing.T) {
+	if pfx, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(testPFX), "")); err != nil {
+		t.Fatal(err)
+	} else if assertion, err := NewClientAssertion("https://login.microsoftonline.com", "app", string(pfx), "", "secret"); err != nil {
+		t.Fatal(err)
+	} else {
+		verifyAssertion(t, assertion, "ES256", 2)
+	}
+
+	if pfx, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(testPFX), "")); err != nil {
+		t.Fatal(err)
+	} else if _, err := NewClientAssertion("https://login.microsoftonline.com", "app", string(pfx), "", "wrong"); err == nil {
+		t.Error("expected an error for the wrong password")
+	}
+}
+
+func TestNewClientAssertionModernPFX(t *testing.T) {
+	if pfx, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(testModernPFX), "")); err != nil {
+		t.Fatal(err)
+	} else if assertion, err := NewClientAssertion("https://login.microsoftonline.com", "app", string(pfx), "", "secret"); err != nil {
+		t.Fatal(err)
+	} else {
+		verifyAssertion(t, assertion, "ES256", 2)
+	}
+}
+
+func TestNewClientAssertionPEMBundle(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	encrypted, err := pkcs8.MarshalPrivateKey(key, []byte("secret"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bundle := selfSignedCert(t, key) + string(pem.EncodeToMemory(&pem.Block{Type: "ENCRYPTED PRIVATE KEY", Bytes: encrypted}))
+	if assertion, err := NewClientAssertion("https://login.microsoftonline.com", "app", bundle, "", "secret"); err != nil {
+		t.Fatal(err)
+	} else {
+		verifyAssertion(t, assertion, "RS256", 1)
+	}
+}
+
+func TestNewClientAssertionECKey(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pemKey := string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}))
+	if assertion, err := NewClientAssertion("https://login.microsoftonline.com", "app", selfSignedCert(t, key), pemKey, ""); err != nil {
+		t.Fatal(err)
+	} else {
+		verifyAssertion(t, assertion, "ES256", 1)
+	}
+
+	other, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if _, err := NewClientAssertion("https://login.microsoftonline.com", "app", selfSignedCert(t, other), pemKey, ""); err == nil {
+		t.Error("expected an error for a certificate that doesn't match the key")
+	}
+}
@@ -25,25 +25,25 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"math"
 	"net/http"
 	"net/url"
-	"strconv"
 	"sync"
 	"time"
 
 	"github.com/bloodhoundad/azurehound/client/config"
 	"github.com/bloodhoundad/azurehound/constants"
+	"github.com/bloodhoundad/azurehound/enums"
 )
 
 type RestClient interface {
-	Authenticate() error
+	Authenticate(ctx context.Context) error
 	Delete(ctx context.Context, path string, body interface{}, params, headers map[string]string) (*http.Response, error)
 	Get(ctx context.Context, path string, params, headers map[string]string) (*http.Response, error)
 	Patch(ctx context.Context, path string, body interface{}, params, headers map[string]string) (*http.Response, error)
 	Post(ctx context.Context, path string, body interface{}, params, headers map[string]string) (*http.Response, error)
 	Put(ctx context.Context, path string, body interface{}, params, headers map[string]string) (*http.Response, error)
 	Send(req *http.Request) (*http.Response, error)
+	Token(ctx context.Context) (Token, error)
 }
 
 func NewRestClient(apiUrl string, config config.Config) (RestClient, error) {
@@ -53,11 +53,24 @@ func NewRestClient(apiUrl string, config config.Config) (RestClient, error) {
 		return nil, err
 	} else if http, err := NewHTTPClient(config.ProxyUrl); err != nil {
 		return nil, err
+	} else if err := WithCassette(http, config.RecordPath, config.ReplayPath); err != nil {
+		return nil, err
+	} else if tokenCache, err := newTokenCache(config.TokenCache, config.TokenCacheKey); err != nil {
+		return nil, err
+	} else if jwt, err := SelectJWT(apiUrl, config.JWT); err != nil {
+		return nil, err
 	} else {
+		var jwtExpires time.Time
+		if jwt != "" {
+			if jwtExpires, err = ParseExp(jwt); err != nil {
+				return nil, err
+			}
+		}
+
 		client := &restClient{
 			*api,
 			*auth,
-			config.JWT,
+			jwt,
 			config.ApplicationId,
 			config.ClientSecret,
 			config.ClientCert,
@@ -72,32 +85,90 @@ func NewRestClient(apiUrl string, config config.Config) (RestClient, error) {
 			Token{},
 			config.SubscriptionId,
 			config.MgmtGroupId,
+			NewRetryPolicy(config.MaxRetries, config.MaxBackoff),
+			NewRateLimiter(config.RateLimit, config.RateLimitBurst),
+			config.AuthMode,
+			config.IdentityEndpoint,
+			config.IdentityHeader,
+			tokenCache,
+			config.FederatedTokenFile,
+			config.FederatedTokenCommand,
+			jwtExpires,
+			config.MSALCache,
 		}
 		return client, nil
 	}
 }
 
 type restClient struct {
-	api           url.URL
-	authUrl       url.URL
-	jwt           string
-	clientId      string
-	clientSecret  string
-	clientCert    string
-	clientKey     string
-	clientKeyPass string
-	username      string
-	password      string
-	http          *http.Client
-	mutex         sync.RWMutex
-	refreshToken  string
-	tenant        string
-	token         Token
-	subId         []string
-	mgmtGroupId   []string
+	api                   url.URL
+	authUrl               url.URL
+	jwt                   string
+	clientId              string
+	clientSecret          string
+	clientCert            string
+	clientKey             string
+	clientKeyPass         string
+	username              string
+	password              string
+	http                  *http.Client
+	mutex                 sync.RWMutex
+	refreshToken          string
+	tenant                string
+	token                 Token
+	subId                 []string
+	mgmtGroupId           []string
+	retry                 RetryPolicy
+	limiter               RateLimiter
+	authMode              string
+	identityEndpoint      string
+	identityHeader        string
+	tokenCache            *tokenCache
+	federatedTokenFile    string
+	federatedTokenCommand string
+	jwtExpires            time.Time
+	msalCache             string
+}
+
+func (s *restClient) Authenticate(ctx context.Context) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.authenticate(ctx)
+}
+
+// authenticate acquires a new token; callers hold s.mutex so only one request acquires it at a time
+func (s *restClient) authenticate(ctx context.Context) error {
+	switch s.authMode {
+	case "":
+		return s.authenticateWithCredentials(ctx)
+	case enums.ManagedIdentity:
+		if req, err := s.managedIdentityRequest(ctx); err != nil {
+			return err
+		} else {
+			return s.requestToken(req)
+		}
+	case enums.DeviceCode:
+		return s.authenticateWithDeviceCode(ctx)
+	case enums.AzureCLI:
+		if clientId, refreshToken, err := s.msalRefreshToken(); err != nil {
+			return err
+		} else if req, err := s.refreshTokenRequest(ctx, clientId, refreshToken); err != nil {
+			return err
+		} else {
+			return s.requestToken(req)
+		}
+	case enums.WorkloadIdentity:
+		if assertion, err := s.federatedToken(); err != nil {
+			return err
+		} else {
+			return s.authenticateWithAssertion(ctx, assertion)
+		}
+	default:
+		return fmt.Errorf("unsupported auth mode: %s", s.authMode)
+	}
 }
 
-func (s *restClient) Authenticate() error {
+func (s *restClient) authenticateWithCredentials(ctx context.Context) error {
 	var (
 		path         = url.URL{Path: fmt.Sprintf("/%s/oauth2/v2.0/token", s.tenant)}
 		endpoint     = s.authUrl.ResolveReference(&path)
@@ -121,7 +192,7 @@ func (s *restClient) Authenticate() error {
 	} else if s.clientSecret != "" {
 		body.Add("grant_type", "client_credentials")
 		body.Add("client_secret", s.clientSecret)
-	} else if s.clientCert != "" && s.clientKey != "" {
+	} else if s.clientCert != "" {
 		if clientAssertion, err := NewClientAssertion(endpoint.String(), s.clientId, s.clientCert, s.clientKey, s.clientKeyPass); err != nil {
 			return err
 		} else {
@@ -138,14 +209,42 @@ func (s *restClient) Authenticate() error {
 		return fmt.Errorf("unable to authenticate. no valid credential provided")
 	}
 
-	if req, err := NewRequest(context.Background(), "POST", endpoint, body, nil, nil); err != nil {
+	if req, err := NewRequest(ctx, "POST", endpoint, body, nil, nil); err != nil {
 		return err
-	} else if res, err := s.send(req); err != nil {
+	} else {
+		return s.requestToken(req)
+	}
+}
+
+// authenticateWithAssertion exchanges a signed JWT for a token with the client credentials grant
+func (s *restClient) authenticateWithAssertion(ctx context.Context, assertion string) error {
+	var (
+		path         = url.URL{Path: fmt.Sprintf("/%s/oauth2/v2.0/token", s.tenant)}
+		endpoint     = s.authUrl.ResolveReference(&path)
+		defaultScope = url.URL{Path: "/.default"}
+		body         = url.Values{}
+	)
+
+	body.Add("client_id", s.clientId)
+	body.Add("scope", s.api.ResolveReference(&defaultScope).String())
+	body.Add("grant_type", "client_credentials")
+	body.Add("client_assertion_type", "urn:ietf:params:oauth:client-assertion-type:jwt-bearer")
+	body.Add("client_assertion", assertion)
+
+	if req, err := NewRequest(ctx, "POST", endpoint, body, nil, nil); err != nil {
+		return err
+	} else {
+		return s.requestToken(req)
+	}
+}
+
+// requestToken sends a token request and keeps the token from the response for subsequent requests. Callers hold
+// s.mutex.
+func (s *restClient) requestToken(req *http.Request) error {
+	if res, err := s.send(req, false); err != nil {
 		return err
 	} else {
 		defer res.Body.Close()
-		s.mutex.Lock()
-		defer s.mutex.Unlock()
 		if err := json.NewDecoder(res.Body).Decode(&s.token); err != nil {
 			return err
 		} else {
@@ -154,6 +253,26 @@ func (s *restClient) Authenticate() error {
 	}
 }
 
+// refreshTokenRequest builds a request redeeming a refresh token issued to clientId for a token for this client's API
+func (s *restClient) refreshTokenRequest(ctx context.Context, clientId, refreshToken string) (*http.Request, error) {
+	var (
+		path     = url.URL{Path: fmt.Sprintf("/%s/oauth2/v2.0/token", s.tenant)}
+		endpoint = s.authUrl.ResolveReference(&path)
+		body     = url.Values{}
+	)
+	body.Add("grant_type", "refresh_token")
+	body.Add("refresh_token", refreshToken)
+	body.Add("client_id", clientId)
+	body.Add("scope", s.offlineScope())
+	return NewRequest(ctx, http.MethodPost, endpoint, body, nil, nil)
+}
+
+// offlineScope is the default scope of this client's API along with a refresh token
+func (s *restClient) offlineScope() string {
+	defaultScope := url.URL{Path: "/.default"}
+	return s.api.ResolveReference(&defaultScope).String() + " offline_access"
+}
+
 func (s *restClient) Delete(ctx context.Context, path string, body interface{}, params, headers map[string]string) (*http.Response, error) {
 	endpoint := s.api.ResolveReference(&url.URL{Path: path})
 	if req, err := NewRequest(ctx, http.MethodDelete, endpoint, body, params, headers); err != nil {
@@ -200,22 +319,47 @@ func (s *restClient) Put(ctx context.Context, path string, body interface{}, par
 }
 
 func (s *restClient) Send(req *http.Request) (*http.Response, error) {
-	if s.jwt != "" {
+	return s.send(req, true)
+}
+
+// Token returns the token requests are authenticated with, acquiring a new one with ctx once the current one expires
+func (s *restClient) Token(ctx context.Context) (Token, error) {
+	if s.jwt != "" && (s.refreshToken == "" || time.Now().Before(s.jwtExpires)) {
 		if aud, err := ParseAud(s.jwt); err != nil {
-			return nil, err
-		} else if aud != s.api.String() {
-			return nil, fmt.Errorf("invalid audience")
+			return Token{}, err
+		} else if !SameAudience(aud, s.api.String()) {
+			return Token{}, fmt.Errorf("invalid audience")
+		} else if time.Now().After(s.jwtExpires) {
+			return Token{}, fmt.Errorf("%w: the token for %s expired at %s", ErrTokenExpired, aud, s.jwtExpires.Format(time.RFC3339))
+		} else {
+			return Token{accessToken: s.jwt, expires: s.jwtExpires}, nil
 		}
-		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", s.jwt))
+	} else if token := s.currentToken(); !token.IsExpired() {
+		return token, nil
 	} else {
-		if s.token.IsExpired() {
-			if err := s.Authenticate(); err != nil {
-				return nil, err
-			}
+		// an expired JWT is replaced with one minted from the refresh token
+		return s.renewToken(ctx)
+	}
+}
+
+// currentToken returns the last token requested, which another request may be replacing concurrently
+func (s *restClient) currentToken() Token {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	return s.token
+}
+
+// renewToken acquires a new token unless another request renewed it while this one waited its turn
+func (s *restClient) renewToken(ctx context.Context) (Token, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.token.IsExpired() {
+		if err := s.authenticate(ctx); err != nil {
+			return Token{}, err
 		}
-		req.Header.Set("Authorization", s.token.String())
 	}
-	return s.send(req)
+	return s.token, nil
 }
 
 func copyBody(req *http.Request) ([]byte, error) {
@@ -232,60 +376,79 @@ func copyBody(req *http.Request) ([]byte, error) {
 	return body, err
 }
 
-func (s *restClient) send(req *http.Request) (*http.Response, error) {
+// send tries a request until it succeeds or the retry policy gives up on it, authorizing each attempt with the current
+// token unless the request is for a token itself
+func (s *restClient) send(req *http.Request, authorize bool) (*http.Response, error) {
 	// copy the bytes in case we need to retry the request
 	if body, err := copyBody(req); err != nil {
 		return nil, err
 	} else {
-		var (
-			res        *http.Response
-			err        error
-			maxRetries = 3
-		)
-		// Try the request up to a set number of times
-		for retry := 0; retry < maxRetries; retry++ {
+		// Try the request until it succeeds or the retry policy gives up on it
+		for attempt := 1; ; attempt++ {
 
 			// Reusing http.Request requires rewinding the request body
 			// back to a working state
-			if body != nil && retry > 0 {
+			if body != nil && attempt > 1 {
 				req.Body = io.NopCloser(bytes.NewBuffer(body))
 			}
 
-			// Try the request
-			if res, err = s.http.Do(req); err != nil {
-				// client error
+			// Wait for our turn so concurrent collectors don't exceed the API's throttling limits
+			if err := s.limiter.Wait(req.Context(), req); err != nil {
 				return nil, err
-			} else if res.StatusCode < http.StatusOK || res.StatusCode >= http.StatusBadRequest {
-				// Error response code handling
-				// See official Retry guidance (https://learn.microsoft.com/en-us/azure/architecture/best-practices/retry-service-specific#retry-usage-guidance)
-				if res.StatusCode == http.StatusTooManyRequests {
-					retryAfterHeader := res.Header.Get("Retry-After")
-					if retryAfter, err := strconv.ParseInt(retryAfterHeader, 10, 64); err != nil {
-						return nil, fmt.Errorf("attempting to handle 429 but unable to parse retry-after header: %w", err)
-					} else {
-						// Wait the time indicated in the retry-after header
-						time.Sleep(time.Second * time.Duration(retryAfter))
-						continue
-					}
-				} else if res.StatusCode >= http.StatusInternalServerError {
-					// Wait the time calculated by the 5 second exponential backoff
-					backoff := math.Pow(5, float64(retry+1))
-					time.Sleep(time.Second * time.Duration(backoff))
-					continue
+			}
+
+			// The token may have expired while the request waited
+			if authorize {
+				if token, err := s.Token(req.Context()); err != nil {
+					return nil, err
 				} else {
-					// Not a status code that warrants a retry
-					var errRes map[string]interface{}
-					if err := Decode(res.Body, &errRes); err != nil {
-						return nil, fmt.Errorf("malformed error response, status code: %d", res.StatusCode)
-					} else {
-						return nil, fmt.Errorf("%v", errRes)
-					}
+					req.Header.Set("Authorization", token.String())
 				}
-			} else {
+			}
+
+			// Try the request
+			res, err := s.http.Do(req)
+			if res != nil {
+				s.limiter.Observe(req, res)
+			}
+			if err == nil && res.StatusCode >= http.StatusOK && res.StatusCode < http.StatusBadRequest {
 				// Response OK
 				return res, nil
+			} else if delay, retry := s.retry.Retry(attempt, res, err); !retry {
+				if err == nil {
+					err = responseError(res)
+				}
+				if attempt > 1 {
+					return nil, fmt.Errorf("unable to complete the request after %d attempts: %w", attempt, err)
+				} else {
+					return nil, err
+				}
+			} else {
+				if res != nil {
+					io.Copy(io.Discard, res.Body)
+					res.Body.Close()
+				}
+
+				// Wait before trying again unless the request is cancelled in the meantime
+				timer := time.NewTimer(delay)
+				select {
+				case <-req.Context().Done():
+					timer.Stop()
+					return nil, req.Context().Err()
+				case <-timer.C:
+				}
 			}
 		}
-		return nil, fmt.Errorf("unable to complete the request after %d attempts: %w", maxRetries, err)
+	}
+}
+
+// responseError decodes an error response into a ResponseError
+func responseError(res *http.Response) error {
+	defer res.Body.Close()
+	var errRes map[string]interface{}
+	if err := Decode(res.Body, &errRes); err != nil {
+		return ResponseError{StatusCode: res.StatusCode}
+	} else {
+		return ResponseError{StatusCode: res.StatusCode, Body: errRes}
 	}
 }
@@ -0,0 +1,135 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bloodhoundad/azurehound/client/config"
+)
+
+// TestConcurrentTokenRefresh is meant to be run with -race: tokens that expire straight away are refreshed by every
+// request while the others read them
+func TestConcurrentTokenRefresh(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/tenant/oauth2/v2.0/token" {
+			json.NewEncoder(w).Encode(map[string]interface{}{"access_token": "token", "expires_in": 1})
+		} else if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewRestClient(server.URL, config.Config{Authority: server.URL, ApplicationId: "app", ClientSecret: "secret", Tenant: "tenant"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 10; j++ {
+				if res, err := client.Get(context.Background(), "/users", nil, nil); err != nil {
+					t.Error(err)
+				} else {
+					res.Body.Close()
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestSingleTokenRefresh(t *testing.T) {
+	var tokenRequests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/tenant/oauth2/v2.0/token" {
+			atomic.AddInt32(&tokenRequests, 1)
+			json.NewEncoder(w).Encode(map[string]interface{}{"access_token": "token", "expires_in": 3600})
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewRestClient(server.URL, config.Config{Authority: server.URL, ApplicationId: "app", ClientSecret: "secret", Tenant: "tenant"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 25; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if res, err := client.Get(context.Background(), "/users", nil, nil); err != nil {
+				t.Error(err)
+			} else {
+				res.Body.Close()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if tokenRequests != 1 {
+		t.Errorf("got %d token requests, want 1", tokenRequests)
+	}
+}
+
+func TestTokenRenewedAfterRateLimitWait(t *testing.T) {
+	var (
+		mutex  sync.Mutex
+		issued = map[string]time.Time{}
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		if r.URL.Path == "/tenant/oauth2/v2.0/token" {
+			// tokens are renewed 10s before they expire so this one is only used for a second
+			token := fmt.Sprintf("token%d", len(issued))
+			issued[token] = time.Now()
+			json.NewEncoder(w).Encode(map[string]interface{}{"access_token": token, "expires_in": 11})
+		} else if at, ok := issued[strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")]; !ok || time.Since(at) > 1100*time.Millisecond {
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewRestClient(server.URL, config.Config{Authority: server.URL, ApplicationId: "app", ClientSecret: "secret", Tenant: "tenant", RateLimit: 1, RateLimitBurst: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the second request waits a second for the rate limiter, by which time the first token expired
+	for i := 0; i < 2; i++ {
+		if res, err := client.Get(context.Background(), "/users", nil, nil); err != nil {
+			t.Fatal(err)
+		} else {
+			res.Body.Close()
+		}
+	}
+}
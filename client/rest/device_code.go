@@ -0,0 +1,213 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bloodhoundad/azurehound/constants"
+)
+
+const deviceCodeGrantType = "urn:ietf:params:oauth:grant-type:device_code"
+
+// deviceCodePrompt is where the sign in instructions of the device code flow are written
+var deviceCodePrompt io.Writer = os.Stderr
+
+// deviceCodeSession holds the refresh token obtained by a device code sign in. It is shared by every client using the
+// same authority, tenant and application so that the Graph and ARM clients mint their tokens from a single sign in.
+type deviceCodeSession struct {
+	mutex        sync.Mutex
+	refreshToken string
+}
+
+var (
+	deviceCodeSessionsMutex sync.Mutex
+	deviceCodeSessions      = map[string]*deviceCodeSession{}
+)
+
+type deviceCode struct {
+	DeviceCode      string `json:"device_code"`
+	UserCode        string `json:"user_code"`
+	VerificationUri string `json:"verification_uri"`
+	ExpiresIn       int    `json:"expires_in"`
+	Interval        int    `json:"interval"`
+	Message         string `json:"message"`
+}
+
+func (s *restClient) deviceCodeClientId() string {
+	if s.clientId == "" {
+		return constants.AzPowerShellClientID
+	} else {
+		return s.clientId
+	}
+}
+
+func (s *restClient) deviceCodeSessionKey() string {
+	return strings.Join([]string{s.authUrl.String(), s.tenant, s.deviceCodeClientId()}, "|")
+}
+
+func (s *restClient) deviceCodeSession() *deviceCodeSession {
+	deviceCodeSessionsMutex.Lock()
+	defer deviceCodeSessionsMutex.Unlock()
+
+	key := s.deviceCodeSessionKey()
+	if session, ok := deviceCodeSessions[key]; ok {
+		return session
+	} else {
+		session := &deviceCodeSession{}
+		deviceCodeSessions[key] = session
+		return session
+	}
+}
+
+func (s *restClient) authenticateWithDeviceCode(ctx context.Context) error {
+	var (
+		key     = s.deviceCodeSessionKey()
+		session = s.deviceCodeSession()
+	)
+
+	session.mutex.Lock()
+	defer session.mutex.Unlock()
+
+	if session.refreshToken == "" && s.tokenCache != nil {
+		if refreshToken, err := s.tokenCache.read(key); err != nil {
+			return err
+		} else {
+			session.refreshToken = refreshToken
+		}
+	}
+
+	if session.refreshToken != "" {
+		if req, err := s.refreshTokenRequest(ctx, s.deviceCodeClientId(), session.refreshToken); err != nil {
+			return err
+		} else if err := s.requestToken(req); err == nil {
+			return s.keepRefreshToken(session, key)
+		} else if oauthError(err) != "invalid_grant" {
+			return err
+		}
+		// the refresh token expired or was revoked so the user has to sign in again
+	}
+
+	if err := s.deviceCodeSignIn(ctx); err != nil {
+		return err
+	} else {
+		return s.keepRefreshToken(session, key)
+	}
+}
+
+// keepRefreshToken remembers the refresh token that came with the current token, which may have been rotated
+func (s *restClient) keepRefreshToken(session *deviceCodeSession, key string) error {
+	if s.token.refreshToken == "" || s.token.refreshToken == session.refreshToken {
+		return nil
+	} else {
+		session.refreshToken = s.token.refreshToken
+		if s.tokenCache != nil {
+			return s.tokenCache.write(key, session.refreshToken)
+		} else {
+			return nil
+		}
+	}
+}
+
+// deviceCodeSignIn asks the user to sign in on another device and polls the token endpoint until they have or ctx is done
+func (s *restClient) deviceCodeSignIn(ctx context.Context) error {
+	var (
+		codePath  = url.URL{Path: fmt.Sprintf("/%s/oauth2/v2.0/devicecode", s.tenant)}
+		tokenPath = url.URL{Path: fmt.Sprintf("/%s/oauth2/v2.0/token", s.tenant)}
+		body      = url.Values{}
+		code      deviceCode
+	)
+
+	body.Add("client_id", s.deviceCodeClientId())
+	body.Add("scope", s.offlineScope())
+	if req, err := NewRequest(ctx, http.MethodPost, s.authUrl.ResolveReference(&codePath), body, nil, nil); err != nil {
+		return err
+	} else if res, err := s.send(req, false); err != nil {
+		return fmt.Errorf("unable to start device code sign in: %w", err)
+	} else {
+		defer res.Body.Close()
+		if err := json.NewDecoder(res.Body).Decode(&code); err != nil {
+			return err
+		}
+	}
+
+	if code.Message != "" {
+		fmt.Fprintln(deviceCodePrompt, code.Message)
+	} else {
+		fmt.Fprintf(deviceCodePrompt, "To sign in, use a web browser to open the page %s and enter the code %s to authenticate.\n", code.VerificationUri, code.UserCode)
+	}
+
+	var (
+		interval = time.Duration(code.Interval) * time.Second
+		deadline = time.Now().Add(time.Duration(code.ExpiresIn) * time.Second)
+		poll     = url.Values{}
+	)
+
+	// See RFC 8628 (https://www.rfc-editor.org/rfc/rfc8628#section-3.5)
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	poll.Add("grant_type", deviceCodeGrantType)
+	poll.Add("client_id", s.deviceCodeClientId())
+	poll.Add("device_code", code.DeviceCode)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(interval):
+		}
+
+		if time.Now().After(deadline) {
+			return fmt.Errorf("the device code expired before sign in completed")
+		} else if req, err := NewRequest(ctx, http.MethodPost, s.authUrl.ResolveReference(&tokenPath), poll, nil, nil); err != nil {
+			return err
+		} else if err := s.requestToken(req); err == nil {
+			return nil
+		} else {
+			switch oauthError(err) {
+			case "authorization_pending":
+			case "slow_down":
+				interval += 5 * time.Second
+			default:
+				return err
+			}
+		}
+	}
+}
+
+// oauthError returns the OAuth 2.0 error code of a failed token request, if any
+func oauthError(err error) string {
+	var resErr ResponseError
+	if errors.As(err, &resErr) {
+		code, _ := resErr.Body["error"].(string)
+		return code
+	} else {
+		return ""
+	}
+}
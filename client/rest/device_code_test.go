@@ -0,0 +1,180 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package rest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/bloodhoundad/azurehound/client/config"
+	"github.com/bloodhoundad/azurehound/enums"
+)
+
+type fakeAuthority struct {
+	mutex       sync.Mutex
+	deviceCodes int
+	polls       int
+}
+
+func (s *fakeAuthority) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	r.ParseForm()
+	reply := func(status int, body map[string]interface{}) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(body)
+	}
+
+	switch {
+	case r.URL.Path == "/tenant/oauth2/v2.0/devicecode":
+		s.deviceCodes++
+		reply(http.StatusOK, map[string]interface{}{
+			"device_code": "device-code",
+			"user_code":   "USERCODE",
+			"expires_in":  60,
+			"interval":    1,
+			"message":     "enter USERCODE",
+		})
+	case r.Form.Get("grant_type") == deviceCodeGrantType:
+		if s.polls++; s.polls == 1 {
+			reply(http.StatusBadRequest, map[string]interface{}{"error": "authorization_pending"})
+		} else {
+			reply(http.StatusOK, map[string]interface{}{"access_token": r.Form.Get("client_id"), "expires_in": 3600, "refresh_token": "refresh-1"})
+		}
+	case r.Form.Get("grant_type") == "refresh_token" && strings.HasPrefix(r.Form.Get("refresh_token"), "refresh-"):
+		scope := strings.Fields(r.Form.Get("scope"))[0]
+		reply(http.StatusOK, map[string]interface{}{"access_token": scope, "expires_in": 3600, "refresh_token": "refresh-2"})
+	default:
+		reply(http.StatusBadRequest, map[string]interface{}{"error": "invalid_grant"})
+	}
+}
+
+func TestDeviceCode(t *testing.T) {
+	var (
+		authority = &fakeAuthority{}
+		server    = httptest.NewServer(authority)
+		prompt    = &bytes.Buffer{}
+		cfg       = config.Config{
+			AuthMode:      enums.DeviceCode,
+			Authority:     server.URL,
+			Tenant:        "tenant",
+			TokenCache:    filepath.Join(t.TempDir(), "tokens"),
+			TokenCacheKey: "passphrase",
+		}
+	)
+	defer server.Close()
+
+	deviceCodePrompt = prompt
+	defer func() { deviceCodePrompt = os.Stderr }()
+
+	authenticate := func(api string, cfg config.Config) (Token, error) {
+		if client, err := NewRestClient(api, cfg); err != nil {
+			return Token{}, err
+		} else if err := client.Authenticate(context.Background()); err != nil {
+			return Token{}, err
+		} else {
+			return client.(*restClient).token, nil
+		}
+	}
+
+	if token, err := authenticate("https://graph.microsoft.com", cfg); err != nil {
+		t.Fatal(err)
+	} else if token.accessToken != "1950a258-227b-4e31-a9cf-717495945fc2" {
+		t.Errorf("got token %s; want one issued to the Azure PowerShell client", token.accessToken)
+	} else if !strings.Contains(prompt.String(), "USERCODE") {
+		t.Errorf("the sign in prompt was not shown: %q", prompt.String())
+	}
+
+	// the ARM client mints its token from the same sign in
+	if token, err := authenticate("https://management.azure.com", cfg); err != nil {
+		t.Fatal(err)
+	} else if token.accessToken != "https://management.azure.com/.default" {
+		t.Errorf("got token %s; want one for ARM", token.accessToken)
+	}
+
+	// the next run picks up the encrypted refresh token from the cache
+	deviceCodeSessionsMutex.Lock()
+	deviceCodeSessions = map[string]*deviceCodeSession{}
+	deviceCodeSessionsMutex.Unlock()
+	if token, err := authenticate("https://graph.microsoft.com", cfg); err != nil {
+		t.Fatal(err)
+	} else if token.accessToken != "https://graph.microsoft.com/.default" {
+		t.Errorf("got token %s; want one refreshed for Graph", token.accessToken)
+	}
+
+	if authority.deviceCodes != 1 {
+		t.Errorf("signed in %d times; want 1", authority.deviceCodes)
+	}
+
+	cfg.TokenCacheKey = "wrong"
+	deviceCodeSessions = map[string]*deviceCodeSession{}
+	if _, err := authenticate("https://graph.microsoft.com", cfg); err == nil {
+		t.Error("expected an error decrypting the token cache with the wrong key")
+	}
+
+	cfg.TokenCacheKey = ""
+	if _, err := NewRestClient("https://graph.microsoft.com", cfg); err == nil {
+		t.Error("expected an error for a token cache without a key")
+	}
+}
+
+func TestDeviceCodeCancel(t *testing.T) {
+	var (
+		authority = &fakeAuthority{}
+		server    = httptest.NewServer(authority)
+		cfg       = config.Config{
+			AuthMode:  enums.DeviceCode,
+			Authority: server.URL,
+			Tenant:    "tenant",
+		}
+	)
+	defer server.Close()
+
+	deviceCodePrompt = &bytes.Buffer{}
+	defer func() { deviceCodePrompt = os.Stderr }()
+	deviceCodeSessionsMutex.Lock()
+	deviceCodeSessions = map[string]*deviceCodeSession{}
+	deviceCodeSessionsMutex.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	// giving up on the sign in doesn't wait for the next poll
+	start := time.Now()
+	if client, err := NewRestClient("https://graph.microsoft.com", cfg); err != nil {
+		t.Fatal(err)
+	} else if err := client.Authenticate(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("got error %v; want %v", err, context.DeadlineExceeded)
+	} else if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("took %s to give up on the sign in", elapsed)
+	} else if authority.polls != 0 {
+		t.Errorf("polled %d times; want 0", authority.polls)
+	}
+}
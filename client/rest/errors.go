@@ -0,0 +1,42 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package rest
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrTokenExpired is returned when a provided JWT has expired and there is no refresh token to replace it
+var ErrTokenExpired = errors.New("access token expired")
+
+// ResponseError is returned for responses with a status code that doesn't warrant a retry
+type ResponseError struct {
+	StatusCode int
+
+	// The decoded error response; nil when the response body could not be decoded
+	Body map[string]interface{}
+}
+
+func (s ResponseError) Error() string {
+	if s.Body == nil {
+		return fmt.Sprintf("malformed error response, status code: %d", s.StatusCode)
+	} else {
+		return fmt.Sprintf("%v", s.Body)
+	}
+}
@@ -0,0 +1,95 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package rest
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/bloodhoundad/azurehound/client/config"
+)
+
+func testJWT(aud string, exp time.Time) string {
+	body, _ := json.Marshal(map[string]interface{}{"aud": aud, "exp": exp.Unix(), "tid": "tenant"})
+	return "eyJhbGciOiJub25lIn0." + base64.RawURLEncoding.EncodeToString(body) + ".signature"
+}
+
+func TestSelectJWT(t *testing.T) {
+	var (
+		exp   = time.Now().Add(time.Hour).Truncate(time.Second)
+		graph = testJWT("https://graph.microsoft.com", exp)
+		arm   = testJWT("https://management.azure.com/", exp)
+	)
+
+	if jwt, err := SelectJWT("https://graph.microsoft.com", []string{arm, graph}); err != nil || jwt != graph {
+		t.Errorf("got %s, %v; want the Graph token", jwt, err)
+	} else if jwt, err := SelectJWT("https://management.azure.com", []string{arm, graph}); err != nil || jwt != arm {
+		t.Errorf("got %s, %v; want the ARM token", jwt, err)
+	} else if jwt, err := SelectJWT("https://management.azure.com", []string{graph}); err != nil || jwt != "" {
+		t.Errorf("got %s, %v; want no token", jwt, err)
+	} else if _, err := SelectJWT("https://graph.microsoft.com", []string{"garbage"}); err == nil {
+		t.Error("expected an error for a malformed token")
+	}
+
+	if got, err := ParseExp(graph); err != nil {
+		t.Fatal(err)
+	} else if !got.Equal(exp) {
+		t.Errorf("got exp %v; want %v", got, exp)
+	}
+}
+
+func TestExpiredJWT(t *testing.T) {
+	var (
+		requests int
+		server   = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			r.ParseForm()
+			if r.Form.Get("grant_type") == "refresh_token" {
+				json.NewEncoder(w).Encode(map[string]interface{}{"access_token": "refreshed", "expires_in": 3600})
+			} else if r.Header.Get("Authorization") != "Bearer refreshed" {
+				w.WriteHeader(http.StatusUnauthorized)
+			}
+		}))
+		expired = testJWT(server.URL, time.Now().Add(-time.Minute))
+	)
+	defer server.Close()
+
+	// without a refresh token the request fails without being sent
+	if client, err := NewRestClient(server.URL, config.Config{Authority: server.URL, JWT: []string{expired}}); err != nil {
+		t.Fatal(err)
+	} else if _, err := client.Get(context.Background(), "/users", nil, nil); !errors.Is(err, ErrTokenExpired) {
+		t.Errorf("got %v; want %v", err, ErrTokenExpired)
+	} else if requests != 0 {
+		t.Errorf("sent %d requests with an expired token", requests)
+	}
+
+	// with a refresh token the expired token is replaced
+	if client, err := NewRestClient(server.URL, config.Config{Authority: server.URL, JWT: []string{expired}, RefreshToken: "refresh", Tenant: "tenant"}); err != nil {
+		t.Fatal(err)
+	} else if res, err := client.Get(context.Background(), "/users", nil, nil); err != nil {
+		t.Fatal(err)
+	} else {
+		res.Body.Close()
+	}
+}
@@ -0,0 +1,68 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package rest
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+const (
+	// The Azure Instance Metadata Service endpoint available to VMs, scale sets and AKS nodes
+	imdsEndpoint   = "http://169.254.169.254/metadata/identity/oauth2/token"
+	imdsApiVersion = "2018-02-01"
+
+	// The version of the identity endpoint App Service, Functions and Container Apps expose via IDENTITY_ENDPOINT
+	identityEndpointApiVersion = "2019-08-01"
+)
+
+// managedIdentityRequest builds a request for a token for this client's API from the managed identity endpoint.
+// An identity header means the endpoint is an App Service style endpoint; otherwise the IMDS protocol is used, against
+// the real IMDS endpoint unless another one is configured. A configured application id selects a user-assigned identity.
+func (s *restClient) managedIdentityRequest(ctx context.Context) (*http.Request, error) {
+	var (
+		endpoint = imdsEndpoint
+		params   = map[string]string{
+			"resource": s.api.String(),
+		}
+		headers = map[string]string{}
+	)
+
+	if s.identityEndpoint != "" {
+		endpoint = s.identityEndpoint
+	}
+
+	if s.identityHeader != "" {
+		params["api-version"] = identityEndpointApiVersion
+		headers["X-IDENTITY-HEADER"] = s.identityHeader
+	} else {
+		params["api-version"] = imdsApiVersion
+		headers["Metadata"] = "true"
+	}
+
+	if s.clientId != "" {
+		params["client_id"] = s.clientId
+	}
+
+	if endpoint, err := url.Parse(endpoint); err != nil {
+		return nil, err
+	} else {
+		return NewRequest(ctx, http.MethodGet, endpoint, nil, params, headers)
+	}
+}
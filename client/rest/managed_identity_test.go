@@ -0,0 +1,103 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package rest
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/bloodhoundad/azurehound/client/config"
+	"github.com/bloodhoundad/azurehound/enums"
+)
+
+func TestManagedIdentityIMDS(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if r.Header.Get("Metadata") != "true" {
+			w.WriteHeader(http.StatusBadRequest)
+		} else if query.Get("api-version") != imdsApiVersion || query.Get("client_id") != "user-assigned" {
+			w.WriteHeader(http.StatusBadRequest)
+		} else {
+			fmt.Fprintf(w, `{"access_token":"%s","expires_in":"3599","token_type":"Bearer"}`, query.Get("resource"))
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewRestClient("https://graph.microsoft.com", config.Config{
+		ApplicationId:    "user-assigned",
+		AuthMode:         enums.ManagedIdentity,
+		IdentityEndpoint: server.URL,
+	})
+	if err != nil {
+		t.Fatal(err)
+	} else if err := client.Authenticate(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	token := client.(*restClient).token
+	if token.String() != "Bearer https://graph.microsoft.com" {
+		t.Errorf("got token %s for the wrong resource", token)
+	} else if token.IsExpired() || token.expires.After(time.Now().Add(time.Hour)) {
+		t.Errorf("got token expiring at %v", token.expires)
+	}
+}
+
+func TestManagedIdentityEndpoint(t *testing.T) {
+	expiresOn := time.Now().Add(time.Hour).Unix()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if r.Header.Get("X-IDENTITY-HEADER") != "secret" || query.Get("api-version") != identityEndpointApiVersion {
+			w.WriteHeader(http.StatusUnauthorized)
+		} else if query.Has("client_id") {
+			w.WriteHeader(http.StatusBadRequest)
+		} else {
+			fmt.Fprintf(w, `{"access_token":"%s","expires_on":"%d","token_type":"Bearer"}`, query.Get("resource"), expiresOn)
+		}
+	}))
+	defer server.Close()
+
+	client, err := NewRestClient("https://management.azure.com", config.Config{
+		AuthMode:         enums.ManagedIdentity,
+		IdentityEndpoint: server.URL,
+		IdentityHeader:   "secret",
+	})
+	if err != nil {
+		t.Fatal(err)
+	} else if err := client.Authenticate(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	token := client.(*restClient).token
+	if token.String() != "Bearer https://management.azure.com" {
+		t.Errorf("got token %s for the wrong resource", token)
+	} else if token.expires.Unix() != expiresOn {
+		t.Errorf("got token expiring at %v; want %v", token.expires, time.Unix(expiresOn, 0))
+	}
+}
+
+func TestUnsupportedAuthMode(t *testing.T) {
+	if client, err := NewRestClient("https://graph.microsoft.com", config.Config{AuthMode: "telepathy"}); err != nil {
+		t.Fatal(err)
+	} else if err := client.Authenticate(context.Background()); err == nil {
+		t.Error("expected an error for an unsupported auth mode")
+	}
+}
@@ -9,6 +9,7 @@ import (
 	http "net/http"
 	reflect "reflect"
 
+	rest "github.com/bloodhoundad/azurehound/client/rest"
 	gomock "github.com/golang/mock/gomock"
 )
 
@@ -36,17 +37,17 @@ func (m *MockRestClient) EXPECT() *MockRestClientMockRecorder {
 }
 
 // Authenticate mocks base method.
-func (m *MockRestClient) Authenticate() error {
+func (m *MockRestClient) Authenticate(arg0 context.Context) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Authenticate")
+	ret := m.ctrl.Call(m, "Authenticate", arg0)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Authenticate indicates an expected call of Authenticate.
-func (mr *MockRestClientMockRecorder) Authenticate() *gomock.Call {
+func (mr *MockRestClientMockRecorder) Authenticate(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authenticate", reflect.TypeOf((*MockRestClient)(nil).Authenticate))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Authenticate", reflect.TypeOf((*MockRestClient)(nil).Authenticate), arg0)
 }
 
 // Delete mocks base method.
@@ -138,3 +139,18 @@ func (mr *MockRestClientMockRecorder) Send(arg0 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Send", reflect.TypeOf((*MockRestClient)(nil).Send), arg0)
 }
+
+// Token mocks base method.
+func (m *MockRestClient) Token(arg0 context.Context) (rest.Token, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Token", arg0)
+	ret0, _ := ret[0].(rest.Token)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Token indicates an expected call of Token.
+func (mr *MockRestClientMockRecorder) Token(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Token", reflect.TypeOf((*MockRestClient)(nil).Token), arg0)
+}
@@ -0,0 +1,127 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package rest
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gofrs/uuid"
+)
+
+// The application id of the Azure CLI, whose refresh tokens are preferred when the cache holds several for an account
+const azureCLIClientId = "04b07795-8ddb-461a-bbee-02f9e1bf7b46"
+
+// msalCache is the subset of the MSAL token cache schema shared by the Azure CLI and other MSAL based tools
+type msalCache struct {
+	Account      map[string]msalAccount    `json:"Account"`
+	RefreshToken map[string]msalCredential `json:"RefreshToken"`
+}
+
+type msalAccount struct {
+	HomeAccountId string `json:"home_account_id"`
+	Environment   string `json:"environment"`
+	Realm         string `json:"realm"`
+	Username      string `json:"username"`
+}
+
+type msalCredential struct {
+	HomeAccountId string `json:"home_account_id"`
+	Environment   string `json:"environment"`
+	ClientId      string `json:"client_id"`
+	FamilyId      string `json:"family_id"`
+	Secret        string `json:"secret"`
+}
+
+// DefaultMSALCachePath returns where the Azure CLI keeps its token cache
+func DefaultMSALCachePath() string {
+	if dir := os.Getenv("AZURE_CONFIG_DIR"); dir != "" {
+		return filepath.Join(dir, "msal_token_cache.json")
+	} else if home, err := os.UserHomeDir(); err != nil {
+		return ""
+	} else {
+		return filepath.Join(home, ".azure", "msal_token_cache.json")
+	}
+}
+
+// msalRefreshToken finds the refresh token of the account signed in to tenant, or the only account in the cache when
+// tenant is a domain name no account is signed in to. The cache is only read, never written.
+func (s *restClient) msalRefreshToken() (string, string, error) {
+	var (
+		cache      msalCache
+		path       = s.msalCache
+		candidates = map[string]msalAccount{}
+		others     = map[string]msalAccount{}
+	)
+
+	// an account's realm is the ID of its tenant, so a tenant given by ID never matches an account of another one
+	_, err := uuid.FromString(s.tenant)
+	byDomain := err != nil
+
+	if path == "" {
+		path = DefaultMSALCachePath()
+	}
+
+	if data, err := os.ReadFile(path); err != nil {
+		return "", "", fmt.Errorf("unable to read MSAL token cache, sign in with `az login` first: %w", err)
+	} else if err := json.Unmarshal(data, &cache); err != nil {
+		return "", "", fmt.Errorf("unable to parse MSAL token cache %s: %w", path, err)
+	}
+
+	for _, account := range cache.Account {
+		if !strings.EqualFold(account.Environment, s.authUrl.Host) {
+			continue
+		} else if s.username != "" && !strings.EqualFold(account.Username, s.username) {
+			continue
+		} else if strings.EqualFold(account.Realm, s.tenant) {
+			candidates[account.HomeAccountId] = account
+		} else if byDomain {
+			others[account.HomeAccountId] = account
+		}
+	}
+	if len(candidates) == 0 {
+		candidates = others
+	}
+
+	if len(candidates) == 0 {
+		return "", "", fmt.Errorf("no account for tenant %s in MSAL token cache %s", s.tenant, path)
+	} else if len(candidates) > 1 {
+		return "", "", fmt.Errorf("more than one account in MSAL token cache %s matches tenant %s, select one with --username", path, s.tenant)
+	}
+
+	var refreshToken *msalCredential
+	for _, account := range candidates {
+		for key := range cache.RefreshToken {
+			credential := cache.RefreshToken[key]
+			if credential.HomeAccountId != account.HomeAccountId || !strings.EqualFold(credential.Environment, account.Environment) {
+				continue
+			} else if refreshToken == nil || credential.ClientId == azureCLIClientId {
+				refreshToken = &credential
+			}
+		}
+	}
+
+	if refreshToken == nil {
+		return "", "", fmt.Errorf("no refresh token for tenant %s in MSAL token cache %s, sign in again with `az login`", s.tenant, path)
+	} else {
+		return refreshToken.ClientId, refreshToken.Secret, nil
+	}
+}
@@ -0,0 +1,127 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/bloodhoundad/azurehound/client/config"
+	"github.com/bloodhoundad/azurehound/enums"
+)
+
+func TestAzureCLI(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if !strings.HasSuffix(r.URL.Path, "/oauth2/v2.0/token") || r.Form.Get("grant_type") != "refresh_token" {
+			w.WriteHeader(http.StatusBadRequest)
+		} else {
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"access_token": r.Form.Get("client_id") + "|" + r.Form.Get("refresh_token"),
+				"expires_in":   3600,
+			})
+		}
+	}))
+	defer server.Close()
+
+	var (
+		host  = mustParseURL(t, server.URL).Host
+		path  = filepath.Join(t.TempDir(), "msal_token_cache.json")
+		cache = map[string]interface{}{
+			"Account": map[string]interface{}{
+				"a": map[string]string{"home_account_id": "alice.home", "environment": host, "realm": "tenant-a", "username": "alice@contoso.com"},
+				"b": map[string]string{"home_account_id": "bob.home", "environment": host, "realm": "tenant-b", "username": "bob@contoso.com"},
+				"c": map[string]string{"home_account_id": "carol.home", "environment": host, "realm": "tenant-b", "username": "carol@contoso.com"},
+			},
+			"RefreshToken": map[string]interface{}{
+				"1": map[string]string{"home_account_id": "alice.home", "environment": host, "client_id": "other", "family_id": "1", "secret": "alice-other"},
+				"2": map[string]string{"home_account_id": "alice.home", "environment": host, "client_id": azureCLIClientId, "family_id": "1", "secret": "alice-cli"},
+				"3": map[string]string{"home_account_id": "bob.home", "environment": host, "client_id": azureCLIClientId, "family_id": "1", "secret": "bob-cli"},
+				"4": map[string]string{"home_account_id": "carol.home", "environment": host, "client_id": azureCLIClientId, "family_id": "1", "secret": "carol-cli"},
+			},
+		}
+		authenticate = func(cfg config.Config) (string, error) {
+			if client, err := NewRestClient("https://graph.microsoft.com", cfg); err != nil {
+				return "", err
+			} else if err := client.Authenticate(context.Background()); err != nil {
+				return "", err
+			} else {
+				return client.(*restClient).token.accessToken, nil
+			}
+		}
+	)
+
+	if data, err := json.Marshal(cache); err != nil {
+		t.Fatal(err)
+	} else if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := config.Config{AuthMode: enums.AzureCLI, Authority: server.URL, MSALCache: path, Tenant: "tenant-a"}
+	if token, err := authenticate(cfg); err != nil {
+		t.Fatal(err)
+	} else if token != azureCLIClientId+"|alice-cli" {
+		t.Errorf("got token %s; want one refreshed with alice's Azure CLI refresh token", token)
+	}
+
+	// with several accounts signed in to the tenant the username has to pick one
+	cfg.Tenant = "tenant-b"
+	if _, err := authenticate(cfg); err == nil {
+		t.Error("expected an error for an ambiguous account")
+	}
+	cfg.Username = "carol@contoso.com"
+	if token, err := authenticate(cfg); err != nil {
+		t.Fatal(err)
+	} else if token != azureCLIClientId+"|carol-cli" {
+		t.Errorf("got token %s; want one refreshed with carol's Azure CLI refresh token", token)
+	}
+	cfg.Username = ""
+
+	// a domain name matches every account so the username has to pick one
+	cfg.Tenant = "contoso.onmicrosoft.com"
+	if _, err := authenticate(cfg); err == nil {
+		t.Error("expected an error for an ambiguous account")
+	}
+
+	// a tenant ID only matches accounts signed in to that tenant
+	cfg.Tenant, cfg.Username = "00000000-0000-0000-0000-000000000001", "alice@contoso.com"
+	if _, err := authenticate(cfg); err == nil {
+		t.Error("expected an error for a tenant without a signed in account")
+	}
+
+	cfg.MSALCache = filepath.Join(t.TempDir(), "missing.json")
+	if _, err := authenticate(cfg); err == nil {
+		t.Error("expected an error for a missing cache")
+	}
+}
+
+func mustParseURL(t *testing.T, raw string) *url.URL {
+	if u, err := url.Parse(raw); err != nil {
+		t.Fatal(err)
+		return nil
+	} else {
+		return u
+	}
+}
@@ -0,0 +1,251 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package rest
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// When fewer requests than this remain in an ARM throttling bucket the request rate is scaled down in proportion
+	rateLimitLowWater = 100
+
+	// The rate a throttled bucket never drops below so that collection keeps making progress
+	minRequestRate = 0.5
+
+	rateLimitRemainingPrefix = "X-Ms-Ratelimit-Remaining-"
+
+	// How long an unlimited bucket measures the rate it sends at before starting over
+	sendRateWindow = 10 * time.Second
+)
+
+// RateLimiter paces requests so that every collector sharing a RestClient stays within the API's throttling limits
+type RateLimiter interface {
+	// Wait blocks until the request may be sent or the context is done
+	Wait(ctx context.Context, req *http.Request) error
+
+	// Observe adjusts the pace of future requests based on the response to a request
+	Observe(req *http.Request, res *http.Response)
+}
+
+// NewRateLimiter returns a RateLimiter that keeps a token bucket for each host and ARM resource provider, allowing at
+// most limit requests per second with bursts of up to burst requests. With a limit of zero or less requests are sent
+// without delay until the API pushes back, after which they're limited to the rate they were sent at until it stops.
+func NewRateLimiter(limit int, burst int) RateLimiter {
+	fixedLimit := float64(limit)
+	if limit <= 0 {
+		fixedLimit = math.Inf(1)
+	}
+	if burst < 1 {
+		burst = 1
+	}
+	return &adaptiveLimiter{
+		limit:   fixedLimit,
+		burst:   float64(burst),
+		buckets: make(map[string]*bucket),
+	}
+}
+
+type unlimited struct{}
+
+func (s unlimited) Wait(ctx context.Context, req *http.Request) error { return nil }
+
+func (s unlimited) Observe(req *http.Request, res *http.Response) {}
+
+type adaptiveLimiter struct {
+	limit   float64
+	burst   float64
+	mutex   sync.Mutex
+	buckets map[string]*bucket
+}
+
+func (s *adaptiveLimiter) bucket(u *url.URL) *bucket {
+	key := rateLimitKey(u)
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if b, ok := s.buckets[key]; ok {
+		return b
+	} else {
+		b := &bucket{
+			limit:     s.limit,
+			rate:      s.limit,
+			burst:     s.burst,
+			tokens:    s.burst,
+			last:      time.Now(),
+			unlimited: math.IsInf(s.limit, 1),
+			since:     time.Now(),
+		}
+		s.buckets[key] = b
+		return b
+	}
+}
+
+func (s *adaptiveLimiter) Wait(ctx context.Context, req *http.Request) error {
+	b := s.bucket(req.URL)
+	if delay := b.reserve(time.Now()); delay <= 0 {
+		return nil
+	} else {
+		timer := time.NewTimer(delay)
+		defer timer.Stop()
+		select {
+		case <-ctx.Done():
+			b.cancel()
+			return ctx.Err()
+		case <-timer.C:
+			return nil
+		}
+	}
+}
+
+func (s *adaptiveLimiter) Observe(req *http.Request, res *http.Response) {
+	b := s.bucket(req.URL)
+	if res.StatusCode == http.StatusTooManyRequests {
+		b.throttled(time.Now())
+	} else if remaining, ok := rateLimitRemaining(res.Header); ok && remaining < rateLimitLowWater {
+		b.slowDown(float64(remaining) / rateLimitLowWater)
+	} else {
+		b.speedUp()
+	}
+}
+
+// bucket is a token bucket whose refill rate moves between minRequestRate and its configured limit. An unlimited bucket
+// has no limit until the API pushes back, when the rate it was sending at becomes its limit until it recovers.
+type bucket struct {
+	mutex  sync.Mutex
+	limit  float64
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+
+	unlimited bool
+	sent      float64
+	since     time.Time
+}
+
+func (s *bucket) refill(now time.Time) {
+	if elapsed := now.Sub(s.last).Seconds(); elapsed > 0 {
+		s.tokens = math.Min(s.burst, s.tokens+elapsed*s.rate)
+		s.last = now
+	}
+}
+
+// reserve takes a token from the bucket and returns how long the caller has to wait before the token is available
+func (s *bucket) reserve(now time.Time) time.Duration {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if math.IsInf(s.rate, 1) {
+		if now.Sub(s.since) > sendRateWindow {
+			s.sent, s.since = 0, now
+		}
+		s.sent++
+		return 0
+	}
+	s.refill(now)
+	s.tokens--
+	if s.tokens >= 0 {
+		return 0
+	} else {
+		return time.Duration(-s.tokens / s.rate * float64(time.Second))
+	}
+}
+
+// cancel returns a token reserved by a request that will not be sent
+func (s *bucket) cancel() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.tokens = math.Min(s.burst, s.tokens+1)
+}
+
+// throttled halves the rate and empties the bucket so that requests already queued are spread out as well
+func (s *bucket) throttled(now time.Time) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.limitToSendRate(now)
+	s.refill(now)
+	s.rate = math.Max(minRequestRate, s.rate/2)
+	s.tokens = math.Min(0, s.tokens)
+}
+
+// slowDown lowers the rate to the given fraction of the limit if it's currently faster than that
+func (s *bucket) slowDown(fraction float64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.limitToSendRate(time.Now())
+	s.refill(time.Now())
+	s.rate = math.Max(minRequestRate, math.Min(s.rate, s.limit*fraction))
+}
+
+// speedUp recovers the rate a step at a time after the API stops pushing back
+func (s *bucket) speedUp() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.rate < s.limit {
+		s.refill(time.Now())
+		s.rate = math.Min(s.limit, s.rate+s.limit/20)
+	} else if s.unlimited && !math.IsInf(s.limit, 1) {
+		s.limit, s.rate, s.sent, s.since = math.Inf(1), math.Inf(1), 0, time.Now()
+	}
+}
+
+// limitToSendRate gives an unlimited bucket the rate it has been sending at as its limit
+func (s *bucket) limitToSendRate(now time.Time) {
+	if math.IsInf(s.limit, 1) {
+		s.limit = math.Max(minRequestRate, s.sent/math.Max(1, now.Sub(s.since).Seconds()))
+		s.rate, s.tokens, s.last = s.limit, math.Min(s.tokens, s.burst), now
+	}
+}
+
+// rateLimitKey groups requests that share a throttling limit: Azure Resource Manager throttles each resource provider
+// separately so ARM requests are keyed by host and the provider that serves them, anything else just by host
+func rateLimitKey(u *url.URL) string {
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+	for i := len(segments) - 2; i >= 0; i-- {
+		if strings.EqualFold(segments[i], "providers") {
+			return u.Host + "/" + strings.ToLower(segments[i+1])
+		}
+	}
+	return u.Host
+}
+
+// rateLimitRemaining returns the lowest of the x-ms-ratelimit-remaining-* header values ARM attaches to its responses
+func rateLimitRemaining(header http.Header) (int, bool) {
+	var (
+		remaining = math.MaxInt32
+		found     = false
+	)
+	for key, values := range header {
+		if strings.HasPrefix(http.CanonicalHeaderKey(key), rateLimitRemainingPrefix) {
+			for _, value := range values {
+				if n, err := strconv.Atoi(value); err == nil && n < remaining {
+					remaining = n
+					found = true
+				}
+			}
+		}
+	}
+	return remaining, found
+}
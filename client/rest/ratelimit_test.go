@@ -0,0 +1,168 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package rest
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+)
+
+func TestRateLimitKey(t *testing.T) {
+	tests := map[string]string{
+		"https://graph.microsoft.com/v1.0/users":                                                                                                        "graph.microsoft.com",
+		"https://management.azure.com/subscriptions/1/resourcegroups":                                                                                   "management.azure.com",
+		"https://management.azure.com/subscriptions/1/providers/Microsoft.KeyVault/vaults":                                                              "management.azure.com/microsoft.keyvault",
+		"https://management.azure.com/subscriptions/1/providers/Microsoft.Compute/virtualMachines/vm/providers/Microsoft.Authorization/roleAssignments": "management.azure.com/microsoft.authorization",
+	}
+
+	for raw, want := range tests {
+		if u, err := url.Parse(raw); err != nil {
+			t.Fatal(err)
+		} else if got := rateLimitKey(u); got != want {
+			t.Errorf("rateLimitKey(%s) = %s; want %s", raw, got, want)
+		}
+	}
+}
+
+func TestRateLimitRemaining(t *testing.T) {
+	header := http.Header{}
+	if _, ok := rateLimitRemaining(header); ok {
+		t.Error("found a remaining count without headers")
+	}
+
+	header.Set("x-ms-ratelimit-remaining-subscription-reads", "11999")
+	header.Set("x-ms-ratelimit-remaining-tenant-reads", "42")
+	header.Set("x-ms-ratelimit-remaining-resource", "Microsoft.Compute/LowCostGet3Min;3996")
+	if remaining, ok := rateLimitRemaining(header); !ok || remaining != 42 {
+		t.Errorf("got %d, %v; want 42, true", remaining, ok)
+	}
+}
+
+func TestRateLimiterWait(t *testing.T) {
+	var (
+		limiter = NewRateLimiter(100, 2)
+		req, _  = http.NewRequest(http.MethodGet, "https://graph.microsoft.com/v1.0/users", nil)
+		start   = time.Now()
+	)
+
+	for i := 0; i < 4; i++ {
+		if err := limiter.Wait(context.Background(), req); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// the burst covers two requests, the remaining two wait 10ms each
+	if elapsed := time.Since(start); elapsed < 15*time.Millisecond {
+		t.Errorf("four requests took %v; want at least 15ms", elapsed)
+	}
+
+	// a different resource provider has its own bucket
+	other, _ := http.NewRequest(http.MethodGet, "https://management.azure.com/subscriptions/1/providers/Microsoft.KeyVault/vaults", nil)
+	start = time.Now()
+	if err := limiter.Wait(context.Background(), other); err != nil {
+		t.Fatal(err)
+	} else if elapsed := time.Since(start); elapsed > 5*time.Millisecond {
+		t.Errorf("request to another provider waited %v", elapsed)
+	}
+}
+
+func TestRateLimiterCancel(t *testing.T) {
+	var (
+		limiter     = NewRateLimiter(1, 1)
+		req, _      = http.NewRequest(http.MethodGet, "https://graph.microsoft.com/v1.0/users", nil)
+		ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	)
+	defer cancel()
+
+	if err := limiter.Wait(ctx, req); err != nil {
+		t.Fatal(err)
+	} else if err := limiter.Wait(ctx, req); err != context.DeadlineExceeded {
+		t.Errorf("got %v; want %v", err, context.DeadlineExceeded)
+	}
+}
+
+func TestRateLimiterAdapts(t *testing.T) {
+	var (
+		limiter = NewRateLimiter(20, 40).(*adaptiveLimiter)
+		req, _  = http.NewRequest(http.MethodGet, "https://management.azure.com/subscriptions/1/resourcegroups", nil)
+		b       = limiter.bucket(req.URL)
+		rate    = func() float64 {
+			b.mutex.Lock()
+			defer b.mutex.Unlock()
+			return b.rate
+		}
+	)
+
+	limiter.Observe(req, &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{}})
+	if got := rate(); got != 10 {
+		t.Errorf("rate after throttling is %v; want 10", got)
+	}
+
+	limiter.Observe(req, &http.Response{StatusCode: http.StatusOK, Header: http.Header{"X-Ms-Ratelimit-Remaining-Subscription-Reads": {"10"}}})
+	if got := rate(); got != 2 {
+		t.Errorf("rate with 10 requests remaining is %v; want 2", got)
+	}
+
+	for i := 0; i < 100; i++ {
+		limiter.Observe(req, &http.Response{StatusCode: http.StatusOK, Header: http.Header{}})
+	}
+	if got := rate(); got != 20 {
+		t.Errorf("rate after recovering is %v; want 20", got)
+	}
+}
+
+func TestRateLimiterUnlimited(t *testing.T) {
+	var (
+		limiter = NewRateLimiter(0, 40).(*adaptiveLimiter)
+		req, _  = http.NewRequest(http.MethodGet, "https://graph.microsoft.com/v1.0/users", nil)
+		b       = limiter.bucket(req.URL)
+		rate    = func() float64 {
+			b.mutex.Lock()
+			defer b.mutex.Unlock()
+			return b.rate
+		}
+	)
+
+	// requests aren't delayed until the API pushes back
+	start := time.Now()
+	for i := 0; i < 10; i++ {
+		if err := limiter.Wait(context.Background(), req); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Errorf("sending 10 requests took %v; want no delay", elapsed)
+	}
+
+	// then they're limited to half the rate they were sent at
+	limiter.Observe(req, &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{}})
+	if got := rate(); got != 5 {
+		t.Errorf("rate after throttling is %v; want 5", got)
+	}
+
+	for i := 0; i < 100; i++ {
+		limiter.Observe(req, &http.Response{StatusCode: http.StatusOK, Header: http.Header{}})
+	}
+	if got := rate(); !math.IsInf(got, 1) {
+		t.Errorf("rate after recovering is %v; want no limit", got)
+	}
+}
@@ -0,0 +1,137 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package rest
+
+import (
+	"errors"
+	"io"
+	"math"
+	"math/rand"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+const (
+	DefaultMaxRetries = 2
+	DefaultMaxBackoff = 2 * time.Minute
+
+	// The delay before the first retry; every retry after it doubles the delay
+	baseBackoff = 2 * time.Second
+)
+
+// RetryPolicy decides whether a request that failed, either with a transport error or an error response, is attempted
+// again and how long to wait before doing so
+type RetryPolicy interface {
+	// Retry returns the delay before the next attempt of a request, or false when the request should not be retried.
+	// attempt is the number of attempts made so far; exactly one of res and err is non-nil.
+	Retry(attempt int, res *http.Response, err error) (time.Duration, bool)
+}
+
+// NewRetryPolicy returns a RetryPolicy that retries a request up to maxRetries times with jittered exponential backoff
+// of at most maxBackoff, unless the response says how long to wait
+func NewRetryPolicy(maxRetries int, maxBackoff time.Duration) RetryPolicy {
+	if maxRetries < 0 {
+		maxRetries = 0
+	}
+	if maxBackoff <= 0 {
+		maxBackoff = DefaultMaxBackoff
+	}
+	return exponentialBackoff{
+		maxRetries: maxRetries,
+		maxBackoff: maxBackoff,
+	}
+}
+
+// exponentialBackoff retries throttled requests, server errors and transient network errors.
+// See official Retry guidance (https://learn.microsoft.com/en-us/azure/architecture/best-practices/retry-service-specific#retry-usage-guidance)
+type exponentialBackoff struct {
+	maxRetries int
+	maxBackoff time.Duration
+}
+
+func (s exponentialBackoff) Retry(attempt int, res *http.Response, err error) (time.Duration, bool) {
+	if attempt > s.maxRetries {
+		return 0, false
+	} else if err != nil {
+		return s.backoff(attempt), isTransient(err)
+	} else if res.StatusCode != http.StatusTooManyRequests && res.StatusCode < http.StatusInternalServerError {
+		// Not a status code that warrants a retry
+		return 0, false
+	} else if retryAfter, ok := RetryAfter(res.Header); ok {
+		// Wait the time indicated by the server
+		return s.limit(retryAfter), true
+	} else {
+		return s.backoff(attempt), true
+	}
+}
+
+// backoff returns a random delay between half and all of the exponential backoff for the attempt so concurrent
+// requests that failed together don't retry together
+func (s exponentialBackoff) backoff(attempt int) time.Duration {
+	backoff := s.limit(time.Duration(float64(baseBackoff) * math.Pow(2, float64(attempt-1))))
+	return backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+}
+
+func (s exponentialBackoff) limit(delay time.Duration) time.Duration {
+	if delay > s.maxBackoff || delay < 0 {
+		return s.maxBackoff
+	} else {
+		return delay
+	}
+}
+
+// RetryAfter returns the delay requested by a response via Graph's `x-ms-retry-after-ms` header or the standard
+// `Retry-After` header in either its delay-seconds or HTTP-date form
+func RetryAfter(header http.Header) (time.Duration, bool) {
+	if value := header.Get("x-ms-retry-after-ms"); value != "" {
+		if ms, err := strconv.ParseFloat(value, 64); err == nil && ms >= 0 {
+			return time.Duration(ms * float64(time.Millisecond)), true
+		}
+	}
+
+	if value := header.Get("Retry-After"); value == "" {
+		return 0, false
+	} else if seconds, err := strconv.ParseInt(value, 10, 64); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second, true
+	} else if date, err := http.ParseTime(value); err == nil {
+		if delay := time.Until(date); delay > 0 {
+			return delay, true
+		} else {
+			return 0, true
+		}
+	} else {
+		return 0, false
+	}
+}
+
+// isTransient reports whether a transport error is likely to go away on its own such as a connection reset or timeout
+func isTransient(err error) bool {
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	return errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNABORTED) ||
+		errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.EPIPE) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, io.EOF)
+}
@@ -0,0 +1,97 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package rest
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestRetryAfter(t *testing.T) {
+	tests := []struct {
+		header http.Header
+		delay  time.Duration
+		ok     bool
+	}{
+		{http.Header{}, 0, false},
+		{http.Header{"Retry-After": {"7"}}, 7 * time.Second, true},
+		{http.Header{"X-Ms-Retry-After-Ms": {"1500"}, "Retry-After": {"7"}}, 1500 * time.Millisecond, true},
+		{http.Header{"Retry-After": {"Wed, 21 Oct 2015 07:28:00 GMT"}}, 0, true},
+		{http.Header{"Retry-After": {"soon"}}, 0, false},
+	}
+
+	for _, test := range tests {
+		if delay, ok := RetryAfter(test.header); delay != test.delay || ok != test.ok {
+			t.Errorf("RetryAfter(%v) = %v, %v; want %v, %v", test.header, delay, ok, test.delay, test.ok)
+		}
+	}
+
+	future := time.Now().Add(time.Hour).UTC().Format(http.TimeFormat)
+	if delay, ok := RetryAfter(http.Header{"Retry-After": {future}}); !ok || delay < 59*time.Minute || delay > time.Hour {
+		t.Errorf("got %v, %v for an HTTP-date an hour from now", delay, ok)
+	}
+}
+
+func TestRetryPolicy(t *testing.T) {
+	var (
+		policy = NewRetryPolicy(2, 5*time.Second)
+		status = func(code int, header http.Header) *http.Response {
+			if header == nil {
+				header = http.Header{}
+			}
+			return &http.Response{StatusCode: code, Header: header}
+		}
+	)
+
+	tests := []struct {
+		name    string
+		attempt int
+		res     *http.Response
+		err     error
+		retry   bool
+		min     time.Duration
+		max     time.Duration
+	}{
+		{"throttled", 1, status(http.StatusTooManyRequests, nil), nil, true, time.Second, 2 * time.Second},
+		{"server error", 2, status(http.StatusServiceUnavailable, nil), nil, true, 2 * time.Second, 4 * time.Second},
+		{"retry after", 1, status(http.StatusTooManyRequests, http.Header{"Retry-After": {"3"}}), nil, true, 3 * time.Second, 3 * time.Second},
+		{"retry after is capped", 1, status(http.StatusTooManyRequests, http.Header{"Retry-After": {"60"}}), nil, true, 5 * time.Second, 5 * time.Second},
+		{"retries exhausted", 3, status(http.StatusTooManyRequests, nil), nil, false, 0, 0},
+		{"not found", 1, status(http.StatusNotFound, nil), nil, false, 0, 0},
+		{"forbidden", 1, status(http.StatusForbidden, nil), nil, false, 0, 0},
+		{"connection reset", 1, nil, fmt.Errorf("read: %w", syscall.ECONNRESET), true, time.Second, 2 * time.Second},
+		{"unexpected eof", 1, nil, io.ErrUnexpectedEOF, true, time.Second, 2 * time.Second},
+		{"canceled", 1, nil, context.Canceled, false, 0, 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			delay, retry := policy.Retry(test.attempt, test.res, test.err)
+			if retry != test.retry {
+				t.Fatalf("got retry %v, want %v", retry, test.retry)
+			} else if retry && (delay < test.min || delay > test.max) {
+				t.Errorf("got delay %v, want between %v and %v", delay, test.min, test.max)
+			}
+		})
+	}
+}
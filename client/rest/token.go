@@ -28,31 +28,60 @@ type Token struct {
 	expiresIn    int
 	extExpiresIn int
 	expires      time.Time
+	refreshToken string
 }
 
 func (s Token) IsExpired() bool {
 	return time.Now().After(s.expires.Add(-10 * time.Second))
 }
 
+func (s Token) AccessToken() string {
+	return s.accessToken
+}
+
+// RefreshToken returns the refresh token issued alongside the access token, if any
+func (s Token) RefreshToken() string {
+	return s.refreshToken
+}
+
+func (s Token) Expires() time.Time {
+	return s.expires
+}
+
+// Claims returns the claims of the access token without verifying its signature
+func (s Token) Claims() (map[string]interface{}, error) {
+	return ParseBody(s.accessToken)
+}
+
 func (s Token) String() string {
 	return fmt.Sprintf("Bearer %s", s.accessToken)
 }
 
 func (s *Token) UnmarshalJSON(data []byte) error {
 	var res struct {
-		AccessToken  string `json:"access_token"`   // The token to use in calls to Microsoft Graph API
-		ExpiresIn    int    `json:"expires_in"`     // How long the access token is valid in seconds
-		ExtExpiresIn int    `json:"ext_expires_in"` // How long the access token is valid in seconds
-		TokenType    string `json:"token_type"`     // Indicates the token type value. The only type currently supported by Azure AD is `bearer`
+		AccessToken  string      `json:"access_token"`   // The token to use in calls to Microsoft Graph API
+		ExpiresIn    json.Number `json:"expires_in"`     // How long the access token is valid in seconds
+		ExpiresOn    json.Number `json:"expires_on"`     // When the access token expires in seconds since the epoch; only sent by managed identity endpoints
+		ExtExpiresIn json.Number `json:"ext_expires_in"` // How long the access token is valid in seconds
+		RefreshToken string      `json:"refresh_token"`  // The refresh token issued alongside the access token, if any
+		TokenType    string      `json:"token_type"`     // Indicates the token type value. The only type currently supported by Azure AD is `bearer`
 	}
 
+	// json.Number accepts the quoted numbers sent by managed identity endpoints as well
 	if err := json.Unmarshal(data, &res); err != nil {
 		return err
 	} else {
+		expiresIn, _ := res.ExpiresIn.Int64()
+		extExpiresIn, _ := res.ExtExpiresIn.Int64()
 		s.accessToken = res.AccessToken
-		s.expiresIn = res.ExpiresIn
-		s.extExpiresIn = res.ExtExpiresIn
-		s.expires = time.Now().Add(time.Duration(res.ExpiresIn) * time.Second)
+		s.expiresIn = int(expiresIn)
+		s.extExpiresIn = int(extExpiresIn)
+		s.refreshToken = res.RefreshToken
+		if expiresOn, err := res.ExpiresOn.Int64(); err == nil && expiresIn == 0 {
+			s.expires = time.Unix(expiresOn, 0)
+		} else {
+			s.expires = time.Now().Add(time.Duration(expiresIn) * time.Second)
+		}
 		return nil
 	}
 }
@@ -0,0 +1,135 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package rest
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+// tokenCache keeps refresh tokens on disk between runs, encrypted with AES-256-GCM under a key derived from a passphrase
+type tokenCache struct {
+	path       string
+	passphrase string
+}
+
+type tokenCacheFile struct {
+	Salt  []byte `json:"salt"`
+	Nonce []byte `json:"nonce"`
+	Data  []byte `json:"data"`
+}
+
+func newTokenCache(path, passphrase string) (*tokenCache, error) {
+	if path == "" {
+		return nil, nil
+	} else if passphrase == "" {
+		return nil, fmt.Errorf("a key is required to encrypt the token cache")
+	} else {
+		return &tokenCache{path, passphrase}, nil
+	}
+}
+
+func (s *tokenCache) gcm(salt []byte) (cipher.AEAD, error) {
+	if key, err := scrypt.Key([]byte(s.passphrase), salt, 1<<15, 8, 1, 32); err != nil {
+		return nil, err
+	} else if block, err := aes.NewCipher(key); err != nil {
+		return nil, err
+	} else {
+		return cipher.NewGCM(block)
+	}
+}
+
+// load returns the cached refresh tokens, or none if the cache doesn't exist yet
+func (s *tokenCache) load() (map[string]string, error) {
+	var (
+		file   tokenCacheFile
+		tokens = map[string]string{}
+	)
+
+	if data, err := os.ReadFile(s.path); errors.Is(err, os.ErrNotExist) {
+		return tokens, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("unable to read token cache: %w", err)
+	} else if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("unable to parse token cache: %w", err)
+	} else if gcm, err := s.gcm(file.Salt); err != nil {
+		return nil, err
+	} else if plaintext, err := gcm.Open(nil, file.Nonce, file.Data, nil); err != nil {
+		return nil, fmt.Errorf("unable to decrypt token cache, is the key correct?")
+	} else if err := json.Unmarshal(plaintext, &tokens); err != nil {
+		return nil, fmt.Errorf("unable to parse token cache: %w", err)
+	} else {
+		return tokens, nil
+	}
+}
+
+func (s *tokenCache) read(key string) (string, error) {
+	if tokens, err := s.load(); err != nil {
+		return "", err
+	} else {
+		return tokens[key], nil
+	}
+}
+
+// write stores the refresh token for key alongside any others in the cache, re-encrypting it with a fresh salt and nonce
+func (s *tokenCache) write(key, refreshToken string) error {
+	tokens, err := s.load()
+	if err != nil {
+		return err
+	}
+	tokens[key] = refreshToken
+
+	file := tokenCacheFile{Salt: make([]byte, 16)}
+	if _, err := io.ReadFull(rand.Reader, file.Salt); err != nil {
+		return err
+	}
+
+	gcm, err := s.gcm(file.Salt)
+	if err != nil {
+		return err
+	}
+
+	file.Nonce = make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, file.Nonce); err != nil {
+		return err
+	} else if plaintext, err := json.Marshal(tokens); err != nil {
+		return err
+	} else {
+		file.Data = gcm.Seal(nil, file.Nonce, plaintext, nil)
+	}
+
+	tmp := s.path + ".tmp"
+	if data, err := json.Marshal(file); err != nil {
+		return err
+	} else if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return err
+	} else if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("unable to write token cache: %w", err)
+	} else {
+		return os.Rename(tmp, s.path)
+	}
+}
@@ -18,11 +18,8 @@
 package rest
 
 import (
-	"crypto/sha1"
-	"crypto/x509"
 	"encoding/base64"
 	"encoding/json"
-	"encoding/pem"
 	"fmt"
 	"io"
 	"strings"
@@ -30,7 +27,6 @@ import (
 
 	"github.com/gofrs/uuid"
 	"github.com/golang-jwt/jwt"
-	"github.com/youmark/pkcs8"
 )
 
 func Decode(body io.ReadCloser, v interface{}) error {
@@ -39,16 +35,16 @@ func Decode(body io.ReadCloser, v interface{}) error {
 }
 
 func NewClientAssertion(tokenUrl string, clientId string, clientCert string, signingKey string, keyPassphrase string) (string, error) {
-	if key, err := parseRSAPrivateKey(signingKey, keyPassphrase); err != nil {
-		return "", fmt.Errorf("Unable to parse private key: %w", err)
+	if credential, err := parseCertificateCredential(clientCert, signingKey, keyPassphrase); err != nil {
+		return "", fmt.Errorf("Unable to load client certificate: %w", err)
+	} else if method, err := credential.signingMethod(); err != nil {
+		return "", err
 	} else if jti, err := uuid.NewV4(); err != nil {
 		return "", fmt.Errorf("Unable to generate JWT ID: %w", err)
-	} else if thumbprint, err := x5t(clientCert); err != nil {
-		return "", fmt.Errorf("Unable to create X.509 certificate thumbprint: %w", err)
 	} else {
 		iat := time.Now()
 		exp := iat.Add(1 * time.Minute)
-		token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.StandardClaims{
+		token := jwt.NewWithClaims(method, jwt.StandardClaims{
 			Audience:  tokenUrl,
 			ExpiresAt: exp.Unix(),
 			Issuer:    clientId,
@@ -59,12 +55,13 @@ func NewClientAssertion(tokenUrl string, clientId string, clientCert string, sig
 		})
 
 		token.Header = map[string]interface{}{
-			"alg": "RS256",
+			"alg": method.Alg(),
 			"typ": "JWT",
-			"x5t": thumbprint,
+			"x5t": credential.x5t(),
+			"x5c": credential.x5c(),
 		}
 
-		if signedToken, err := token.SignedString(key); err != nil {
+		if signedToken, err := token.SignedString(credential.key); err != nil {
 			return "", fmt.Errorf("Unable to sign JWT: %w", err)
 		} else {
 			return signedToken, nil
@@ -80,7 +77,7 @@ func ParseBody(accessToken string) (map[string]interface{}, error) {
 
 	if len(parts) != 3 {
 		return body, fmt.Errorf("invalid access token")
-	} else if bytes, err := base64.RawStdEncoding.DecodeString(parts[1]); err != nil {
+	} else if bytes, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "=")); err != nil {
 		return body, err
 	} else if err := json.Unmarshal(bytes, &body); err != nil {
 		return body, err
@@ -99,23 +96,30 @@ func ParseAud(accessToken string) (string, error) {
 	}
 }
 
-func parseRSAPrivateKey(signingKey string, password string) (interface{}, error) {
-	if decodedBlock, _ := pem.Decode([]byte(signingKey)); decodedBlock == nil {
-		return nil, fmt.Errorf("Unable to decode private key")
-	} else if key, _, err := pkcs8.ParsePrivateKey(decodedBlock.Bytes, []byte(password)); err != nil {
-		return nil, err
+// ParseExp returns when an access token expires
+func ParseExp(accessToken string) (time.Time, error) {
+	if body, err := ParseBody(accessToken); err != nil {
+		return time.Time{}, err
+	} else if exp, ok := body["exp"].(float64); !ok {
+		return time.Time{}, fmt.Errorf("invalid 'exp' type: %T", body["exp"])
 	} else {
-		return key, nil
+		return time.Unix(int64(exp), 0), nil
 	}
 }
 
-func x5t(certificate string) (string, error) {
-	if decoded, _ := pem.Decode([]byte(certificate)); decoded == nil {
-		return "", fmt.Errorf("Unable to decode certificate")
-	} else if cert, err := x509.ParseCertificate(decoded.Bytes); err != nil {
-		return "", fmt.Errorf("Unable to parse certificate: %w", err)
-	} else {
-		checksum := sha1.Sum(cert.Raw)
-		return base64.StdEncoding.EncodeToString(checksum[:]), nil
+// SameAudience reports whether a token audience refers to the given API, ignoring a trailing slash
+func SameAudience(aud string, api string) bool {
+	return strings.TrimSuffix(aud, "/") == strings.TrimSuffix(api, "/")
+}
+
+// SelectJWT returns the token among jwts issued for the given API, if any
+func SelectJWT(api string, jwts []string) (string, error) {
+	for _, jwt := range jwts {
+		if aud, err := ParseAud(jwt); err != nil {
+			return "", err
+		} else if SameAudience(aud, api) {
+			return jwt, nil
+		}
 	}
+	return "", nil
 }
@@ -0,0 +1,53 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package rest
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/bloodhoundad/azurehound/internal/shell"
+)
+
+// federatedToken returns the external OIDC token exchanged for an access token by workload identity federation. It is
+// read again for every token request because the issuer rotates it, e.g. hourly for AKS projected service account tokens.
+func (s *restClient) federatedToken() (string, error) {
+	var token string
+	if s.federatedTokenFile != "" {
+		if data, err := os.ReadFile(s.federatedTokenFile); err != nil {
+			return "", fmt.Errorf("unable to read federated token: %w", err)
+		} else {
+			token = string(data)
+		}
+	} else if s.federatedTokenCommand != "" {
+		if output, err := shell.Run(s.federatedTokenCommand); err != nil {
+			return "", fmt.Errorf("unable to get federated token: %w", err)
+		} else {
+			token = output
+		}
+	} else {
+		return "", fmt.Errorf("unable to authenticate. workload identity requires a federated token file or command")
+	}
+
+	if token = strings.TrimSpace(token); token == "" {
+		return "", fmt.Errorf("unable to authenticate. the federated token is empty")
+	} else {
+		return token, nil
+	}
+}
@@ -0,0 +1,96 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package rest
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/bloodhoundad/azurehound/client/config"
+	"github.com/bloodhoundad/azurehound/enums"
+)
+
+func TestWorkloadIdentity(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if r.Form.Get("grant_type") != "client_credentials" || r.Form.Get("client_id") != "app" {
+			w.WriteHeader(http.StatusBadRequest)
+		} else {
+			json.NewEncoder(w).Encode(map[string]interface{}{"access_token": r.Form.Get("client_assertion"), "expires_in": 3600})
+		}
+	}))
+	defer server.Close()
+
+	var (
+		tokenFile = filepath.Join(t.TempDir(), "token")
+		cfg       = config.Config{
+			ApplicationId:      "app",
+			AuthMode:           enums.WorkloadIdentity,
+			Authority:          server.URL,
+			FederatedTokenFile: tokenFile,
+			Tenant:             "tenant",
+		}
+		authenticate = func(client RestClient) string {
+			if err := client.Authenticate(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			return client.(*restClient).token.accessToken
+		}
+	)
+
+	if err := os.WriteFile(tokenFile, []byte("first\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	client, err := NewRestClient("https://graph.microsoft.com", cfg)
+	if err != nil {
+		t.Fatal(err)
+	} else if token := authenticate(client); token != "first" {
+		t.Errorf("got assertion %s; want first", token)
+	}
+
+	// the token file is read again after it rotates
+	if err := os.WriteFile(tokenFile, []byte("second"), 0600); err != nil {
+		t.Fatal(err)
+	} else if token := authenticate(client); token != "second" {
+		t.Errorf("got assertion %s; want second", token)
+	}
+
+	if runtime.GOOS != "windows" {
+		cfg.FederatedTokenFile = ""
+		cfg.FederatedTokenCommand = "echo from-command"
+		if client, err := NewRestClient("https://graph.microsoft.com", cfg); err != nil {
+			t.Fatal(err)
+		} else if token := authenticate(client); token != "from-command" {
+			t.Errorf("got assertion %s; want from-command", token)
+		}
+	}
+
+	cfg.FederatedTokenFile = filepath.Join(t.TempDir(), "missing")
+	if client, err := NewRestClient("https://graph.microsoft.com", cfg); err != nil {
+		t.Fatal(err)
+	} else if err := client.Authenticate(context.Background()); err == nil {
+		t.Error("expected an error for a missing token file")
+	}
+}
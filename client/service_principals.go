@@ -19,8 +19,8 @@ package client
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
-	"net/url"
 	"strings"
 
 	"github.com/bloodhoundad/azurehound/client/query"
@@ -82,109 +82,25 @@ func (s *azureClient) GetAzureADServicePrincipals(ctx context.Context, filter, s
 }
 
 func (s *azureClient) ListAzureADServicePrincipals(ctx context.Context, filter, search, orderBy, expand string, selectCols []string) <-chan azure.ServicePrincipalResult {
-	out := make(chan azure.ServicePrincipalResult)
-
-	go func() {
-		defer close(out)
-
-		var (
-			errResult = azure.ServicePrincipalResult{}
-			nextLink  string
-		)
-
-		if list, err := s.GetAzureADServicePrincipals(ctx, filter, search, orderBy, expand, selectCols, 999, false); err != nil {
-			errResult.Error = err
-			out <- errResult
-		} else {
-			for _, u := range list.Value {
-				out <- azure.ServicePrincipalResult{Ok: u}
-			}
-
-			nextLink = list.NextLink
-			for nextLink != "" {
-				var list azure.ServicePrincipalList
-				if url, err := url.Parse(nextLink); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else if req, err := rest.NewRequest(ctx, "GET", url, nil, nil, nil); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else if res, err := s.msgraph.Send(req); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else if err := rest.Decode(res.Body, &list); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else {
-					for _, u := range list.Value {
-						out <- azure.ServicePrincipalResult{Ok: u}
-					}
-					nextLink = list.NextLink
-				}
-			}
-		}
-	}()
-	return out
+	return paginate(ctx, nextLinks(s.msgraph, func() (page[azure.ServicePrincipal], error) {
+		list, err := s.GetAzureADServicePrincipals(ctx, filter, search, orderBy, expand, selectCols, graphPageSize, false)
+		return page[azure.ServicePrincipal]{Value: list.Value, NextLink: list.NextLink}, err
+	}), func(u azure.ServicePrincipal, err error) azure.ServicePrincipalResult {
+		return azure.ServicePrincipalResult{Error: err, Ok: u}
+	})
 }
 
 func (s *azureClient) ListAzureADServicePrincipalOwners(ctx context.Context, objectId string, filter, search, orderBy string, selectCols []string) <-chan azure.ServicePrincipalOwnerResult {
-	out := make(chan azure.ServicePrincipalOwnerResult)
-
-	go func() {
-		defer close(out)
-
-		var (
-			errResult = azure.ServicePrincipalOwnerResult{
-				ServicePrincipalId: objectId,
-			}
-			nextLink string
-		)
-
-		if list, err := s.GetAzureADServicePrincipalOwners(ctx, objectId, filter, search, orderBy, selectCols, 999, false); err != nil {
-			errResult.Error = err
-			out <- errResult
-		} else {
-			for _, u := range list.Value {
-				out <- azure.ServicePrincipalOwnerResult{
-					ServicePrincipalId: objectId,
-					Ok:                 u,
-				}
-			}
-
-			nextLink = list.NextLink
-			for nextLink != "" {
-				var list azure.DirectoryObjectList
-				if url, err := url.Parse(nextLink); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else if req, err := rest.NewRequest(ctx, "GET", url, nil, nil, nil); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else if res, err := s.msgraph.Send(req); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else if err := rest.Decode(res.Body, &list); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else {
-					for _, u := range list.Value {
-						out <- azure.ServicePrincipalOwnerResult{
-							ServicePrincipalId: objectId,
-							Ok:                 u,
-						}
-					}
-					nextLink = list.NextLink
-				}
-			}
+	ctx = rest.WithBatching(ctx)
+
+	return paginate(ctx, nextLinks(s.msgraph, func() (page[json.RawMessage], error) {
+		list, err := s.GetAzureADServicePrincipalOwners(ctx, objectId, filter, search, orderBy, selectCols, graphPageSize, false)
+		return page[json.RawMessage]{Value: list.Value, NextLink: list.NextLink}, err
+	}), func(u json.RawMessage, err error) azure.ServicePrincipalOwnerResult {
+		return azure.ServicePrincipalOwnerResult{
+			Error:              err,
+			ServicePrincipalId: objectId,
+			Ok:                 u,
 		}
-	}()
-	return out
+	})
 }
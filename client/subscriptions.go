@@ -20,7 +20,6 @@ package client
 import (
 	"context"
 	"fmt"
-	"net/url"
 
 	"github.com/bloodhoundad/azurehound/client/query"
 	"github.com/bloodhoundad/azurehound/client/rest"
@@ -61,51 +60,10 @@ func (s *azureClient) GetAzureSubscriptions(ctx context.Context) (azure.Subscrip
 }
 
 func (s *azureClient) ListAzureSubscriptions(ctx context.Context) <-chan azure.SubscriptionResult {
-	out := make(chan azure.SubscriptionResult)
-
-	go func() {
-		defer close(out)
-
-		var (
-			errResult = azure.SubscriptionResult{}
-			nextLink  string
-		)
-
-		if result, err := s.GetAzureSubscriptions(ctx); err != nil {
-			errResult.Error = err
-			out <- errResult
-		} else {
-			for _, u := range result.Value {
-				out <- azure.SubscriptionResult{Ok: u}
-			}
-
-			nextLink = result.NextLink
-			for nextLink != "" {
-				var list azure.SubscriptionList
-				if url, err := url.Parse(nextLink); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else if req, err := rest.NewRequest(ctx, "GET", url, nil, nil, nil); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else if res, err := s.resourceManager.Send(req); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else if err := rest.Decode(res.Body, &list); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else {
-					for _, u := range list.Value {
-						out <- azure.SubscriptionResult{Ok: u}
-					}
-					nextLink = list.NextLink
-				}
-			}
-		}
-	}()
-	return out
+	return paginate(ctx, nextLinks(s.resourceManager, func() (page[azure.Subscription], error) {
+		result, err := s.GetAzureSubscriptions(ctx)
+		return page[azure.Subscription]{Value: result.Value, NextLink: result.NextLink}, err
+	}), func(u azure.Subscription, err error) azure.SubscriptionResult {
+		return azure.SubscriptionResult{Error: err, Ok: u}
+	})
 }
@@ -20,7 +20,6 @@ package client
 import (
 	"context"
 	"fmt"
-	"net/url"
 
 	"github.com/bloodhoundad/azurehound/client/query"
 	"github.com/bloodhoundad/azurehound/client/rest"
@@ -61,51 +60,10 @@ func (s *azureClient) GetAzureADTenants(ctx context.Context, includeAllTenantCat
 }
 
 func (s *azureClient) ListAzureADTenants(ctx context.Context, includeAllTenantCategories bool) <-chan azure.TenantResult {
-	out := make(chan azure.TenantResult)
-
-	go func() {
-		defer close(out)
-
-		var (
-			errResult = azure.TenantResult{}
-			nextLink  string
-		)
-
-		if result, err := s.GetAzureADTenants(ctx, includeAllTenantCategories); err != nil {
-			errResult.Error = err
-			out <- errResult
-		} else {
-			for _, u := range result.Value {
-				out <- azure.TenantResult{Ok: u}
-			}
-
-			nextLink = result.NextLink
-			for nextLink != "" {
-				var list azure.TenantList
-				if url, err := url.Parse(nextLink); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else if req, err := rest.NewRequest(ctx, "GET", url, nil, nil, nil); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else if res, err := s.resourceManager.Send(req); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else if err := rest.Decode(res.Body, &list); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else {
-					for _, u := range list.Value {
-						out <- azure.TenantResult{Ok: u}
-					}
-					nextLink = list.NextLink
-				}
-			}
-		}
-	}()
-	return out
+	return paginate(ctx, nextLinks(s.resourceManager, func() (page[azure.Tenant], error) {
+		result, err := s.GetAzureADTenants(ctx, includeAllTenantCategories)
+		return page[azure.Tenant]{Value: result.Value, NextLink: result.NextLink}, err
+	}), func(u azure.Tenant, err error) azure.TenantResult {
+		return azure.TenantResult{Error: err, Ok: u}
+	})
 }
@@ -20,7 +20,6 @@ package client
 import (
 	"context"
 	"fmt"
-	"net/url"
 	"strings"
 
 	"github.com/bloodhoundad/azurehound/client/query"
@@ -67,51 +66,10 @@ func (s *azureClient) GetAzureADUsers(ctx context.Context, filter string, search
 }
 
 func (s *azureClient) ListAzureADUsers(ctx context.Context, filter string, search string, orderBy string, selectCols []string) <-chan azure.UserResult {
-	out := make(chan azure.UserResult)
-
-	go func() {
-		defer close(out)
-
-		var (
-			errResult = azure.UserResult{}
-			nextLink  string
-		)
-
-		if users, err := s.GetAzureADUsers(ctx, filter, search, orderBy, selectCols, 999, false); err != nil {
-			errResult.Error = err
-			out <- errResult
-		} else {
-			for _, u := range users.Value {
-				out <- azure.UserResult{Ok: u}
-			}
-
-			nextLink = users.NextLink
-			for nextLink != "" {
-				var users azure.UserList
-				if url, err := url.Parse(nextLink); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else if req, err := rest.NewRequest(ctx, "GET", url, nil, nil, nil); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else if res, err := s.msgraph.Send(req); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else if err := rest.Decode(res.Body, &users); err != nil {
-					errResult.Error = err
-					out <- errResult
-					nextLink = ""
-				} else {
-					for _, u := range users.Value {
-						out <- azure.UserResult{Ok: u}
-					}
-					nextLink = users.NextLink
-				}
-			}
-		}
-	}()
-	return out
+	return paginate(ctx, nextLinks(s.msgraph, func() (page[azure.User], error) {
+		users, err := s.GetAzureADUsers(ctx, filter, search, orderBy, selectCols, graphPageSize, false)
+		return page[azure.User]{Value: users.Value, NextLink: users.NextLink}, err
+	}), func(u azure.User, err error) azure.UserResult {
+		return azure.UserResult{Error: err, Ok: u}
+	})
 }
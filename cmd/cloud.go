@@ -0,0 +1,83 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	client_config "github.com/bloodhoundad/azurehound/client/config"
+	"github.com/bloodhoundad/azurehound/client/rest"
+	"github.com/bloodhoundad/azurehound/config"
+)
+
+// resolveCloud configures the endpoints of the cloud described by --cloud-config or discovered from
+// --cloud-metadata-url. Endpoints given explicitly take precedence.
+func resolveCloud() error {
+	var (
+		cloudConfig = config.AzCloudConfig.Value().(string)
+		metadataUrl = config.AzCloudMetadataUrl.Value().(string)
+	)
+
+	if cloudConfig != "" {
+		if data, err := os.ReadFile(cloudConfig); err != nil {
+			return fmt.Errorf("unable to read cloud config: %w", err)
+		} else if env, err := client_config.ParseCloudMetadata(data, config.AzMgmtUrl.Value().(string)); err != nil {
+			return err
+		} else {
+			config.SetCloud(env)
+		}
+	} else if metadataUrl != "" {
+		if data, err := fetchCloudMetadata(metadataUrl); err != nil {
+			return fmt.Errorf("unable to discover cloud endpoints from %s: %w", metadataUrl, err)
+		} else if env, err := client_config.ParseCloudMetadata(data, metadataUrl); err != nil {
+			return err
+		} else {
+			config.SetCloud(env)
+		}
+	}
+	return nil
+}
+
+func fetchCloudMetadata(resourceManagerUrl string) ([]byte, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if endpoint, err := client_config.CloudMetadataUrl(resourceManagerUrl); err != nil {
+		return nil, err
+	} else if client, err := rest.NewHTTPClient(config.Proxy.Value().(string)); err != nil {
+		return nil, err
+	} else if err := rest.WithCassette(client, cassettePath(config.Record.Value().(string), "cloud"), cassettePath(config.Replay.Value().(string), "cloud")); err != nil {
+		return nil, err
+	} else if req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint.String(), nil); err != nil {
+		return nil, err
+	} else if res, err := client.Do(req); err != nil {
+		return nil, err
+	} else {
+		defer res.Body.Close()
+		if res.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("unexpected response code %s", res.Status)
+		} else {
+			return io.ReadAll(res.Body)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/bloodhoundad/azurehound/config"
+)
+
+// defaultConcurrency holds the collectors that need fewer concurrent requests than the --concurrency default
+var defaultConcurrency = map[string]int{
+	// the storage API throttles container listing far more aggressively than other ARM reads
+	"storage-containers": 2,
+}
+
+// collectorConcurrency returns the number of concurrent requests the named collector should send. A per-collector value from
+// --concurrency-overrides wins, followed by the collector's own default and then --concurrency.
+func collectorConcurrency(collector string) int {
+	overrides, _ := config.ConcurrencyOverrides.Value().([]string)
+	for _, override := range overrides {
+		if name, value, ok := strings.Cut(override, "="); !ok || strings.TrimSpace(name) != collector {
+			continue
+		} else if n, err := strconv.Atoi(strings.TrimSpace(value)); err != nil || n < 1 {
+			log.Error(fmt.Errorf("invalid concurrency override: %s", override), "ignoring concurrency override", "collector", collector)
+		} else {
+			return n
+		}
+	}
+
+	if n, ok := defaultConcurrency[collector]; ok {
+		return n
+	} else if n, ok := config.Concurrency.Value().(int); ok && n > 0 {
+		return n
+	} else {
+		return config.Concurrency.Default.(int)
+	}
+}
+
+// validateConcurrencyOverrides rejects overrides that would otherwise be ignored, such as one for a collector that
+// doesn't exist
+func validateConcurrencyOverrides() error {
+	overrides, _ := config.ConcurrencyOverrides.Value().([]string)
+	for _, override := range overrides {
+		if name, value, ok := strings.Cut(override, "="); !ok {
+			return fmt.Errorf("invalid concurrency override %s: expected <collector>=<n>", override)
+		} else if !isCollector(strings.TrimSpace(name)) {
+			return fmt.Errorf("invalid concurrency override %s: unknown collector %s, see --%s in --help for the collectors", override, strings.TrimSpace(name), config.ConcurrencyOverrides.Name)
+		} else if n, err := strconv.Atoi(strings.TrimSpace(value)); err != nil || n < 1 {
+			return fmt.Errorf("invalid concurrency override %s: expected a positive number of requests", override)
+		}
+	}
+	return nil
+}
+
+func isCollector(name string) bool {
+	for _, collector := range config.Collectors {
+		if collector == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,83 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/bloodhoundad/azurehound/config"
+)
+
+func init() {
+	setupLogger()
+}
+
+func TestConcurrency(t *testing.T) {
+	defer config.Concurrency.Set(config.Concurrency.Default)
+	defer config.ConcurrencyOverrides.Set(config.ConcurrencyOverrides.Default)
+
+	if got := collectorConcurrency("group-members"); got != 25 {
+		t.Errorf("got %d; want the default of 25", got)
+	}
+	if got := collectorConcurrency("storage-containers"); got != 2 {
+		t.Errorf("got %d; want the collector default of 2", got)
+	}
+
+	config.Concurrency.Set(5)
+	config.ConcurrencyOverrides.Set([]string{"group-members=50", "storage-containers = 4", "app-owners=many"})
+
+	if got := collectorConcurrency("group-members"); got != 50 {
+		t.Errorf("got %d; want the override of 50", got)
+	}
+	if got := collectorConcurrency("storage-containers"); got != 4 {
+		t.Errorf("got %d; want the override of 4", got)
+	}
+	if got := collectorConcurrency("app-owners"); got != 5 {
+		t.Errorf("got %d; want the global value of 5 for an invalid override", got)
+	}
+	if got := collectorConcurrency("role-assignments"); got != 5 {
+		t.Errorf("got %d; want the global value of 5", got)
+	}
+}
+
+func TestValidateConcurrencyOverrides(t *testing.T) {
+	defer config.ConcurrencyOverrides.Set(config.ConcurrencyOverrides.Default)
+
+	for name := range defaultConcurrency {
+		if !isCollector(name) {
+			t.Errorf("collector %s has a default concurrency but isn't listed in config.Collectors", name)
+		}
+	}
+
+	tests := []struct {
+		overrides []string
+		valid     bool
+	}{
+		{[]string{"group-members=50", "storage-containers = 4"}, true},
+		{[]string{"group-member=50"}, false},
+		{[]string{"group-members"}, false},
+		{[]string{"group-members=0"}, false},
+		{[]string{"app-owners=many"}, false},
+	}
+	for _, test := range tests {
+		config.ConcurrencyOverrides.Set(test.overrides)
+		if err := validateConcurrencyOverrides(); (err == nil) != test.valid {
+			t.Errorf("got error %v for %v; want valid %t", err, test.overrides, test.valid)
+		}
+	}
+}
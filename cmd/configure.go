@@ -41,7 +41,6 @@ import (
 	"github.com/gofrs/uuid"
 	"github.com/manifoldco/promptui"
 	"github.com/spf13/cobra"
-	"github.com/spf13/viper"
 	"github.com/youmark/pkcs8"
 )
 
@@ -57,20 +56,38 @@ var configureCmd = &cobra.Command{
 }
 
 func configureCmdImpl(cmd *cobra.Command, args []string) {
-	if err := configure(); err != nil {
+	// configure doesn't load the configuration so the profile is looked up directly
+	profile := cmd.Flag(config.Profile.Name).Value.String()
+	if profile == "" {
+		profile = os.Getenv(config.EnvPrefix + "_PROFILE")
+	}
+
+	if err := configure(profile); err != nil {
 		exit(err)
 	}
 }
 
-func configure() error {
+func configure(profile string) error {
 	var (
 		configFile  = config.ConfigFile.Value().(string)
 		configDir   = filepath.Dir(configFile)
 		genCert     bool
 		genCertPath = filepath.Join(configDir, "cert.pem")
 		genKeyPath  = filepath.Join(configDir, "key.pem")
+
+		// only the configs set below are written so the rest of the file is kept
+		configured []config.Config
+		set        = func(c config.Config, value interface{}) {
+			c.Set(value)
+			configured = append(configured, c)
+		}
 	)
 
+	if profile != "" {
+		genCertPath = filepath.Join(configDir, profile+"-cert.pem")
+		genKeyPath = filepath.Join(configDir, profile+"-key.pem")
+	}
+
 	// Configure Azure connection
 	if _, region, err := choose("Azure Region", config.AzRegions, 1); err != nil {
 		return err
@@ -81,18 +98,23 @@ func configure() error {
 	} else if _, authMethod, err := choose("Authentication Method", enums.AuthMethods(), 0); err != nil {
 		return err
 	} else {
-		config.AzRegion.Set(region)
-		config.AzTenant.Set(tenantId)
-		config.AzAppId.Set(appId)
+		set(config.AzRegion, region)
+		set(config.AzTenant, []string{tenantId})
+		set(config.AzAppId, appId)
+
+		// replace whatever credentials were configured before
+		for _, credential := range []config.Config{config.AzCert, config.AzKey, config.AzKeyPass, config.AzUsername, config.AzPassword, config.AzSecret} {
+			set(credential, "")
+		}
 
 		if authMethod == enums.Certificate {
 			if genCert = confirm("Generate Certificate and Key", true); genCert {
 				if keyPass, err := prompt("Private Key Passphrase (optional)", nil, true); err != nil {
 					return err
 				} else {
-					config.AzCert.Set(genCertPath)
-					config.AzKey.Set(genKeyPath)
-					config.AzKeyPass.Set(keyPass)
+					set(config.AzCert, genCertPath)
+					set(config.AzKey, genKeyPath)
+					set(config.AzKeyPass, keyPass)
 				}
 			} else if certPath, err := prompt("Public Certificate Path", validatePem, false); err != nil {
 				return err
@@ -101,9 +123,9 @@ func configure() error {
 			} else if keyPass, err := prompt("Private Key Passphrase (optional)", nil, true); err != nil {
 				return err
 			} else {
-				config.AzCert.Set(certPath)
-				config.AzKey.Set(keyPath)
-				config.AzKeyPass.Set(keyPass)
+				set(config.AzCert, certPath)
+				set(config.AzKey, keyPath)
+				set(config.AzKeyPass, keyPass)
 			}
 		} else if authMethod == enums.UsernamePassword {
 			if upn, err := prompt("Input the User Principal Name", validateUserPrincipalName, false); err != nil {
@@ -111,13 +133,13 @@ func configure() error {
 			} else if password, err := prompt("Input the password", nil, true); err != nil {
 				return err
 			} else {
-				config.AzUsername.Set(upn)
-				config.AzPassword.Set(password)
+				set(config.AzUsername, upn)
+				set(config.AzPassword, password)
 			}
 		} else if secret, err := prompt("Client Secret", nil, true); err != nil {
 			return err
 		} else {
-			config.AzSecret.Set(secret)
+			set(config.AzSecret, secret)
 		}
 
 	}
@@ -131,9 +153,9 @@ func configure() error {
 		} else if bheToken, err := prompt("BloodHound Enterprise Token", nil, true); err != nil {
 			return err
 		} else {
-			config.BHEUrl.Set(bheUrl)
-			config.BHETokenId.Set(bheTokenId)
-			config.BHEToken.Set(bheToken)
+			set(config.BHEUrl, bheUrl)
+			set(config.BHETokenId, bheTokenId)
+			set(config.BHEToken, bheToken)
 		}
 	}
 
@@ -148,7 +170,7 @@ func configure() error {
 				if parsedURL.Scheme != "https" && parsedURL.Scheme != "http" {
 					return errors.New("unsupported proxy url scheme")
 				} else {
-					config.Proxy.Set(proxyURL)
+					set(config.Proxy, proxyURL)
 				}
 			}
 		}
@@ -161,18 +183,18 @@ func configure() error {
 		} else if logFile, err := prompt("Log file (optional)", nil, false); err != nil {
 			return err
 		} else {
-			config.VerbosityLevel.Set(idx - 1)
-			config.LogFile.Set(logFile)
-			config.JsonLogs.Set(confirm("Enable Structured Logs", false))
+			set(config.VerbosityLevel, idx-1)
+			set(config.LogFile, logFile)
+			set(config.JsonLogs, confirm("Enable Structured Logs", false))
 		}
 	}
 
-	// writing the configfile path in the configfile is confusing
-	config.ConfigFile.Set(nil)
 	if err := os.MkdirAll(configDir, os.ModePerm); err != nil {
 		return err
-	} else if err := viper.WriteConfigAs(configFile); err != nil {
+	} else if err := config.SaveProfile(configFile, profile, configured); err != nil {
 		return err
+	} else if profile != "" {
+		fmt.Fprintf(os.Stderr, "\nProfile %s written to %s\n", profile, configFile)
 	} else {
 		fmt.Fprintf(os.Stderr, "\nConfiguration written to %s\n", configFile)
 	}
@@ -0,0 +1,244 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/bloodhoundad/azurehound/client"
+	"github.com/bloodhoundad/azurehound/client/rest"
+	"github.com/bloodhoundad/azurehound/enums"
+	"github.com/bloodhoundad/azurehound/models"
+	"github.com/bloodhoundad/azurehound/models/azure"
+	"github.com/bloodhoundad/azurehound/pipeline"
+)
+
+// deltaState holds the delta link of each incremental collection, keyed by tenant id and then collection
+type deltaState map[string]map[string]string
+
+var deltaStateMutex sync.Mutex
+
+func loadDeltaState(path string) (deltaState, error) {
+	state := deltaState{}
+	if data, err := os.ReadFile(path); errors.Is(err, os.ErrNotExist) {
+		return state, nil
+	} else if err != nil {
+		return nil, err
+	} else if err := json.Unmarshal(data, &state); err != nil {
+		return nil, err
+	} else {
+		return state, nil
+	}
+}
+
+func readDeltaLink(path, tenantId, collection string) (string, error) {
+	deltaStateMutex.Lock()
+	defer deltaStateMutex.Unlock()
+
+	if state, err := loadDeltaState(path); err != nil {
+		return "", err
+	} else {
+		return state[tenantId][collection], nil
+	}
+}
+
+// stagedDeltaLinks holds the delta links received by the current collection, keyed by state file, until its output
+// has been written or ingested
+var stagedDeltaLinks = map[string]deltaState{}
+
+func stageDeltaLink(path, tenantId, collection, link string) {
+	deltaStateMutex.Lock()
+	defer deltaStateMutex.Unlock()
+
+	if stagedDeltaLinks[path] == nil {
+		stagedDeltaLinks[path] = deltaState{}
+	}
+	if stagedDeltaLinks[path][tenantId] == nil {
+		stagedDeltaLinks[path][tenantId] = make(map[string]string)
+	}
+	stagedDeltaLinks[path][tenantId][collection] = link
+}
+
+// saveDeltaLinks persists the staged delta links once the output of the collection that received them is safe. The
+// links of a cancelled collection are dropped instead so the next one repeats the changes rather than skipping them.
+func saveDeltaLinks(ctx context.Context) {
+	if ctx.Err() != nil {
+		discardDeltaLinks()
+	} else if err := commitDeltaLinks(); err != nil {
+		log.Error(err, "unable to write delta state")
+	}
+}
+
+func discardDeltaLinks() {
+	deltaStateMutex.Lock()
+	defer deltaStateMutex.Unlock()
+
+	stagedDeltaLinks = map[string]deltaState{}
+}
+
+func commitDeltaLinks() error {
+	deltaStateMutex.Lock()
+	defer deltaStateMutex.Unlock()
+
+	for path, staged := range stagedDeltaLinks {
+		if err := writeDeltaLinks(path, staged); err != nil {
+			return fmt.Errorf("unable to write delta state to %s: %w", path, err)
+		}
+		delete(stagedDeltaLinks, path)
+	}
+	return nil
+}
+
+func writeDeltaLinks(path string, links deltaState) error {
+	if state, err := loadDeltaState(path); err != nil {
+		return err
+	} else {
+		for tenantId, collections := range links {
+			if state[tenantId] == nil {
+				state[tenantId] = make(map[string]string)
+			}
+			for collection, link := range collections {
+				state[tenantId][collection] = link
+			}
+		}
+
+		// write to a temporary file first so an interrupted write never loses the previous links
+		tmp := path + ".tmp"
+		if data, err := json.MarshalIndent(state, "", "  "); err != nil {
+			return err
+		} else if err := os.WriteFile(tmp, data, 0600); err != nil {
+			return err
+		} else {
+			return os.Rename(tmp, path)
+		}
+	}
+}
+
+type deltaItem[T any] struct {
+	Error     error
+	Ok        T
+	DeltaLink string
+}
+
+// listDelta continues the delta query of a collection from the link persisted by the previous collection, or starts a
+// new one when there is none, and stages the link of the next round once every page has been received; saveDeltaLinks
+// persists it after the output has been written or ingested. Objects are converted by wrap, which is told whether the
+// query is incremental and may drop an object by returning false.
+func listDelta[T any](ctx context.Context, client client.AzureClient, statePath, collection string, list func(deltaLink string) <-chan deltaItem[T], wrap func(item T, incremental bool) (AzureWrapper, bool)) <-chan interface{} {
+	out := make(chan interface{})
+
+	go func() {
+		defer close(out)
+
+		var (
+			tenantId = client.TenantInfo().TenantId
+			count    = 0
+		)
+
+		deltaLink, err := readDeltaLink(statePath, tenantId, collection)
+		if err != nil {
+			log.Error(err, "unable to read delta state; collecting all "+collection, "path", statePath)
+		}
+
+		for {
+			var (
+				incremental = deltaLink != ""
+				restart     = false
+			)
+			for item := range list(deltaLink) {
+				if item.Error != nil && incremental && count == 0 && deltaLinkExpired(item.Error) {
+					log.Error(item.Error, "unable to continue from the previous delta link; collecting all "+collection)
+					restart = true
+				} else if item.Error != nil {
+					log.Error(item.Error, "unable to continue processing "+collection)
+				} else if item.DeltaLink != "" {
+					stageDeltaLink(statePath, tenantId, collection, item.DeltaLink)
+				} else if wrapper, ok := wrap(item.Ok, incremental); ok {
+					log.V(2).Info("found "+collection+" change", "data", wrapper.Data)
+					count++
+					out <- wrapper
+				}
+			}
+
+			if restart {
+				deltaLink = ""
+			} else {
+				break
+			}
+		}
+		log.Info("finished listing all "+collection, "count", count)
+	}()
+
+	return out
+}
+
+// deltaLinkExpired reports whether a delta query failed because its link expired, in which case the collection starts
+// over. Any other error leaves the link to be tried again by the next collection.
+func deltaLinkExpired(err error) bool {
+	var resErr rest.ResponseError
+	if !errors.As(err, &resErr) {
+		return false
+	} else if resErr.StatusCode == http.StatusGone {
+		return true
+	} else if body, ok := resErr.Body["error"].(map[string]interface{}); !ok {
+		return false
+	} else {
+		code, _ := body["code"].(string)
+		return strings.EqualFold(code, "syncStateNotFound") || strings.EqualFold(code, "resyncRequired")
+	}
+}
+
+// deltaChange marks an object of an incremental delta query; objects of a new query are unmarked
+func deltaChange(object azure.DirectoryObject, incremental bool) enums.DeltaChange {
+	if !incremental {
+		return ""
+	} else if object.Removed != nil {
+		return enums.DeltaChangeRemoved
+	} else {
+		return enums.DeltaChangeUpdated
+	}
+}
+
+// withoutRemoved drops objects a delta query reported as removed since they have no relationships left to collect
+func withoutRemoved(ctx context.Context, in <-chan interface{}) <-chan interface{} {
+	return pipeline.Filter(ctx.Done(), in, func(item interface{}) bool {
+		if wrapper, ok := item.(AzureWrapper); !ok {
+			return true
+		} else {
+			switch data := wrapper.Data.(type) {
+			case models.App:
+				return data.Delta != enums.DeltaChangeRemoved
+			case models.Group:
+				return data.Delta != enums.DeltaChangeRemoved
+			case models.ServicePrincipal:
+				return data.Delta != enums.DeltaChangeRemoved
+			case models.User:
+				return data.Delta != enums.DeltaChangeRemoved
+			default:
+				return true
+			}
+		}
+	})
+}
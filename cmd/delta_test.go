@@ -0,0 +1,184 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	"github.com/bloodhoundad/azurehound/client/mocks"
+	"github.com/bloodhoundad/azurehound/client/rest"
+	"github.com/bloodhoundad/azurehound/enums"
+	"github.com/bloodhoundad/azurehound/models"
+	"github.com/bloodhoundad/azurehound/models/azure"
+	"github.com/golang/mock/gomock"
+)
+
+func init() {
+	setupLogger()
+}
+
+func mockUserDeltaResults(results ...azure.UserResult) <-chan azure.UserResult {
+	out := make(chan azure.UserResult)
+	go func() {
+		defer close(out)
+		for _, result := range results {
+			out <- result
+		}
+	}()
+	return out
+}
+
+func TestListUsersDelta(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ctx := context.Background()
+
+	var (
+		mockClient = mocks.NewMockAzureClient(ctrl)
+		mockTenant = azure.Tenant{TenantId: "tenant"}
+		statePath  = filepath.Join(t.TempDir(), "delta.json")
+	)
+	mockClient.EXPECT().TenantInfo().Return(mockTenant).AnyTimes()
+	mockClient.EXPECT().ListAzureADUsersDelta(gomock.Any(), "", gomock.Any()).Return(mockUserDeltaResults(
+		azure.UserResult{Ok: azure.User{DirectoryObject: azure.DirectoryObject{Id: "foo"}}},
+		azure.UserResult{DeltaLink: "link1"},
+	)).Times(1)
+	mockClient.EXPECT().ListAzureADUsersDelta(gomock.Any(), "link1", gomock.Any()).Return(mockUserDeltaResults(
+		azure.UserResult{Ok: azure.User{DirectoryObject: azure.DirectoryObject{Id: "foo"}}},
+		azure.UserResult{Ok: azure.User{DirectoryObject: azure.DirectoryObject{Id: "bar", Removed: &azure.DeltaRemoved{Reason: "deleted"}}}},
+		azure.UserResult{DeltaLink: "link2"},
+	)).Times(1)
+
+	// a new round emits every user unmarked
+	channel := listUsersDelta(ctx, mockClient, statePath)
+	if result, ok := <-channel; !ok {
+		t.Fatalf("failed to receive from channel")
+	} else if user, ok := result.(AzureWrapper).Data.(models.User); !ok {
+		t.Errorf("failed type assertion: got %T, want %T", result.(AzureWrapper).Data, models.User{})
+	} else if user.Delta != "" {
+		t.Errorf("got %s, want an unmarked user", user.Delta)
+	}
+	if _, ok := <-channel; ok {
+		t.Error("should not have recieved from channel")
+	}
+
+	// the link is only persisted once the output is safe
+	if link, err := readDeltaLink(statePath, "tenant", "users"); err != nil {
+		t.Fatal(err)
+	} else if link != "" {
+		t.Errorf("got %s, want no link before the output is written", link)
+	}
+	saveDeltaLinks(ctx)
+	if link, err := readDeltaLink(statePath, "tenant", "users"); err != nil {
+		t.Fatal(err)
+	} else if link != "link1" {
+		t.Errorf("got %s, want link1", link)
+	}
+
+	// the next round continues from the persisted link and marks each change
+	channel = listUsersDelta(ctx, mockClient, statePath)
+	for _, want := range []enums.DeltaChange{enums.DeltaChangeUpdated, enums.DeltaChangeRemoved} {
+		if result, ok := <-channel; !ok {
+			t.Fatalf("failed to receive from channel")
+		} else if user, ok := result.(AzureWrapper).Data.(models.User); !ok {
+			t.Errorf("failed type assertion: got %T, want %T", result.(AzureWrapper).Data, models.User{})
+		} else if user.Delta != want {
+			t.Errorf("got %s, want %s", user.Delta, want)
+		}
+	}
+	if _, ok := <-channel; ok {
+		t.Error("should not have recieved from channel")
+	}
+
+	saveDeltaLinks(ctx)
+	if link, err := readDeltaLink(statePath, "tenant", "users"); err != nil {
+		t.Fatal(err)
+	} else if link != "link2" {
+		t.Errorf("got %s, want link2", link)
+	}
+}
+
+func TestListUsersDeltaErrors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ctx := context.Background()
+
+	var (
+		mockClient = mocks.NewMockAzureClient(ctrl)
+		statePath  = filepath.Join(t.TempDir(), "delta.json")
+		expired    = rest.ResponseError{StatusCode: http.StatusBadRequest, Body: map[string]interface{}{"error": map[string]interface{}{"code": "syncStateNotFound"}}}
+	)
+	mockClient.EXPECT().TenantInfo().Return(azure.Tenant{TenantId: "tenant"}).AnyTimes()
+	if err := writeDeltaLinks(statePath, deltaState{"tenant": {"users": "link1"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	// an unavailable service keeps the link for the next collection
+	mockClient.EXPECT().ListAzureADUsersDelta(gomock.Any(), "link1", gomock.Any()).Return(mockUserDeltaResults(
+		azure.UserResult{Error: rest.ResponseError{StatusCode: http.StatusServiceUnavailable}},
+	)).Times(1)
+	for range listUsersDelta(ctx, mockClient, statePath) {
+		t.Error("should not have recieved from channel")
+	}
+	saveDeltaLinks(ctx)
+	if link, err := readDeltaLink(statePath, "tenant", "users"); err != nil {
+		t.Fatal(err)
+	} else if link != "link1" {
+		t.Errorf("got %q, want link1 kept", link)
+	}
+
+	// an expired link starts the collection over
+	mockClient.EXPECT().ListAzureADUsersDelta(gomock.Any(), "link1", gomock.Any()).Return(mockUserDeltaResults(
+		azure.UserResult{Error: expired},
+	)).Times(1)
+	mockClient.EXPECT().ListAzureADUsersDelta(gomock.Any(), "", gomock.Any()).Return(mockUserDeltaResults(
+		azure.UserResult{Ok: azure.User{DirectoryObject: azure.DirectoryObject{Id: "foo"}}},
+		azure.UserResult{DeltaLink: "link2"},
+	)).Times(1)
+	count := 0
+	for range listUsersDelta(ctx, mockClient, statePath) {
+		count++
+	}
+	saveDeltaLinks(ctx)
+	if count != 1 {
+		t.Errorf("got %d users, want 1", count)
+	} else if link, err := readDeltaLink(statePath, "tenant", "users"); err != nil {
+		t.Fatal(err)
+	} else if link != "link2" {
+		t.Errorf("got %q, want link2", link)
+	}
+}
+
+func TestSaveDeltaLinksCancelled(t *testing.T) {
+	statePath := filepath.Join(t.TempDir(), "delta.json")
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	stageDeltaLink(statePath, "tenant", "users", "link1")
+	saveDeltaLinks(ctx)
+	saveDeltaLinks(context.Background())
+
+	if link, err := readDeltaLink(statePath, "tenant", "users"); err != nil {
+		t.Fatal(err)
+	} else if link != "" {
+		t.Errorf("got %s, want the link of a cancelled collection dropped", link)
+	}
+}
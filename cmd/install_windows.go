@@ -88,7 +88,7 @@ func configureService() error {
 	}
 
 	config.ConfigFile.Set(sysConfig)
-	return configure()
+	return configure("")
 }
 
 func shouldUseConfig(config string) bool {
@@ -55,18 +55,18 @@ func listAppOwnersCmdImpl(cmd *cobra.Command, args []string) {
 	} else {
 		log.Info("collecting azure app owners...")
 		start := time.Now()
-		stream := listAppOwners(ctx, azClient, listApps(ctx, azClient))
+		stream := listAppOwners(ctx, azClient, listApps(ctx, azClient), collectorConcurrency("app-owners"))
 		outputStream(ctx, stream)
 		duration := time.Since(start)
 		log.Info("collection completed", "duration", duration.String())
 	}
 }
 
-func listAppOwners(ctx context.Context, client client.AzureClient, apps <-chan interface{}) <-chan interface{} {
+func listAppOwners(ctx context.Context, client client.AzureClient, apps <-chan interface{}, concurrency int) <-chan interface{} {
 	var (
 		out     = make(chan interface{})
 		ids     = make(chan string)
-		streams = pipeline.Demux(ctx.Done(), ids, 25)
+		streams = pipeline.Demux(ctx.Done(), ids, concurrency)
 		wg      sync.WaitGroup
 	)
 
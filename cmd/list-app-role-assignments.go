@@ -56,18 +56,18 @@ func listAppRoleAssignmentsCmdImpl(cmd *cobra.Command, args []string) {
 		log.Info("collecting azure active directory app role assignments...")
 		start := time.Now()
 		servicePrincipals := listServicePrincipals(ctx, azClient)
-		stream := listAppRoleAssignments(ctx, azClient, servicePrincipals)
+		stream := listAppRoleAssignments(ctx, azClient, servicePrincipals, collectorConcurrency("app-role-assignments"))
 		outputStream(ctx, stream)
 		duration := time.Since(start)
 		log.Info("collection completed", "duration", duration.String())
 	}
 }
 
-func listAppRoleAssignments(ctx context.Context, client client.AzureClient, servicePrincipals <-chan interface{}) <-chan interface{} {
+func listAppRoleAssignments(ctx context.Context, client client.AzureClient, servicePrincipals <-chan interface{}, concurrency int) <-chan interface{} {
 	var (
 		out         = make(chan interface{})
 		filteredSPs = make(chan models.ServicePrincipal)
-		streams     = pipeline.Demux(ctx.Done(), filteredSPs, 25)
+		streams     = pipeline.Demux(ctx.Done(), filteredSPs, concurrency)
 		wg          sync.WaitGroup
 	)
 
@@ -101,12 +101,15 @@ func listAppRoleAssignments(ctx context.Context, client client.AzureClient, serv
 					} else {
 						log.V(2).Info("found app role assignment", "roleAssignments", item)
 						count++
+						permission := resolvePermission(servicePrincipal.AppId, servicePrincipal.ServicePrincipal, item.Ok.AppRoleId, enums.AccessTypeRole)
 						out <- AzureWrapper{
 							Kind: enums.KindAZAppRoleAssignment,
 							Data: models.AppRoleAssignment{
 								AppRoleAssignment: item.Ok,
 								AppId:             servicePrincipal.AppId,
 								TenantId:          client.TenantInfo().TenantId,
+								AppRoleValue:      permission.Value,
+								IsTierZero:        permission.IsTierZero,
 							},
 						}
 					}
@@ -24,8 +24,11 @@ import (
 	"time"
 
 	"github.com/bloodhoundad/azurehound/client"
+	"github.com/bloodhoundad/azurehound/config"
 	"github.com/bloodhoundad/azurehound/enums"
 	"github.com/bloodhoundad/azurehound/models"
+	"github.com/bloodhoundad/azurehound/models/azure"
+	"github.com/bloodhoundad/azurehound/pipeline"
 	"github.com/spf13/cobra"
 )
 
@@ -35,7 +38,7 @@ func init() {
 
 var listAppsCmd = &cobra.Command{
 	Use:          "apps",
-	Long:         "Lists Azure Active Directory Applications",
+	Long:         "Lists Azure Active Directory Applications along with the permissions they require",
 	Run:          listAppsCmdImpl,
 	SilenceUsage: true,
 }
@@ -52,7 +55,8 @@ func listAppsCmdImpl(cmd *cobra.Command, args []string) {
 	} else {
 		log.Info("collecting azure active directory applications...")
 		start := time.Now()
-		stream := listApps(ctx, azClient)
+		resolver := newPermissionResolver(ctx, listPermissionResources(ctx, azClient))
+		stream := resolveAppPermissions(ctx, resolver, listApps(ctx, azClient))
 		outputStream(ctx, stream)
 		duration := time.Since(start)
 		log.Info("collection completed", "duration", duration.String())
@@ -60,6 +64,10 @@ func listAppsCmdImpl(cmd *cobra.Command, args []string) {
 }
 
 func listApps(ctx context.Context, client client.AzureClient) <-chan interface{} {
+	if statePath, _ := config.DeltaState.Value().(string); statePath != "" {
+		return listAppsDelta(ctx, client, statePath)
+	}
+
 	out := make(chan interface{})
 
 	go func() {
@@ -87,3 +95,23 @@ func listApps(ctx context.Context, client client.AzureClient) <-chan interface{}
 
 	return out
 }
+
+func listAppsDelta(ctx context.Context, client client.AzureClient, statePath string) <-chan interface{} {
+	list := func(deltaLink string) <-chan deltaItem[azure.Application] {
+		return pipeline.Map(ctx.Done(), client.ListAzureADAppsDelta(ctx, deltaLink, nil), func(item azure.ApplicationResult) deltaItem[azure.Application] {
+			return deltaItem[azure.Application]{Error: item.Error, Ok: item.Ok, DeltaLink: item.DeltaLink}
+		})
+	}
+
+	return listDelta(ctx, client, statePath, "apps", list, func(item azure.Application, incremental bool) (AzureWrapper, bool) {
+		return AzureWrapper{
+			Kind: enums.KindAZApp,
+			Data: models.App{
+				Application: item,
+				TenantId:    client.TenantInfo().TenantId,
+				TenantName:  client.TenantInfo().DisplayName,
+				Delta:       deltaChange(item.DirectoryObject, incremental),
+			},
+		}, true
+	})
+}
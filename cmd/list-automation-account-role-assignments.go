@@ -57,18 +57,18 @@ func listAutomationAccountRoleAssignmentImpl(cmd *cobra.Command, args []string)
 		log.Info("collecting azure automation account role assignments...")
 		start := time.Now()
 		subscriptions := listSubscriptions(ctx, azClient)
-		stream := listAutomationAccountRoleAssignments(ctx, azClient, listAutomationAccounts(ctx, azClient, subscriptions))
+		stream := listAutomationAccountRoleAssignments(ctx, azClient, listAutomationAccounts(ctx, azClient, subscriptions, collectorConcurrency("automation-accounts")), collectorConcurrency("automation-account-role-assignments"))
 		outputStream(ctx, stream)
 		duration := time.Since(start)
 		log.Info("collection completed", "duration", duration.String())
 	}
 }
 
-func listAutomationAccountRoleAssignments(ctx context.Context, client client.AzureClient, automationAccounts <-chan interface{}) <-chan interface{} {
+func listAutomationAccountRoleAssignments(ctx context.Context, client client.AzureClient, automationAccounts <-chan interface{}, concurrency int) <-chan interface{} {
 	var (
 		out     = make(chan interface{})
 		ids     = make(chan string)
-		streams = pipeline.Demux(ctx.Done(), ids, 25)
+		streams = pipeline.Demux(ctx.Done(), ids, concurrency)
 		wg      sync.WaitGroup
 	)
 
@@ -55,18 +55,18 @@ func listAutomationAccountsCmdImpl(cmd *cobra.Command, args []string) {
 	} else {
 		log.Info("collecting azure automation accounts...")
 		start := time.Now()
-		stream := listAutomationAccounts(ctx, azClient, listSubscriptions(ctx, azClient))
+		stream := listAutomationAccounts(ctx, azClient, listSubscriptions(ctx, azClient), collectorConcurrency("automation-accounts"))
 		outputStream(ctx, stream)
 		duration := time.Since(start)
 		log.Info("collection completed", "duration", duration.String())
 	}
 }
 
-func listAutomationAccounts(ctx context.Context, client client.AzureClient, subscriptions <-chan interface{}) <-chan interface{} {
+func listAutomationAccounts(ctx context.Context, client client.AzureClient, subscriptions <-chan interface{}, concurrency int) <-chan interface{} {
 	var (
 		out     = make(chan interface{})
 		ids     = make(chan string)
-		streams = pipeline.Demux(ctx.Done(), ids, 25)
+		streams = pipeline.Demux(ctx.Done(), ids, concurrency)
 		wg      sync.WaitGroup
 	)
 
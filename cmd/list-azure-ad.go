@@ -25,6 +25,7 @@ import (
 	"time"
 
 	"github.com/bloodhoundad/azurehound/client"
+	"github.com/bloodhoundad/azurehound/config"
 	"github.com/bloodhoundad/azurehound/pipeline"
 	"github.com/spf13/cobra"
 )
@@ -82,26 +83,36 @@ func listAllAD(ctx context.Context, client client.AzureClient) <-chan interface{
 		servicePrincipals  = make(chan interface{})
 		servicePrincipals2 = make(chan interface{})
 		servicePrincipals3 = make(chan interface{})
+		servicePrincipals4 = make(chan interface{})
 
 		tenants = make(chan interface{})
 	)
 
-	// Enumerate Apps, AppOwners and AppMembers
+	// Enumerate ServicePrincipals, ServicePrincipalOwners and the permissions they expose. A delta round only carries
+	// the service principals that changed, so their permissions are indexed from a listing of all of them instead.
+	var resolver *permissionResolver
+	if statePath, _ := config.DeltaState.Value().(string); statePath != "" {
+		pipeline.Tee(ctx.Done(), listServicePrincipals(ctx, client), servicePrincipals, servicePrincipals2, servicePrincipals3)
+		resolver = newPermissionResolver(ctx, listPermissionResources(ctx, client))
+	} else {
+		pipeline.Tee(ctx.Done(), listServicePrincipals(ctx, client), servicePrincipals, servicePrincipals2, servicePrincipals3, servicePrincipals4)
+		resolver = newPermissionResolver(ctx, withoutRemoved(ctx, servicePrincipals4))
+	}
+	servicePrincipalOwners := listServicePrincipalOwners(ctx, client, withoutRemoved(ctx, servicePrincipals2), collectorConcurrency("service-principal-owners"))
+
+	// Enumerate Apps, AppOwners and the permissions they require
 	pipeline.Tee(ctx.Done(), listApps(ctx, client), apps, apps2)
-	appOwners := listAppOwners(ctx, client, apps2)
+	appOwners := listAppOwners(ctx, client, withoutRemoved(ctx, apps2), collectorConcurrency("app-owners"))
+	resolvedApps := resolveAppPermissions(ctx, resolver, apps)
 
 	// Enumerate Devices and DeviceOwners
 	pipeline.Tee(ctx.Done(), listDevices(ctx, client), devices, devices2)
-	deviceOwners := listDeviceOwners(ctx, client, devices2)
+	deviceOwners := listDeviceOwners(ctx, client, devices2, collectorConcurrency("device-owners"))
 
 	// Enumerate Groups, GroupOwners and GroupMembers
 	pipeline.Tee(ctx.Done(), listGroups(ctx, client), groups, groups2, groups3)
-	groupOwners := listGroupOwners(ctx, client, groups2)
-	groupMembers := listGroupMembers(ctx, client, groups3)
-
-	// Enumerate ServicePrincipals and ServicePrincipalOwners
-	pipeline.Tee(ctx.Done(), listServicePrincipals(ctx, client), servicePrincipals, servicePrincipals2, servicePrincipals3)
-	servicePrincipalOwners := listServicePrincipalOwners(ctx, client, servicePrincipals2)
+	groupOwners := listGroupOwners(ctx, client, withoutRemoved(ctx, groups2), collectorConcurrency("group-owners"))
+	groupMembers := listGroupMembers(ctx, client, withoutRemoved(ctx, groups3), collectorConcurrency("group-members"))
 
 	// Enumerate Tenants
 	pipeline.Tee(ctx.Done(), listTenants(ctx, client), tenants)
@@ -111,15 +122,15 @@ func listAllAD(ctx context.Context, client client.AzureClient) <-chan interface{
 
 	// Enumerate Roles and RoleAssignments
 	pipeline.Tee(ctx.Done(), listRoles(ctx, client), roles, roles2)
-	roleAssignments := listRoleAssignments(ctx, client, roles2)
+	roleAssignments := listRoleAssignments(ctx, client, roles2, collectorConcurrency("role-assignments"))
 
 	// Enumerate AppRoleAssignments
-	appRoleAssignments := listAppRoleAssignments(ctx, client, servicePrincipals3)
+	appRoleAssignments := listAppRoleAssignments(ctx, client, withoutRemoved(ctx, servicePrincipals3), collectorConcurrency("app-role-assignments"))
 
-	return pipeline.Mux(ctx.Done(),
+	streams := []<-chan interface{}{
 		appOwners,
 		appRoleAssignments,
-		apps,
+		resolvedApps,
 		deviceOwners,
 		devices,
 		groupMembers,
@@ -131,5 +142,12 @@ func listAllAD(ctx context.Context, client client.AzureClient) <-chan interface{
 		servicePrincipals,
 		tenants,
 		users,
-	)
+	}
+
+	// Enumerate soft-deleted Users, Groups, Apps and ServicePrincipals
+	if includeDeleted, ok := config.IncludeDeleted.Value().(bool); ok && includeDeleted {
+		streams = append(streams, listDeletedObjects(ctx, client))
+	}
+
+	return pipeline.Mux(ctx.Done(), streams...)
 }
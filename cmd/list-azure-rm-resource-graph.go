@@ -0,0 +1,404 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/bloodhoundad/azurehound/client"
+	"github.com/bloodhoundad/azurehound/enums"
+	"github.com/bloodhoundad/azurehound/models"
+	"github.com/bloodhoundad/azurehound/models/azure"
+	"github.com/bloodhoundad/azurehound/pipeline"
+)
+
+const (
+	resourceGraphResourceGroupsQuery   = "resourcecontainers | where type =~ 'microsoft.resources/subscriptions/resourcegroups'"
+	resourceGraphKeyVaultsQuery        = "resources | where type =~ 'microsoft.keyvault/vaults'"
+	resourceGraphVirtualMachinesQuery  = "resources | where type =~ 'microsoft.compute/virtualmachines'"
+	resourceGraphRoleAssignmentsQuery  = "authorizationresources | where type =~ 'microsoft.authorization/roleassignments' | project id, name, type, properties"
+	resourceGraphSubscriptionsQuery    = "resourcecontainers | where type =~ 'microsoft.resources/subscriptions' | project subscriptionId, ancestors = properties.managementGroupAncestorsChain"
+	resourceGraphManagementGroupFilter = " | where tostring(properties.scope) !startswith '/subscriptions/'"
+	managementGroupScopePrefix         = "/providers/microsoft.management/managementgroups/"
+)
+
+// resourceGraph collects resource groups, key vaults, virtual machines and the role assignments scoped to them with a
+// handful of Azure Resource Graph queries instead of one or more ARM requests per subscription and resource.
+//
+// Role assignments are indexed once by scope and resolved per resource the same way ARM does: assignments at the
+// resource, at any of its ancestors (including management groups and the root scope) and, unless only the assignments
+// at or above the scope are requested, beneath it.
+type resourceGraph struct {
+	ready     chan struct{}
+	ancestors map[string][]string
+	atScope   map[string][]azure.RoleAssignment
+	beneath   map[string][]azure.RoleAssignment
+}
+
+func newResourceGraph(ctx context.Context, client client.AzureClient, subscriptions <-chan interface{}) *resourceGraph {
+	s := &resourceGraph{
+		ready:     make(chan struct{}),
+		ancestors: make(map[string][]string),
+		atScope:   make(map[string][]azure.RoleAssignment),
+		beneath:   make(map[string][]azure.RoleAssignment),
+	}
+
+	go func() {
+		defer close(s.ready)
+		s.index(ctx, client, collectSubscriptionIds(ctx, subscriptions))
+	}()
+
+	return s
+}
+
+// collectors returns the listAllRM collectors backed by this resource graph
+func (s *resourceGraph) collectors() rmCollectors {
+	return rmCollectors{
+		resourceGroups:                listResourceGraphResourceGroups,
+		keyVaults:                     listResourceGraphKeyVaults,
+		virtualMachines:               listResourceGraphVirtualMachines,
+		subscriptionRoleAssignments:   s.listSubscriptionRoleAssignments,
+		resourceGroupRoleAssignments:  s.listResourceGroupRoleAssignments,
+		keyVaultRoleAssignments:       s.listKeyVaultRoleAssignments,
+		virtualMachineRoleAssignments: s.listVirtualMachineRoleAssignments,
+	}
+}
+
+func (s *resourceGraph) index(ctx context.Context, client client.AzureClient, subscriptionIds []string) {
+	if len(subscriptionIds) == 0 {
+		return
+	}
+
+	roots := make(map[string]struct{})
+	for item := range client.ListResourceGraphQuery(ctx, subscriptionIds, nil, resourceGraphSubscriptionsQuery) {
+		var row struct {
+			SubscriptionId string `json:"subscriptionId"`
+			Ancestors      []struct {
+				Name string `json:"name"`
+			} `json:"ancestors"`
+		}
+		if item.Error != nil {
+			log.Error(item.Error, "unable to continue indexing management group ancestors of subscriptions")
+		} else if err := json.Unmarshal(item.Ok, &row); err != nil {
+			log.Error(err, "unable to decode resource graph subscription", "result", string(item.Ok))
+		} else {
+			id := strings.ToLower(row.SubscriptionId)
+			for _, ancestor := range row.Ancestors {
+				s.ancestors[id] = append(s.ancestors[id], strings.ToLower(ancestor.Name))
+			}
+			// the ancestors chain is ordered from the parent management group to the root management group
+			if len(row.Ancestors) > 0 {
+				roots[row.Ancestors[len(row.Ancestors)-1].Name] = struct{}{}
+			}
+		}
+	}
+
+	count := s.indexRoleAssignments(ctx, client, subscriptionIds, nil, resourceGraphRoleAssignmentsQuery)
+	if len(roots) > 0 {
+		managementGroupIds := make([]string, 0, len(roots))
+		for root := range roots {
+			managementGroupIds = append(managementGroupIds, root)
+		}
+		count += s.indexRoleAssignments(ctx, client, nil, managementGroupIds, resourceGraphRoleAssignmentsQuery+resourceGraphManagementGroupFilter)
+	}
+	log.V(1).Info("finished indexing resource graph role assignments", "subscriptions", len(subscriptionIds), "count", count)
+}
+
+func (s *resourceGraph) indexRoleAssignments(ctx context.Context, client client.AzureClient, subscriptionIds []string, managementGroupIds []string, kql string) int {
+	count := 0
+	for item := range client.ListResourceGraphQuery(ctx, subscriptionIds, managementGroupIds, kql) {
+		var roleAssignment azure.RoleAssignment
+		if item.Error != nil {
+			log.Error(item.Error, "unable to continue indexing resource graph role assignments")
+		} else if err := json.Unmarshal(item.Ok, &roleAssignment); err != nil {
+			log.Error(err, "unable to decode resource graph role assignment", "result", string(item.Ok))
+		} else {
+			scope := normalizeScope(roleAssignment.Properties.Scope)
+			s.atScope[scope] = append(s.atScope[scope], roleAssignment)
+			for i := strings.LastIndex(scope, "/"); i > 0; i = strings.LastIndex(scope[:i], "/") {
+				s.beneath[scope[:i]] = append(s.beneath[scope[:i]], roleAssignment)
+			}
+			count++
+		}
+	}
+	return count
+}
+
+// roleAssignments returns the role assignments that apply to the resource, mirroring the ARM role assignments API
+func (s *resourceGraph) roleAssignments(ctx context.Context, resourceId string, includeBeneath bool) []azure.RoleAssignment {
+	select {
+	case <-ctx.Done():
+		return nil
+	case <-s.ready:
+	}
+
+	var (
+		id     = normalizeScope(resourceId)
+		result = append([]azure.RoleAssignment{}, s.atScope["/"]...)
+	)
+
+	// the resource itself and each of its ancestors
+	for i := len(id); i > 0; i = strings.LastIndex(id[:i], "/") {
+		result = append(result, s.atScope[id[:i]]...)
+	}
+
+	// the management groups containing the resource's subscription
+	if parts := strings.Split(id, "/"); len(parts) > 2 && parts[1] == "subscriptions" {
+		for _, managementGroup := range s.ancestors[parts[2]] {
+			result = append(result, s.atScope[managementGroupScopePrefix+managementGroup]...)
+		}
+	}
+
+	if includeBeneath {
+		result = append(result, s.beneath[id]...)
+	}
+
+	return result
+}
+
+func (s *resourceGraph) listSubscriptionRoleAssignments(ctx context.Context, _ client.AzureClient, subscriptions <-chan interface{}, _ int) <-chan interface{} {
+	out := make(chan interface{})
+
+	go func() {
+		defer close(out)
+
+		for result := range pipeline.OrDone(ctx.Done(), subscriptions) {
+			if subscription, ok := result.(AzureWrapper).Data.(models.Subscription); !ok {
+				log.Error(fmt.Errorf("failed type assertion"), "unable to continue enumerating subscription role assignments", "result", result)
+				return
+			} else {
+				subscriptionRoleAssignments := models.SubscriptionRoleAssignments{
+					SubscriptionId: subscription.Id,
+				}
+				for _, item := range s.roleAssignments(ctx, subscription.Id, false) {
+					subscriptionRoleAssignments.RoleAssignments = append(subscriptionRoleAssignments.RoleAssignments, models.SubscriptionRoleAssignment{
+						SubscriptionId: subscription.Id,
+						RoleAssignment: item,
+					})
+				}
+				out <- AzureWrapper{
+					Kind: enums.KindAZSubscriptionRoleAssignment,
+					Data: subscriptionRoleAssignments,
+				}
+				log.V(1).Info("finished listing subscription role assignments", "subscriptionId", subscription.Id, "count", len(subscriptionRoleAssignments.RoleAssignments))
+			}
+		}
+		log.Info("finished listing all subscription role assignments")
+	}()
+
+	return out
+}
+
+func (s *resourceGraph) listResourceGroupRoleAssignments(ctx context.Context, _ client.AzureClient, resourceGroups <-chan interface{}, _ int) <-chan azureWrapper[models.ResourceGroupRoleAssignments] {
+	out := make(chan azureWrapper[models.ResourceGroupRoleAssignments])
+
+	go func() {
+		defer close(out)
+
+		for result := range pipeline.OrDone(ctx.Done(), resourceGroups) {
+			if resourceGroup, ok := result.(AzureWrapper).Data.(models.ResourceGroup); !ok {
+				log.Error(fmt.Errorf("failed type assertion"), "unable to continue enumerating resource group role assignments", "result", result)
+				return
+			} else {
+				resourceGroupRoleAssignments := models.ResourceGroupRoleAssignments{
+					ResourceGroupId: resourceGroup.Id,
+				}
+				for _, item := range s.roleAssignments(ctx, resourceGroup.Id, true) {
+					resourceGroupRoleAssignments.RoleAssignments = append(resourceGroupRoleAssignments.RoleAssignments, models.ResourceGroupRoleAssignment{
+						ResourceGroupId: resourceGroup.Id,
+						RoleAssignment:  item,
+					})
+				}
+				out <- NewAzureWrapper(enums.KindAZResourceGroupRoleAssignment, resourceGroupRoleAssignments)
+				log.V(1).Info("finished listing resource group role assignments", "resourceGroupId", resourceGroup.Id, "count", len(resourceGroupRoleAssignments.RoleAssignments))
+			}
+		}
+		log.Info("finished listing all resource group role assignments")
+	}()
+
+	return out
+}
+
+func (s *resourceGraph) listKeyVaultRoleAssignments(ctx context.Context, _ client.AzureClient, keyVaults <-chan interface{}, _ int) <-chan azureWrapper[models.KeyVaultRoleAssignments] {
+	out := make(chan azureWrapper[models.KeyVaultRoleAssignments])
+
+	go func() {
+		defer close(out)
+
+		for result := range pipeline.OrDone(ctx.Done(), keyVaults) {
+			if keyVault, ok := result.(AzureWrapper).Data.(models.KeyVault); !ok {
+				log.Error(fmt.Errorf("failed type assertion"), "unable to continue enumerating key vault role assignments", "result", result)
+				return
+			} else {
+				keyVaultRoleAssignments := models.KeyVaultRoleAssignments{
+					KeyVaultId: keyVault.Id,
+				}
+				for _, item := range s.roleAssignments(ctx, keyVault.Id, true) {
+					keyVaultRoleAssignments.RoleAssignments = append(keyVaultRoleAssignments.RoleAssignments, models.KeyVaultRoleAssignment{
+						KeyVaultId:     keyVault.Id,
+						RoleAssignment: item,
+					})
+				}
+				out <- NewAzureWrapper(enums.KindAZKeyVaultRoleAssignment, keyVaultRoleAssignments)
+				log.V(1).Info("finished listing key vault role assignments", "keyVaultId", keyVault.Id, "count", len(keyVaultRoleAssignments.RoleAssignments))
+			}
+		}
+		log.Info("finished listing all key vault role assignments")
+	}()
+
+	return out
+}
+
+func (s *resourceGraph) listVirtualMachineRoleAssignments(ctx context.Context, _ client.AzureClient, virtualMachines <-chan interface{}, _ int) <-chan azureWrapper[models.VirtualMachineRoleAssignments] {
+	out := make(chan azureWrapper[models.VirtualMachineRoleAssignments])
+
+	go func() {
+		defer close(out)
+
+		for result := range pipeline.OrDone(ctx.Done(), virtualMachines) {
+			if virtualMachine, ok := result.(AzureWrapper).Data.(models.VirtualMachine); !ok {
+				log.Error(fmt.Errorf("failed type assertion"), "unable to continue enumerating virtual machine role assignments", "result", result)
+				return
+			} else {
+				virtualMachineRoleAssignments := models.VirtualMachineRoleAssignments{
+					VirtualMachineId: virtualMachine.Id,
+				}
+				for _, item := range s.roleAssignments(ctx, virtualMachine.Id, true) {
+					virtualMachineRoleAssignments.RoleAssignments = append(virtualMachineRoleAssignments.RoleAssignments, models.VirtualMachineRoleAssignment{
+						VirtualMachineId: virtualMachine.Id,
+						RoleAssignment:   item,
+					})
+				}
+				out <- NewAzureWrapper(enums.KindAZVMRoleAssignment, virtualMachineRoleAssignments)
+				log.V(1).Info("finished listing virtual machine role assignments", "virtualMachineId", virtualMachine.Id, "count", len(virtualMachineRoleAssignments.RoleAssignments))
+			}
+		}
+		log.Info("finished listing all virtual machine role assignments")
+	}()
+
+	return out
+}
+
+func listResourceGraphResourceGroups(ctx context.Context, client client.AzureClient, subscriptions <-chan interface{}, _ int) <-chan interface{} {
+	return listResourceGraphEntities(ctx, client, subscriptions, resourceGraphResourceGroupsQuery, "resource groups", func(item azure.ResourceGroup, subscriptionId string) AzureWrapper {
+		return AzureWrapper{
+			Kind: enums.KindAZResourceGroup,
+			Data: models.ResourceGroup{
+				ResourceGroup:  item,
+				SubscriptionId: "/subscriptions/" + subscriptionId,
+				TenantId:       client.TenantInfo().TenantId,
+			},
+		}
+	})
+}
+
+func listResourceGraphKeyVaults(ctx context.Context, client client.AzureClient, subscriptions <-chan interface{}, _ int) <-chan interface{} {
+	return listResourceGraphEntities(ctx, client, subscriptions, resourceGraphKeyVaultsQuery, "key vaults", func(item azure.KeyVault, subscriptionId string) AzureWrapper {
+		// the embedded struct's values override top-level properties so TenantId
+		// needs to be explicitly set.
+		return AzureWrapper{
+			Kind: enums.KindAZKeyVault,
+			Data: models.KeyVault{
+				KeyVault:       item,
+				SubscriptionId: subscriptionId,
+				ResourceGroup:  item.ResourceGroupId(),
+				TenantId:       item.Properties.TenantId,
+			},
+		}
+	})
+}
+
+func listResourceGraphVirtualMachines(ctx context.Context, client client.AzureClient, subscriptions <-chan interface{}, _ int) <-chan interface{} {
+	return listResourceGraphEntities(ctx, client, subscriptions, resourceGraphVirtualMachinesQuery, "virtual machines", func(item azure.VirtualMachine, subscriptionId string) AzureWrapper {
+		return AzureWrapper{
+			Kind: enums.KindAZVM,
+			Data: models.VirtualMachine{
+				VirtualMachine:  item,
+				SubscriptionId:  subscriptionId,
+				ResourceGroupId: item.ResourceGroupId(),
+				TenantId:        client.TenantInfo().TenantId,
+			},
+		}
+	})
+}
+
+// listResourceGraphEntities runs a single resource graph query across every subscription and decodes each row into T
+func listResourceGraphEntities[T any](ctx context.Context, client client.AzureClient, subscriptions <-chan interface{}, kql string, description string, wrap func(item T, subscriptionId string) AzureWrapper) <-chan interface{} {
+	out := make(chan interface{})
+
+	go func() {
+		defer close(out)
+
+		var (
+			ids   = collectSubscriptionIds(ctx, subscriptions)
+			count = 0
+		)
+
+		if len(ids) == 0 {
+			return
+		}
+
+		for item := range client.ListResourceGraphQuery(ctx, ids, nil, kql) {
+			var (
+				entity T
+				row    struct {
+					SubscriptionId string `json:"subscriptionId"`
+				}
+			)
+			if item.Error != nil {
+				log.Error(item.Error, "unable to continue processing "+description+" from resource graph")
+			} else if err := json.Unmarshal(item.Ok, &entity); err != nil {
+				log.Error(err, "unable to decode resource graph result", "result", string(item.Ok))
+			} else if err := json.Unmarshal(item.Ok, &row); err != nil {
+				log.Error(err, "unable to decode resource graph result", "result", string(item.Ok))
+			} else {
+				wrapper := wrap(entity, row.SubscriptionId)
+				log.V(2).Info("found resource graph entity", "kind", wrapper.Kind, "data", wrapper.Data)
+				count++
+				out <- wrapper
+			}
+		}
+		log.Info("finished listing all "+description, "count", count)
+	}()
+
+	return out
+}
+
+// collectSubscriptionIds drains the subscriptions stream, returning the id of each subscription
+func collectSubscriptionIds(ctx context.Context, subscriptions <-chan interface{}) []string {
+	var ids []string
+	for result := range pipeline.OrDone(ctx.Done(), subscriptions) {
+		if subscription, ok := result.(AzureWrapper).Data.(models.Subscription); !ok {
+			log.Error(fmt.Errorf("failed type assertion"), "unable to continue collecting subscription ids", "result", result)
+		} else {
+			ids = append(ids, subscription.SubscriptionId)
+		}
+	}
+	return ids
+}
+
+func normalizeScope(scope string) string {
+	if scope = strings.ToLower(strings.TrimSuffix(scope, "/")); scope == "" {
+		return "/"
+	} else {
+		return scope
+	}
+}
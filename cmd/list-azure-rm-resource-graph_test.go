@@ -0,0 +1,151 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/bloodhoundad/azurehound/client/mocks"
+	"github.com/bloodhoundad/azurehound/models"
+	"github.com/bloodhoundad/azurehound/models/azure"
+	"github.com/golang/mock/gomock"
+)
+
+func init() {
+	setupLogger()
+}
+
+func mockResourceGraphResults(rows ...string) <-chan azure.ResourceGraphResult {
+	out := make(chan azure.ResourceGraphResult)
+	go func() {
+		defer close(out)
+		for _, row := range rows {
+			out <- azure.ResourceGraphResult{Ok: json.RawMessage(row)}
+		}
+	}()
+	return out
+}
+
+func TestListResourceGraphKeyVaults(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ctx := context.Background()
+
+	mockClient := mocks.NewMockAzureClient(ctrl)
+	mockSubscriptionsChannel := make(chan interface{})
+	mockResultsChannel := make(chan azure.ResourceGraphResult)
+	mockError := fmt.Errorf("I'm an error")
+	mockClient.EXPECT().ListResourceGraphQuery(gomock.Any(), []string{"foo", "bar"}, gomock.Nil(), resourceGraphKeyVaultsQuery).Return(mockResultsChannel).Times(1)
+	channel := listResourceGraphKeyVaults(ctx, mockClient, mockSubscriptionsChannel, 25)
+
+	go func() {
+		defer close(mockSubscriptionsChannel)
+		mockSubscriptionsChannel <- AzureWrapper{
+			Data: models.Subscription{Subscription: azure.Subscription{SubscriptionId: "foo"}},
+		}
+		mockSubscriptionsChannel <- AzureWrapper{
+			Data: models.Subscription{Subscription: azure.Subscription{SubscriptionId: "bar"}},
+		}
+	}()
+	go func() {
+		defer close(mockResultsChannel)
+		mockResultsChannel <- azure.ResourceGraphResult{
+			Ok: json.RawMessage(`{"id":"/subscriptions/foo/resourceGroups/rg/providers/Microsoft.KeyVault/vaults/kv","subscriptionId":"foo","properties":{"tenantId":"baz"}}`),
+		}
+		mockResultsChannel <- azure.ResourceGraphResult{
+			Error: mockError,
+		}
+	}()
+
+	if result, ok := <-channel; !ok {
+		t.Fatalf("failed to receive from channel")
+	} else if wrapper, ok := result.(AzureWrapper); !ok {
+		t.Errorf("failed type assertion: got %T, want %T", result, AzureWrapper{})
+	} else if keyVault, ok := wrapper.Data.(models.KeyVault); !ok {
+		t.Errorf("failed type assertion: got %T, want %T", wrapper.Data, models.KeyVault{})
+	} else if keyVault.SubscriptionId != "foo" || keyVault.TenantId != "baz" || keyVault.ResourceGroup != "/subscriptions/foo/resourceGroups/rg" {
+		t.Errorf("got %+v, want subscription foo, tenant baz and resource group /subscriptions/foo/resourceGroups/rg", keyVault)
+	}
+
+	if _, ok := <-channel; ok {
+		t.Error("should not have recieved from channel")
+	}
+}
+
+func TestResourceGraphRoleAssignments(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ctx := context.Background()
+
+	mockClient := mocks.NewMockAzureClient(ctrl)
+	mockSubscriptionsChannel := make(chan interface{})
+	mockKeyVaultsChannel := make(chan interface{})
+	mockClient.EXPECT().ListResourceGraphQuery(gomock.Any(), []string{"foo"}, gomock.Nil(), resourceGraphSubscriptionsQuery).Return(mockResourceGraphResults(
+		`{"subscriptionId":"foo","ancestors":[{"name":"child"},{"name":"root"}]}`,
+	)).Times(1)
+	mockClient.EXPECT().ListResourceGraphQuery(gomock.Any(), []string{"foo"}, gomock.Nil(), resourceGraphRoleAssignmentsQuery).Return(mockResourceGraphResults(
+		`{"id":"1","properties":{"scope":"/subscriptions/foo"}}`,
+		`{"id":"2","properties":{"scope":"/subscriptions/foo/resourceGroups/RG"}}`,
+		`{"id":"3","properties":{"scope":"/subscriptions/foo/resourceGroups/rg/providers/Microsoft.KeyVault/vaults/kv"}}`,
+		`{"id":"4","properties":{"scope":"/subscriptions/foo/resourceGroups/rg/providers/Microsoft.KeyVault/vaults/kv/secrets/s"}}`,
+		`{"id":"5","properties":{"scope":"/subscriptions/foo/resourceGroups/rg/providers/Microsoft.KeyVault/vaults/kv2"}}`,
+		`{"id":"6","properties":{"scope":"/subscriptions/foo/resourceGroups/rg2"}}`,
+	)).Times(1)
+	mockClient.EXPECT().ListResourceGraphQuery(gomock.Any(), gomock.Nil(), []string{"root"}, resourceGraphRoleAssignmentsQuery+resourceGraphManagementGroupFilter).Return(mockResourceGraphResults(
+		`{"id":"7","properties":{"scope":"/providers/Microsoft.Management/managementGroups/child"}}`,
+		`{"id":"8","properties":{"scope":"/providers/Microsoft.Management/managementGroups/other"}}`,
+		`{"id":"9","properties":{"scope":"/"}}`,
+	)).Times(1)
+
+	graph := newResourceGraph(ctx, mockClient, mockSubscriptionsChannel)
+	channel := graph.listKeyVaultRoleAssignments(ctx, mockClient, mockKeyVaultsChannel, 25)
+
+	go func() {
+		defer close(mockSubscriptionsChannel)
+		mockSubscriptionsChannel <- AzureWrapper{
+			Data: models.Subscription{Subscription: azure.Subscription{SubscriptionId: "foo"}},
+		}
+	}()
+	go func() {
+		defer close(mockKeyVaultsChannel)
+		mockKeyVaultsChannel <- AzureWrapper{
+			Data: models.KeyVault{KeyVault: azure.KeyVault{Entity: azure.Entity{Id: "/subscriptions/foo/resourceGroups/rg/providers/Microsoft.KeyVault/vaults/kv"}}},
+		}
+	}()
+
+	if result, ok := <-channel; !ok {
+		t.Fatalf("failed to receive from channel")
+	} else {
+		want := map[string]bool{"1": true, "2": true, "3": true, "4": true, "7": true, "9": true}
+		if len(result.Data.RoleAssignments) != len(want) {
+			t.Errorf("got %d role assignments, want %d", len(result.Data.RoleAssignments), len(want))
+		}
+		for _, roleAssignment := range result.Data.RoleAssignments {
+			if !want[roleAssignment.RoleAssignment.Id] {
+				t.Errorf("unexpected role assignment %s", roleAssignment.RoleAssignment.Id)
+			}
+		}
+	}
+
+	if _, ok := <-channel; ok {
+		t.Error("should not have recieved from channel")
+	}
+}
@@ -25,6 +25,7 @@ import (
 	"time"
 
 	"github.com/bloodhoundad/azurehound/client"
+	"github.com/bloodhoundad/azurehound/config"
 	"github.com/bloodhoundad/azurehound/enums"
 	"github.com/bloodhoundad/azurehound/models"
 	"github.com/bloodhoundad/azurehound/pipeline"
@@ -66,6 +67,28 @@ func listAzureRMCmdImpl(cmd *cobra.Command, args []string) {
 	}
 }
 
+// rmCollectors are the sources listAllRM enumerates resource groups, key vaults, virtual machines and the role
+// assignments of those entities and subscriptions from
+type rmCollectors struct {
+	resourceGroups                func(context.Context, client.AzureClient, <-chan interface{}, int) <-chan interface{}
+	keyVaults                     func(context.Context, client.AzureClient, <-chan interface{}, int) <-chan interface{}
+	virtualMachines               func(context.Context, client.AzureClient, <-chan interface{}, int) <-chan interface{}
+	subscriptionRoleAssignments   func(context.Context, client.AzureClient, <-chan interface{}, int) <-chan interface{}
+	resourceGroupRoleAssignments  func(context.Context, client.AzureClient, <-chan interface{}, int) <-chan azureWrapper[models.ResourceGroupRoleAssignments]
+	keyVaultRoleAssignments       func(context.Context, client.AzureClient, <-chan interface{}, int) <-chan azureWrapper[models.KeyVaultRoleAssignments]
+	virtualMachineRoleAssignments func(context.Context, client.AzureClient, <-chan interface{}, int) <-chan azureWrapper[models.VirtualMachineRoleAssignments]
+}
+
+var defaultRMCollectors = rmCollectors{
+	resourceGroups:                listResourceGroups,
+	keyVaults:                     listKeyVaults,
+	virtualMachines:               listVirtualMachines,
+	subscriptionRoleAssignments:   listSubscriptionRoleAssignments,
+	resourceGroupRoleAssignments:  listResourceGroupRoleAssignments,
+	keyVaultRoleAssignments:       listKeyVaultRoleAssignments,
+	virtualMachineRoleAssignments: listVirtualMachineRoleAssignments,
+}
+
 func listAllRM(ctx context.Context, client client.AzureClient) <-chan interface{} {
 	var (
 		keyVaults                = make(chan interface{})
@@ -92,6 +115,8 @@ func listAllRM(ctx context.Context, client client.AzureClient) <-chan interface{
 		subscriptions3               = make(chan interface{})
 		subscriptions4               = make(chan interface{})
 		subscriptions5               = make(chan interface{})
+		subscriptions6               = make(chan interface{})
+		subscriptions7               = make(chan interface{})
 		subscriptionRoleAssignments1 = make(chan interface{})
 		subscriptionRoleAssignments2 = make(chan interface{})
 
@@ -102,34 +127,46 @@ func listAllRM(ctx context.Context, client client.AzureClient) <-chan interface{
 		virtualMachineRoleAssignments3 = make(chan azureWrapper[models.VirtualMachineRoleAssignments])
 		virtualMachineRoleAssignments4 = make(chan azureWrapper[models.VirtualMachineRoleAssignments])
 		virtualMachineRoleAssignments5 = make(chan azureWrapper[models.VirtualMachineRoleAssignments])
+
+		includeResources, _ = config.IncludeResources.Value().(bool)
+		useResourceGraph, _ = config.ResourceGraph.Value().(bool)
+		collectors          = defaultRMCollectors
 	)
 
 	// Enumerate entities
 	pipeline.Tee(ctx.Done(), listManagementGroups(ctx, client), mgmtGroups, mgmtGroups2, mgmtGroups3)
-	pipeline.Tee(ctx.Done(), listSubscriptions(ctx, client), subscriptions, subscriptions2, subscriptions3, subscriptions4, subscriptions5)
-	pipeline.Tee(ctx.Done(), listResourceGroups(ctx, client, subscriptions2), resourceGroups, resourceGroups2)
-	pipeline.Tee(ctx.Done(), listKeyVaults(ctx, client, subscriptions3), keyVaults, keyVaults2, keyVaults3)
-	pipeline.Tee(ctx.Done(), listVirtualMachines(ctx, client, subscriptions4), virtualMachines, virtualMachines2)
+	subscriptionOutputs := []chan<- interface{}{subscriptions, subscriptions2, subscriptions3, subscriptions4, subscriptions5}
+	if includeResources {
+		subscriptionOutputs = append(subscriptionOutputs, subscriptions6)
+	}
+	if useResourceGraph {
+		subscriptionOutputs = append(subscriptionOutputs, subscriptions7)
+		collectors = newResourceGraph(ctx, client, subscriptions7).collectors()
+	}
+	pipeline.Tee(ctx.Done(), listSubscriptions(ctx, client), subscriptionOutputs...)
+	pipeline.Tee(ctx.Done(), collectors.resourceGroups(ctx, client, subscriptions2, collectorConcurrency("resource-groups")), resourceGroups, resourceGroups2)
+	pipeline.Tee(ctx.Done(), collectors.keyVaults(ctx, client, subscriptions3, collectorConcurrency("key-vaults")), keyVaults, keyVaults2, keyVaults3)
+	pipeline.Tee(ctx.Done(), collectors.virtualMachines(ctx, client, subscriptions4, collectorConcurrency("virtual-machines")), virtualMachines, virtualMachines2)
 
 	// Enumerate Relationships
 	// ManagementGroups: Descendants, Owners and UserAccessAdmins
-	mgmtGroupDescendants := listManagementGroupDescendants(ctx, client, mgmtGroups2)
-	pipeline.Tee(ctx.Done(), listManagementGroupRoleAssignments(ctx, client, mgmtGroups3), mgmtGroupRoleAssignments1, mgmtGroupRoleAssignments2)
+	mgmtGroupDescendants := listManagementGroupDescendants(ctx, client, mgmtGroups2, collectorConcurrency("management-group-descendants"))
+	pipeline.Tee(ctx.Done(), listManagementGroupRoleAssignments(ctx, client, mgmtGroups3, collectorConcurrency("management-group-role-assignments")), mgmtGroupRoleAssignments1, mgmtGroupRoleAssignments2)
 	mgmtGroupOwners := listManagementGroupOwners(ctx, mgmtGroupRoleAssignments1)
 	mgmtGroupUserAccessAdmins := listManagementGroupUserAccessAdmins(ctx, mgmtGroupRoleAssignments2)
 
 	// Subscriptions: Owners and UserAccessAdmins
-	pipeline.Tee(ctx.Done(), listSubscriptionRoleAssignments(ctx, client, subscriptions5), subscriptionRoleAssignments1, subscriptionRoleAssignments2)
+	pipeline.Tee(ctx.Done(), collectors.subscriptionRoleAssignments(ctx, client, subscriptions5, collectorConcurrency("subscription-role-assignments")), subscriptionRoleAssignments1, subscriptionRoleAssignments2)
 	subscriptionOwners := listSubscriptionOwners(ctx, client, subscriptionRoleAssignments1)
 	subscriptionUserAccessAdmins := listSubscriptionUserAccessAdmins(ctx, client, subscriptionRoleAssignments2)
 
 	// ResourceGroups: Owners and UserAccessAdmins
-	pipeline.Tee(ctx.Done(), listResourceGroupRoleAssignments(ctx, client, resourceGroups2), resourceGroupRoleAssignments1, resourceGroupRoleAssignments2)
+	pipeline.Tee(ctx.Done(), collectors.resourceGroupRoleAssignments(ctx, client, resourceGroups2, collectorConcurrency("resource-group-role-assignments")), resourceGroupRoleAssignments1, resourceGroupRoleAssignments2)
 	resourceGroupOwners := listResourceGroupOwners(ctx, resourceGroupRoleAssignments1)
 	resourceGroupUserAccessAdmins := listResourceGroupUserAccessAdmins(ctx, resourceGroupRoleAssignments2)
 
 	// KeyVaults: AccessPolicies, Owners, UserAccessAdmins, Contributors and KVContributors
-	pipeline.Tee(ctx.Done(), listKeyVaultRoleAssignments(ctx, client, keyVaults2), keyVaultRoleAssignments1, keyVaultRoleAssignments2, keyVaultRoleAssignments3, keyVaultRoleAssignments4)
+	pipeline.Tee(ctx.Done(), collectors.keyVaultRoleAssignments(ctx, client, keyVaults2, collectorConcurrency("key-vault-role-assignments")), keyVaultRoleAssignments1, keyVaultRoleAssignments2, keyVaultRoleAssignments3, keyVaultRoleAssignments4)
 	keyVaultAccessPolicies := listKeyVaultAccessPolicies(ctx, client, keyVaults3, []enums.KeyVaultAccessType{enums.GetCerts, enums.GetKeys, enums.GetCerts})
 	keyVaultOwners := listKeyVaultOwners(ctx, keyVaultRoleAssignments1)
 	keyVaultUserAccessAdmins := listKeyVaultUserAccessAdmins(ctx, keyVaultRoleAssignments2)
@@ -137,14 +174,14 @@ func listAllRM(ctx context.Context, client client.AzureClient) <-chan interface{
 	keyVaultKVContributors := listKeyVaultKVContributors(ctx, keyVaultRoleAssignments4)
 
 	// VirtualMachines: Owners, AvereContributors, Contributors, AdminLogins and UserAccessAdmins
-	pipeline.Tee(ctx.Done(), listVirtualMachineRoleAssignments(ctx, client, virtualMachines2), virtualMachineRoleAssignments1, virtualMachineRoleAssignments2, virtualMachineRoleAssignments3, virtualMachineRoleAssignments4, virtualMachineRoleAssignments5)
+	pipeline.Tee(ctx.Done(), collectors.virtualMachineRoleAssignments(ctx, client, virtualMachines2, collectorConcurrency("virtual-machine-role-assignments")), virtualMachineRoleAssignments1, virtualMachineRoleAssignments2, virtualMachineRoleAssignments3, virtualMachineRoleAssignments4, virtualMachineRoleAssignments5)
 	virtualMachineOwners := listVirtualMachineOwners(ctx, virtualMachineRoleAssignments1)
 	virtualMachineAvereContributors := listVirtualMachineAvereContributors(ctx, virtualMachineRoleAssignments2)
 	virtualMachineContributors := listVirtualMachineContributors(ctx, virtualMachineRoleAssignments3)
 	virtualMachineAdminLogins := listVirtualMachineAdminLogins(ctx, virtualMachineRoleAssignments4)
 	virtualMachineUserAccessAdmins := listVirtualMachineUserAccessAdmins(ctx, virtualMachineRoleAssignments5)
 
-	return pipeline.Mux(ctx.Done(),
+	streams := []<-chan interface{}{
 		keyVaultAccessPolicies,
 		keyVaultContributors,
 		keyVaultKVContributors,
@@ -167,5 +204,16 @@ func listAllRM(ctx context.Context, client client.AzureClient) <-chan interface{
 		virtualMachineOwners,
 		virtualMachineUserAccessAdmins,
 		virtualMachines,
-	)
+	}
+
+	// Generic Resources: Resources and RoleAssignments for the configured resource types
+	if includeResources {
+		resources := make(chan interface{})
+		resources2 := make(chan interface{})
+		pipeline.Tee(ctx.Done(), listResources(ctx, client, subscriptions6, collectorConcurrency("resources")), resources, resources2)
+		resourceRoleAssignments := listResourceRoleAssignments(ctx, client, resources2, config.ResourceTypes.Value().([]string), collectorConcurrency("resource-role-assignments"))
+		streams = append(streams, resources, resourceRoleAssignments)
+	}
+
+	return pipeline.Mux(ctx.Done(), streams...)
 }
@@ -0,0 +1,195 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/bloodhoundad/azurehound/client"
+	"github.com/bloodhoundad/azurehound/enums"
+	"github.com/bloodhoundad/azurehound/models"
+	"github.com/bloodhoundad/azurehound/pipeline"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	listRootCmd.AddCommand(listDeletedObjectsCmd)
+}
+
+var listDeletedObjectsCmd = &cobra.Command{
+	Use:          "deleted-objects",
+	Long:         "Lists soft-deleted Azure Active Directory Users, Groups, Applications and Service Principals",
+	Run:          listDeletedObjectsCmdImpl,
+	SilenceUsage: true,
+}
+
+func listDeletedObjectsCmdImpl(cmd *cobra.Command, args []string) {
+	if len(args) > 0 {
+		exit(fmt.Errorf("unsupported subcommand: %v", args))
+	}
+
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, os.Kill)
+	defer gracefulShutdown(stop)
+
+	log.V(1).Info("testing connections")
+	if err := testConnections(); err != nil {
+		exit(err)
+	} else if azClient, err := newAzureClient(); err != nil {
+		exit(err)
+	} else {
+		log.Info("collecting azure active directory deleted objects...")
+		start := time.Now()
+		stream := listDeletedObjects(ctx, azClient)
+		outputStream(ctx, stream)
+		duration := time.Since(start)
+		log.Info("collection completed", "duration", duration.String())
+	}
+}
+
+func listDeletedObjects(ctx context.Context, client client.AzureClient) <-chan interface{} {
+	return pipeline.Mux(ctx.Done(),
+		listDeletedApps(ctx, client),
+		listDeletedGroups(ctx, client),
+		listDeletedServicePrincipals(ctx, client),
+		listDeletedUsers(ctx, client),
+	)
+}
+
+func listDeletedApps(ctx context.Context, client client.AzureClient) <-chan interface{} {
+	out := make(chan interface{})
+
+	go func() {
+		defer close(out)
+		count := 0
+		for item := range client.ListAzureADDeletedApps(ctx, "", "", "", nil) {
+			if item.Error != nil {
+				log.Error(item.Error, "unable to continue processing deleted applications")
+				return
+			} else {
+				log.V(2).Info("found deleted application", "app", item)
+				count++
+				out <- AzureWrapper{
+					Kind: enums.KindAZApp,
+					Data: models.App{
+						Application: item.Ok,
+						TenantId:    client.TenantInfo().TenantId,
+						TenantName:  client.TenantInfo().DisplayName,
+						Deleted:     true,
+					},
+				}
+			}
+		}
+		log.Info("finished listing all deleted apps", "count", count)
+	}()
+
+	return out
+}
+
+func listDeletedGroups(ctx context.Context, client client.AzureClient) <-chan interface{} {
+	out := make(chan interface{})
+
+	go func() {
+		defer close(out)
+		count := 0
+		for item := range client.ListAzureADDeletedGroups(ctx, "", "", "", nil) {
+			if item.Error != nil {
+				log.Error(item.Error, "unable to continue processing deleted groups")
+				return
+			} else {
+				log.V(2).Info("found deleted group", "group", item)
+				count++
+				out <- AzureWrapper{
+					Kind: enums.KindAZGroup,
+					Data: models.Group{
+						Group:      item.Ok,
+						TenantId:   client.TenantInfo().TenantId,
+						TenantName: client.TenantInfo().DisplayName,
+						Deleted:    true,
+					},
+				}
+			}
+		}
+		log.Info("finished listing all deleted groups", "count", count)
+	}()
+
+	return out
+}
+
+func listDeletedServicePrincipals(ctx context.Context, client client.AzureClient) <-chan interface{} {
+	out := make(chan interface{})
+
+	go func() {
+		defer close(out)
+		count := 0
+		for item := range client.ListAzureADDeletedServicePrincipals(ctx, "", "", "", nil) {
+			if item.Error != nil {
+				log.Error(item.Error, "unable to continue processing deleted service principals")
+				return
+			} else {
+				log.V(2).Info("found deleted service principal", "servicePrincipal", item)
+				count++
+				out <- AzureWrapper{
+					Kind: enums.KindAZServicePrincipal,
+					Data: models.ServicePrincipal{
+						ServicePrincipal: item.Ok,
+						TenantId:         client.TenantInfo().TenantId,
+						TenantName:       client.TenantInfo().DisplayName,
+						Deleted:          true,
+					},
+				}
+			}
+		}
+		log.Info("finished listing all deleted service principals", "count", count)
+	}()
+
+	return out
+}
+
+func listDeletedUsers(ctx context.Context, client client.AzureClient) <-chan interface{} {
+	out := make(chan interface{})
+
+	go func() {
+		defer close(out)
+		count := 0
+		for item := range client.ListAzureADDeletedUsers(ctx, "", "", "", nil) {
+			if item.Error != nil {
+				log.Error(item.Error, "unable to continue processing deleted users")
+				return
+			} else {
+				log.V(2).Info("found deleted user", "user", item)
+				count++
+				out <- AzureWrapper{
+					Kind: enums.KindAZUser,
+					Data: models.User{
+						User:       item.Ok,
+						TenantId:   client.TenantInfo().TenantId,
+						TenantName: client.TenantInfo().DisplayName,
+						Deleted:    true,
+					},
+				}
+			}
+		}
+		log.Info("finished listing all deleted users", "count", count)
+	}()
+
+	return out
+}
@@ -0,0 +1,104 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/bloodhoundad/azurehound/client/mocks"
+	"github.com/bloodhoundad/azurehound/models"
+	"github.com/bloodhoundad/azurehound/models/azure"
+	"github.com/golang/mock/gomock"
+)
+
+func init() {
+	setupLogger()
+}
+
+func TestListDeletedObjects(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ctx := context.Background()
+
+	mockClient := mocks.NewMockAzureClient(ctrl)
+	mockAppsChannel := make(chan azure.ApplicationResult)
+	mockGroupsChannel := make(chan azure.GroupResult)
+	mockServicePrincipalsChannel := make(chan azure.ServicePrincipalResult)
+	mockUsersChannel := make(chan azure.UserResult)
+	mockTenant := azure.Tenant{}
+	mockError := fmt.Errorf("I'm an error")
+	mockClient.EXPECT().TenantInfo().Return(mockTenant).AnyTimes()
+	mockClient.EXPECT().ListAzureADDeletedApps(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(mockAppsChannel)
+	mockClient.EXPECT().ListAzureADDeletedGroups(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(mockGroupsChannel)
+	mockClient.EXPECT().ListAzureADDeletedServicePrincipals(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(mockServicePrincipalsChannel)
+	mockClient.EXPECT().ListAzureADDeletedUsers(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(mockUsersChannel)
+
+	go func() {
+		defer close(mockAppsChannel)
+		mockAppsChannel <- azure.ApplicationResult{Ok: azure.Application{}}
+	}()
+	go func() {
+		defer close(mockGroupsChannel)
+		mockGroupsChannel <- azure.GroupResult{Ok: azure.Group{}}
+	}()
+	go func() {
+		defer close(mockServicePrincipalsChannel)
+		mockServicePrincipalsChannel <- azure.ServicePrincipalResult{Ok: azure.ServicePrincipal{}}
+	}()
+	go func() {
+		defer close(mockUsersChannel)
+		mockUsersChannel <- azure.UserResult{Ok: azure.User{}}
+		mockUsersChannel <- azure.UserResult{Error: mockError}
+		mockUsersChannel <- azure.UserResult{Ok: azure.User{}}
+	}()
+
+	count := 0
+	for result := range listDeletedObjects(ctx, mockClient) {
+		count++
+		if wrapper, ok := result.(AzureWrapper); !ok {
+			t.Errorf("failed type assertion: got %T, want %T", result, AzureWrapper{})
+		} else {
+			switch data := wrapper.Data.(type) {
+			case models.App:
+				if !data.Deleted {
+					t.Error("expected deleted app to be marked as deleted")
+				}
+			case models.Group:
+				if !data.Deleted {
+					t.Error("expected deleted group to be marked as deleted")
+				}
+			case models.ServicePrincipal:
+				if !data.Deleted {
+					t.Error("expected deleted service principal to be marked as deleted")
+				}
+			case models.User:
+				if !data.Deleted {
+					t.Error("expected deleted user to be marked as deleted")
+				}
+			default:
+				t.Errorf("unexpected type: %T", data)
+			}
+		}
+	}
+
+	if count != 4 {
+		t.Errorf("got %v, want %v", count, 4)
+	}
+}
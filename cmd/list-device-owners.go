@@ -55,18 +55,18 @@ func listDeviceOwnersCmdImpl(cmd *cobra.Command, args []string) {
 	} else {
 		log.Info("collecting azure device owners...")
 		start := time.Now()
-		stream := listDeviceOwners(ctx, azClient, listDevices(ctx, azClient))
+		stream := listDeviceOwners(ctx, azClient, listDevices(ctx, azClient), collectorConcurrency("device-owners"))
 		outputStream(ctx, stream)
 		duration := time.Since(start)
 		log.Info("collection completed", "duration", duration.String())
 	}
 }
 
-func listDeviceOwners(ctx context.Context, client client.AzureClient, devices <-chan interface{}) <-chan interface{} {
+func listDeviceOwners(ctx context.Context, client client.AzureClient, devices <-chan interface{}, concurrency int) <-chan interface{} {
 	var (
 		out     = make(chan interface{})
 		ids     = make(chan string)
-		streams = pipeline.Demux(ctx.Done(), ids, 25)
+		streams = pipeline.Demux(ctx.Done(), ids, concurrency)
 		wg      sync.WaitGroup
 	)
 
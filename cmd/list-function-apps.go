@@ -55,18 +55,18 @@ func listFunctionAppsCmdImpl(cmd *cobra.Command, args []string) {
 	} else {
 		log.Info("collecting azure function apps...")
 		start := time.Now()
-		stream := listFunctionApps(ctx, azClient, listSubscriptions(ctx, azClient))
+		stream := listFunctionApps(ctx, azClient, listSubscriptions(ctx, azClient), collectorConcurrency("function-apps"))
 		outputStream(ctx, stream)
 		duration := time.Since(start)
 		log.Info("collection completed", "duration", duration.String())
 	}
 }
 
-func listFunctionApps(ctx context.Context, client client.AzureClient, subscriptions <-chan interface{}) <-chan interface{} {
+func listFunctionApps(ctx context.Context, client client.AzureClient, subscriptions <-chan interface{}, concurrency int) <-chan interface{} {
 	var (
 		out     = make(chan interface{})
 		ids     = make(chan string)
-		streams = pipeline.Demux(ctx.Done(), ids, 25)
+		streams = pipeline.Demux(ctx.Done(), ids, concurrency)
 		wg      sync.WaitGroup
 	)
 
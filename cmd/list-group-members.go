@@ -55,18 +55,18 @@ func listGroupMembersCmdImpl(cmd *cobra.Command, args []string) {
 	} else {
 		log.Info("collecting azure group members...")
 		start := time.Now()
-		stream := listGroupMembers(ctx, azClient, listGroups(ctx, azClient))
+		stream := listGroupMembers(ctx, azClient, listGroups(ctx, azClient), collectorConcurrency("group-members"))
 		outputStream(ctx, stream)
 		duration := time.Since(start)
 		log.Info("collection completed", "duration", duration.String())
 	}
 }
 
-func listGroupMembers(ctx context.Context, client client.AzureClient, groups <-chan interface{}) <-chan interface{} {
+func listGroupMembers(ctx context.Context, client client.AzureClient, groups <-chan interface{}, concurrency int) <-chan interface{} {
 	var (
 		out     = make(chan interface{})
 		ids     = make(chan string)
-		streams = pipeline.Demux(ctx.Done(), ids, 25)
+		streams = pipeline.Demux(ctx.Done(), ids, concurrency)
 		wg      sync.WaitGroup
 	)
 
@@ -55,18 +55,18 @@ func listGroupOwnersCmdImpl(cmd *cobra.Command, args []string) {
 	} else {
 		log.Info("collecting azure group owners...")
 		start := time.Now()
-		stream := listGroupOwners(ctx, azClient, listGroups(ctx, azClient))
+		stream := listGroupOwners(ctx, azClient, listGroups(ctx, azClient), collectorConcurrency("group-owners"))
 		outputStream(ctx, stream)
 		duration := time.Since(start)
 		log.Info("collection completed", "duration", duration.String())
 	}
 }
 
-func listGroupOwners(ctx context.Context, client client.AzureClient, groups <-chan interface{}) <-chan interface{} {
+func listGroupOwners(ctx context.Context, client client.AzureClient, groups <-chan interface{}, concurrency int) <-chan interface{} {
 	var (
 		out     = make(chan interface{})
 		ids     = make(chan string)
-		streams = pipeline.Demux(ctx.Done(), ids, 25)
+		streams = pipeline.Demux(ctx.Done(), ids, concurrency)
 		wg      sync.WaitGroup
 	)
 
@@ -24,8 +24,11 @@ import (
 	"time"
 
 	"github.com/bloodhoundad/azurehound/client"
+	"github.com/bloodhoundad/azurehound/config"
 	"github.com/bloodhoundad/azurehound/enums"
 	"github.com/bloodhoundad/azurehound/models"
+	"github.com/bloodhoundad/azurehound/models/azure"
+	"github.com/bloodhoundad/azurehound/pipeline"
 	"github.com/spf13/cobra"
 )
 
@@ -60,6 +63,10 @@ func listGroupsCmdImpl(cmd *cobra.Command, args []string) {
 }
 
 func listGroups(ctx context.Context, client client.AzureClient) <-chan interface{} {
+	if statePath, _ := config.DeltaState.Value().(string); statePath != "" {
+		return listGroupsDelta(ctx, client, statePath)
+	}
+
 	out := make(chan interface{})
 
 	go func() {
@@ -88,3 +95,25 @@ func listGroups(ctx context.Context, client client.AzureClient) <-chan interface
 
 	return out
 }
+
+func listGroupsDelta(ctx context.Context, client client.AzureClient, statePath string) <-chan interface{} {
+	list := func(deltaLink string) <-chan deltaItem[azure.Group] {
+		return pipeline.Map(ctx.Done(), client.ListAzureADGroupsDelta(ctx, deltaLink, nil), func(item azure.GroupResult) deltaItem[azure.Group] {
+			return deltaItem[azure.Group]{Error: item.Error, Ok: item.Ok, DeltaLink: item.DeltaLink}
+		})
+	}
+
+	return listDelta(ctx, client, statePath, "groups", list, func(item azure.Group, incremental bool) (AzureWrapper, bool) {
+		// group delta queries can't be filtered on securityEnabled and changes only carry the properties that changed,
+		// so only a new round of the query can be narrowed down to security groups
+		return AzureWrapper{
+			Kind: enums.KindAZGroup,
+			Data: models.Group{
+				Group:      item,
+				TenantId:   client.TenantInfo().TenantId,
+				TenantName: client.TenantInfo().DisplayName,
+				Delta:      deltaChange(item.DirectoryObject, incremental),
+			},
+		}, incremental || item.SecurityEnabled
+	})
+}
@@ -64,7 +64,7 @@ func listKeyVaultAccessPoliciesCmdImpl(cmd *cobra.Command, args []string) {
 			if len(filters) > 0 {
 				log.Info("applying access type filters", "filters", filters)
 			}
-			stream := listKeyVaultAccessPolicies(ctx, azClient, listKeyVaults(ctx, azClient, subscriptions), filters)
+			stream := listKeyVaultAccessPolicies(ctx, azClient, listKeyVaults(ctx, azClient, subscriptions, collectorConcurrency("key-vaults")), filters)
 			outputStream(ctx, stream)
 			duration := time.Since(start)
 			log.Info("collection completed", "duration", duration.String())
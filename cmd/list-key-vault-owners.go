@@ -55,8 +55,8 @@ func listKeyVaultOwnersCmdImpl(cmd *cobra.Command, args []string) {
 		log.Info("collecting azure key vault owners...")
 		start := time.Now()
 		subscriptions := listSubscriptions(ctx, azClient)
-		keyVaults := listKeyVaults(ctx, azClient, subscriptions)
-		kvRoleAssignments := listKeyVaultRoleAssignments(ctx, azClient, keyVaults)
+		keyVaults := listKeyVaults(ctx, azClient, subscriptions, collectorConcurrency("key-vaults"))
+		kvRoleAssignments := listKeyVaultRoleAssignments(ctx, azClient, keyVaults, collectorConcurrency("key-vault-role-assignments"))
 		stream := listKeyVaultOwners(ctx, kvRoleAssignments)
 		outputStream(ctx, stream)
 		duration := time.Since(start)
@@ -49,7 +49,7 @@ func TestListKeyVaultRoleAssignments(t *testing.T) {
 	mockClient.EXPECT().TenantInfo().Return(mockTenant).AnyTimes()
 	mockClient.EXPECT().ListRoleAssignmentsForResource(gomock.Any(), gomock.Any(), gomock.Any()).Return(mockKeyVaultRoleAssignmentChannel).Times(1)
 	mockClient.EXPECT().ListRoleAssignmentsForResource(gomock.Any(), gomock.Any(), gomock.Any()).Return(mockKeyVaultRoleAssignmentChannel2).Times(1)
-	channel := listKeyVaultRoleAssignments(ctx, mockClient, mockKeyVaultsChannel)
+	channel := listKeyVaultRoleAssignments(ctx, mockClient, mockKeyVaultsChannel, 25)
 
 	go func() {
 		defer close(mockKeyVaultsChannel)
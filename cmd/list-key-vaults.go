@@ -55,18 +55,18 @@ func listKeyVaultsCmdImpl(cmd *cobra.Command, args []string) {
 	} else {
 		log.Info("collecting azure key vaults...")
 		start := time.Now()
-		stream := listKeyVaults(ctx, azClient, listSubscriptions(ctx, azClient))
+		stream := listKeyVaults(ctx, azClient, listSubscriptions(ctx, azClient), collectorConcurrency("key-vaults"))
 		outputStream(ctx, stream)
 		duration := time.Since(start)
 		log.Info("collection completed", "duration", duration.String())
 	}
 }
 
-func listKeyVaults(ctx context.Context, client client.AzureClient, subscriptions <-chan interface{}) <-chan interface{} {
+func listKeyVaults(ctx context.Context, client client.AzureClient, subscriptions <-chan interface{}, concurrency int) <-chan interface{} {
 	var (
 		out     = make(chan interface{})
 		ids     = make(chan string)
-		streams = pipeline.Demux(ctx.Done(), ids, 25)
+		streams = pipeline.Demux(ctx.Done(), ids, concurrency)
 		wg      sync.WaitGroup
 	)
 
@@ -55,18 +55,18 @@ func listManagementGroupDescendantsCmdImpl(cmd *cobra.Command, args []string) {
 	} else {
 		log.Info("collecting azure management group descendants...")
 		start := time.Now()
-		stream := listManagementGroupDescendants(ctx, azClient, listManagementGroups(ctx, azClient))
+		stream := listManagementGroupDescendants(ctx, azClient, listManagementGroups(ctx, azClient), collectorConcurrency("management-group-descendants"))
 		outputStream(ctx, stream)
 		duration := time.Since(start)
 		log.Info("collection completed", "duration", duration.String())
 	}
 }
 
-func listManagementGroupDescendants(ctx context.Context, client client.AzureClient, managementGroups <-chan interface{}) <-chan interface{} {
+func listManagementGroupDescendants(ctx context.Context, client client.AzureClient, managementGroups <-chan interface{}, concurrency int) <-chan interface{} {
 	var (
 		out     = make(chan interface{})
 		ids     = make(chan string)
-		streams = pipeline.Demux(ctx.Done(), ids, 25)
+		streams = pipeline.Demux(ctx.Done(), ids, concurrency)
 		wg      sync.WaitGroup
 	)
 
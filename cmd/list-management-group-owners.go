@@ -55,7 +55,7 @@ func listManagementGroupOwnersCmdImpl(cmd *cobra.Command, args []string) {
 		log.Info("collecting azure management group owners...")
 		start := time.Now()
 		managementGroups := listManagementGroups(ctx, azClient)
-		roleAssignments := listManagementGroupRoleAssignments(ctx, azClient, managementGroups)
+		roleAssignments := listManagementGroupRoleAssignments(ctx, azClient, managementGroups, collectorConcurrency("management-group-role-assignments"))
 		stream := listManagementGroupOwners(ctx, roleAssignments)
 		outputStream(ctx, stream)
 		duration := time.Since(start)
@@ -56,18 +56,18 @@ func listManagementGroupRoleAssignmentsCmdImpl(cmd *cobra.Command, args []string
 		log.Info("collecting azure management group role assignments...")
 		start := time.Now()
 		managementGroups := listManagementGroups(ctx, azClient)
-		stream := listManagementGroupRoleAssignments(ctx, azClient, managementGroups)
+		stream := listManagementGroupRoleAssignments(ctx, azClient, managementGroups, collectorConcurrency("management-group-role-assignments"))
 		outputStream(ctx, stream)
 		duration := time.Since(start)
 		log.Info("collection completed", "duration", duration.String())
 	}
 }
 
-func listManagementGroupRoleAssignments(ctx context.Context, client client.AzureClient, managementGroups <-chan interface{}) <-chan azureWrapper[models.ManagementGroupRoleAssignments] {
+func listManagementGroupRoleAssignments(ctx context.Context, client client.AzureClient, managementGroups <-chan interface{}, concurrency int) <-chan azureWrapper[models.ManagementGroupRoleAssignments] {
 	var (
 		out     = make(chan azureWrapper[models.ManagementGroupRoleAssignments])
 		ids     = make(chan string)
-		streams = pipeline.Demux(ctx.Done(), ids, 25)
+		streams = pipeline.Demux(ctx.Done(), ids, concurrency)
 		wg      sync.WaitGroup
 	)
 
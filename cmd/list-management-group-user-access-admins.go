@@ -55,7 +55,7 @@ func listManagementGroupUserAccessAdminsCmdImpl(cmd *cobra.Command, args []strin
 		log.Info("collecting azure management group user access admins...")
 		start := time.Now()
 		managementGroups := listManagementGroups(ctx, azClient)
-		roleAssignments := listManagementGroupRoleAssignments(ctx, azClient, managementGroups)
+		roleAssignments := listManagementGroupRoleAssignments(ctx, azClient, managementGroups, collectorConcurrency("management-group-role-assignments"))
 		stream := listManagementGroupUserAccessAdmins(ctx, roleAssignments)
 		outputStream(ctx, stream)
 		duration := time.Since(start)
@@ -55,8 +55,8 @@ func listResourceGroupOwnersCmdImpl(cmd *cobra.Command, args []string) {
 		log.Info("collecting azure resource group owners...")
 		start := time.Now()
 		subscriptions := listSubscriptions(ctx, azClient)
-		resourceGroups := listResourceGroups(ctx, azClient, subscriptions)
-		roleAssignments := listResourceGroupRoleAssignments(ctx, azClient, resourceGroups)
+		resourceGroups := listResourceGroups(ctx, azClient, subscriptions, collectorConcurrency("resource-groups"))
+		roleAssignments := listResourceGroupRoleAssignments(ctx, azClient, resourceGroups, collectorConcurrency("resource-group-role-assignments"))
 		stream := listResourceGroupOwners(ctx, roleAssignments)
 		outputStream(ctx, stream)
 		duration := time.Since(start)
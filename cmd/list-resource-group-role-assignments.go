@@ -56,19 +56,19 @@ func listResourceGroupRoleAssignmentsCmdImpl(cmd *cobra.Command, args []string)
 		log.Info("collecting azure resource group role assignments...")
 		start := time.Now()
 		subscriptions := listSubscriptions(ctx, azClient)
-		resourceGroups := listResourceGroups(ctx, azClient, subscriptions)
-		stream := listResourceGroupRoleAssignments(ctx, azClient, resourceGroups)
+		resourceGroups := listResourceGroups(ctx, azClient, subscriptions, collectorConcurrency("resource-groups"))
+		stream := listResourceGroupRoleAssignments(ctx, azClient, resourceGroups, collectorConcurrency("resource-group-role-assignments"))
 		outputStream(ctx, stream)
 		duration := time.Since(start)
 		log.Info("collection completed", "duration", duration.String())
 	}
 }
 
-func listResourceGroupRoleAssignments(ctx context.Context, client client.AzureClient, resourceGroups <-chan interface{}) <-chan azureWrapper[models.ResourceGroupRoleAssignments] {
+func listResourceGroupRoleAssignments(ctx context.Context, client client.AzureClient, resourceGroups <-chan interface{}, concurrency int) <-chan azureWrapper[models.ResourceGroupRoleAssignments] {
 	var (
 		out     = make(chan azureWrapper[models.ResourceGroupRoleAssignments])
 		ids     = make(chan string)
-		streams = pipeline.Demux(ctx.Done(), ids, 25)
+		streams = pipeline.Demux(ctx.Done(), ids, concurrency)
 		wg      sync.WaitGroup
 	)
 
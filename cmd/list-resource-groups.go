@@ -55,18 +55,18 @@ func listResourceGroupsCmdImpl(cmd *cobra.Command, args []string) {
 	} else {
 		log.Info("collecting azure resource groups...")
 		start := time.Now()
-		stream := listResourceGroups(ctx, azClient, listSubscriptions(ctx, azClient))
+		stream := listResourceGroups(ctx, azClient, listSubscriptions(ctx, azClient), collectorConcurrency("resource-groups"))
 		outputStream(ctx, stream)
 		duration := time.Since(start)
 		log.Info("collection completed", "duration", duration.String())
 	}
 }
 
-func listResourceGroups(ctx context.Context, client client.AzureClient, subscriptions <-chan interface{}) <-chan interface{} {
+func listResourceGroups(ctx context.Context, client client.AzureClient, subscriptions <-chan interface{}, concurrency int) <-chan interface{} {
 	var (
 		out     = make(chan interface{})
 		ids     = make(chan string)
-		streams = pipeline.Demux(ctx.Done(), ids, 25)
+		streams = pipeline.Demux(ctx.Done(), ids, concurrency)
 		wg      sync.WaitGroup
 	)
 
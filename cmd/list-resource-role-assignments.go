@@ -0,0 +1,140 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bloodhoundad/azurehound/client"
+	"github.com/bloodhoundad/azurehound/config"
+	"github.com/bloodhoundad/azurehound/enums"
+	"github.com/bloodhoundad/azurehound/models"
+	"github.com/bloodhoundad/azurehound/pipeline"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	listRootCmd.AddCommand(listResourceRoleAssignmentsCmd)
+}
+
+var listResourceRoleAssignmentsCmd = &cobra.Command{
+	Use:          "resource-role-assignments",
+	Long:         "Lists Azure Resource Role Assignments for the configured resource types",
+	Run:          listResourceRoleAssignmentsCmdImpl,
+	SilenceUsage: true,
+}
+
+func listResourceRoleAssignmentsCmdImpl(cmd *cobra.Command, args []string) {
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, os.Kill)
+	defer gracefulShutdown(stop)
+
+	log.V(1).Info("testing connections")
+	if err := testConnections(); err != nil {
+		exit(err)
+	} else if azClient, err := newAzureClient(); err != nil {
+		exit(err)
+	} else {
+		log.Info("collecting azure resource role assignments...")
+		start := time.Now()
+		subscriptions := listSubscriptions(ctx, azClient)
+		resourceTypes := config.ResourceTypes.Value().([]string)
+		stream := listResourceRoleAssignments(ctx, azClient, listResources(ctx, azClient, subscriptions, collectorConcurrency("resources")), resourceTypes, collectorConcurrency("resource-role-assignments"))
+		outputStream(ctx, stream)
+		duration := time.Since(start)
+		log.Info("collection completed", "duration", duration.String())
+	}
+}
+
+func listResourceRoleAssignments(ctx context.Context, client client.AzureClient, resources <-chan interface{}, resourceTypes []string, concurrency int) <-chan interface{} {
+	var (
+		out      = make(chan interface{})
+		filtered = make(chan models.Resource)
+		streams  = pipeline.Demux(ctx.Done(), filtered, concurrency)
+		wg       sync.WaitGroup
+		isWanted = func(resourceType string) bool {
+			for _, wanted := range resourceTypes {
+				if strings.EqualFold(wanted, resourceType) {
+					return true
+				}
+			}
+			return false
+		}
+	)
+
+	go func() {
+		defer close(filtered)
+
+		for result := range pipeline.OrDone(ctx.Done(), resources) {
+			if resource, ok := result.(AzureWrapper).Data.(models.Resource); !ok {
+				log.Error(fmt.Errorf("failed type assertion"), "unable to continue enumerating resource role assignments", "result", result)
+				return
+			} else if isWanted(resource.Type) {
+				filtered <- resource
+			}
+		}
+	}()
+
+	wg.Add(len(streams))
+	for i := range streams {
+		stream := streams[i]
+		go func() {
+			defer wg.Done()
+			for resource := range stream {
+				var (
+					resourceRoleAssignments = models.ResourceRoleAssignments{
+						ResourceId:   resource.Id,
+						ResourceType: resource.Type,
+					}
+					count = 0
+				)
+				for item := range client.ListRoleAssignmentsForResource(ctx, resource.Id, "") {
+					if item.Error != nil {
+						log.Error(item.Error, "unable to continue processing role assignments for this resource", "resourceId", resource.Id)
+					} else {
+						resourceRoleAssignment := models.ResourceRoleAssignment{
+							ResourceId:     item.ParentId,
+							RoleAssignment: item.Ok,
+						}
+						log.V(2).Info("found resource role assignment", "resourceRoleAssignment", resourceRoleAssignment)
+						count++
+						resourceRoleAssignments.RoleAssignments = append(resourceRoleAssignments.RoleAssignments, resourceRoleAssignment)
+					}
+				}
+				out <- AzureWrapper{
+					Kind: enums.KindAZResourceRoleAssignment,
+					Data: resourceRoleAssignments,
+				}
+				log.V(1).Info("finished listing resource role assignments", "resourceId", resource.Id, "count", count)
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(out)
+		log.Info("finished listing all resource role assignments")
+	}()
+
+	return out
+}
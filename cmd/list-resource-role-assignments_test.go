@@ -0,0 +1,87 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bloodhoundad/azurehound/client/mocks"
+	"github.com/bloodhoundad/azurehound/models"
+	"github.com/bloodhoundad/azurehound/models/azure"
+	"github.com/golang/mock/gomock"
+)
+
+func init() {
+	setupLogger()
+}
+
+func TestListResourceRoleAssignments(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ctx := context.Background()
+
+	mockClient := mocks.NewMockAzureClient(ctrl)
+
+	mockResourcesChannel := make(chan interface{})
+	mockResourceRoleAssignmentChannel := make(chan azure.RoleAssignmentResult)
+
+	mockTenant := azure.Tenant{}
+	mockClient.EXPECT().TenantInfo().Return(mockTenant).AnyTimes()
+	mockClient.EXPECT().ListRoleAssignmentsForResource(gomock.Any(), "/foo", gomock.Any()).Return(mockResourceRoleAssignmentChannel).Times(1)
+	channel := listResourceRoleAssignments(ctx, mockClient, mockResourcesChannel, []string{"Microsoft.Web/sites"}, 25)
+
+	go func() {
+		defer close(mockResourcesChannel)
+		mockResourcesChannel <- AzureWrapper{
+			Data: models.Resource{
+				Resource: azure.Resource{Entity: azure.Entity{Id: "/foo"}, Type: "microsoft.web/sites"},
+			},
+		}
+		mockResourcesChannel <- AzureWrapper{
+			Data: models.Resource{
+				Resource: azure.Resource{Entity: azure.Entity{Id: "/bar"}, Type: "Microsoft.Compute/disks"},
+			},
+		}
+	}()
+	go func() {
+		defer close(mockResourceRoleAssignmentChannel)
+		mockResourceRoleAssignmentChannel <- azure.RoleAssignmentResult{
+			ParentId: "/foo",
+			Ok:       azure.RoleAssignment{},
+		}
+		mockResourceRoleAssignmentChannel <- azure.RoleAssignmentResult{
+			ParentId: "/foo",
+			Ok:       azure.RoleAssignment{},
+		}
+	}()
+
+	if result, ok := <-channel; !ok {
+		t.Fatalf("failed to receive from channel")
+	} else if wrapper, ok := result.(AzureWrapper); !ok {
+		t.Errorf("failed type assertion: got %T, want %T", result, AzureWrapper{})
+	} else if data, ok := wrapper.Data.(models.ResourceRoleAssignments); !ok {
+		t.Errorf("failed type assertion: got %T, want %T", wrapper.Data, models.ResourceRoleAssignments{})
+	} else if len(data.RoleAssignments) != 2 {
+		t.Errorf("got %v, want %v", len(data.RoleAssignments), 2)
+	}
+
+	if _, ok := <-channel; ok {
+		t.Error("should not have recieved from channel")
+	}
+}
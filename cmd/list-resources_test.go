@@ -0,0 +1,92 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/bloodhoundad/azurehound/client/mocks"
+	"github.com/bloodhoundad/azurehound/models"
+	"github.com/bloodhoundad/azurehound/models/azure"
+	"github.com/golang/mock/gomock"
+)
+
+func init() {
+	setupLogger()
+}
+
+func TestListResources(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ctx := context.Background()
+
+	mockClient := mocks.NewMockAzureClient(ctrl)
+	mockSubscriptionsChannel := make(chan interface{})
+	mockResourcesChannel := make(chan azure.ResourceResult)
+	mockResourcesChannel2 := make(chan azure.ResourceResult)
+	mockTenant := azure.Tenant{}
+	mockError := fmt.Errorf("I'm an error")
+	mockClient.EXPECT().TenantInfo().Return(mockTenant).AnyTimes()
+	mockClient.EXPECT().ListAzureResources(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(mockResourcesChannel).Times(1)
+	mockClient.EXPECT().ListAzureResources(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(mockResourcesChannel2).Times(1)
+	channel := listResources(ctx, mockClient, mockSubscriptionsChannel, 25)
+
+	go func() {
+		defer close(mockSubscriptionsChannel)
+		mockSubscriptionsChannel <- AzureWrapper{
+			Data: models.Subscription{},
+		}
+		mockSubscriptionsChannel <- AzureWrapper{
+			Data: models.Subscription{},
+		}
+	}()
+	go func() {
+		defer close(mockResourcesChannel)
+		mockResourcesChannel <- azure.ResourceResult{
+			Ok: azure.Resource{},
+		}
+		mockResourcesChannel <- azure.ResourceResult{
+			Ok: azure.Resource{},
+		}
+	}()
+	go func() {
+		defer close(mockResourcesChannel2)
+		mockResourcesChannel2 <- azure.ResourceResult{
+			Ok: azure.Resource{},
+		}
+		mockResourcesChannel2 <- azure.ResourceResult{
+			Error: mockError,
+		}
+	}()
+
+	for i := 0; i < 3; i++ {
+		if result, ok := <-channel; !ok {
+			t.Fatalf("failed to receive from channel")
+		} else if wrapper, ok := result.(AzureWrapper); !ok {
+			t.Errorf("failed type assertion: got %T, want %T", result, AzureWrapper{})
+		} else if _, ok := wrapper.Data.(models.Resource); !ok {
+			t.Errorf("failed type assertion: got %T, want %T", wrapper.Data, models.Resource{})
+		}
+	}
+
+	if _, ok := <-channel; ok {
+		t.Error("should not have recieved from channel")
+	}
+}
@@ -56,18 +56,18 @@ func listRoleAssignmentsCmdImpl(cmd *cobra.Command, args []string) {
 		log.Info("collecting azure active directory role assignments...")
 		start := time.Now()
 		roles := listRoles(ctx, azClient)
-		stream := listRoleAssignments(ctx, azClient, roles)
+		stream := listRoleAssignments(ctx, azClient, roles, collectorConcurrency("role-assignments"))
 		outputStream(ctx, stream)
 		duration := time.Since(start)
 		log.Info("collection completed", "duration", duration.String())
 	}
 }
 
-func listRoleAssignments(ctx context.Context, client client.AzureClient, roles <-chan interface{}) <-chan interface{} {
+func listRoleAssignments(ctx context.Context, client client.AzureClient, roles <-chan interface{}, concurrency int) <-chan interface{} {
 	var (
 		out     = make(chan interface{})
 		ids     = make(chan string)
-		streams = pipeline.Demux(ctx.Done(), ids, 25)
+		streams = pipeline.Demux(ctx.Done(), ids, concurrency)
 		wg      sync.WaitGroup
 	)
 
@@ -31,7 +31,9 @@ import (
 )
 
 func init() {
-	config.Init(listRootCmd, append(config.AzureConfig, config.OutputFile))
+	configs := append(config.AzureConfig, config.OutputFile)
+	configs = append(configs, config.CollectionConfig...)
+	config.Init(listRootCmd, configs)
 	rootCmd.AddCommand(listRootCmd)
 }
 
@@ -54,12 +56,14 @@ func listCmdImpl(cmd *cobra.Command, args []string) {
 	log.V(1).Info("testing connections")
 	if err := testConnections(); err != nil {
 		exit(err)
-	} else if azClient, err := newAzureClient(); err != nil {
+	} else if azClients, err := newAzureClients(); err != nil {
 		exit(err)
 	} else {
-		log.Info("collecting azure objects...")
+		runPreflight(ctx, azClients)
+
+		log.Info("collecting azure objects...", "tenants", len(azClients))
 		start := time.Now()
-		stream := listAll(ctx, azClient)
+		stream := listAllTenants(ctx, azClients)
 		outputStream(ctx, stream)
 		duration := time.Since(start)
 		log.Info("collection completed", "duration", duration.String())
@@ -68,8 +72,20 @@ func listCmdImpl(cmd *cobra.Command, args []string) {
 
 func listAll(ctx context.Context, client client.AzureClient) <-chan interface{} {
 	var (
-		azureAD = listAllAD(ctx, client)
-		azureRM = listAllRM(ctx, client)
+		azureAD  = listAllAD(ctx, client)
+		azureRM  = listAllRM(ctx, client)
+		tenantId = client.TenantInfo().TenantId
 	)
-	return pipeline.Mux(ctx.Done(), azureAD, azureRM)
+	return pipeline.Map(ctx.Done(), pipeline.Mux(ctx.Done(), azureAD, azureRM), func(item interface{}) interface{} {
+		return tagTenant(item, tenantId)
+	})
+}
+
+// listAllTenants collects from every tenant concurrently into a single stream
+func listAllTenants(ctx context.Context, clients []client.AzureClient) <-chan interface{} {
+	streams := make([]<-chan interface{}, len(clients))
+	for i, client := range clients {
+		streams[i] = listAll(ctx, client)
+	}
+	return pipeline.Mux(ctx.Done(), streams...)
 }
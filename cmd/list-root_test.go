@@ -0,0 +1,422 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/bloodhoundad/azurehound/config"
+	"github.com/bloodhoundad/azurehound/enums"
+	"github.com/bloodhoundad/azurehound/internal/fakeazure"
+	"github.com/bloodhoundad/azurehound/models"
+	"github.com/bloodhoundad/azurehound/models/azure"
+)
+
+func init() {
+	setupLogger()
+}
+
+func TestTagTenant(t *testing.T) {
+	items := []interface{}{
+		NewAzureWrapper(enums.KindAZUser, models.User{}),
+		AzureWrapper{Kind: enums.KindAZSubscription, Data: models.Subscription{}},
+	}
+
+	for _, item := range items {
+		if data, err := json.Marshal(tagTenant(item, "tenant")); err != nil {
+			t.Fatal(err)
+		} else {
+			var wrapper struct{ TenantId string }
+			if err := json.Unmarshal(data, &wrapper); err != nil {
+				t.Fatal(err)
+			} else if wrapper.TenantId != "tenant" {
+				t.Errorf("got %s; want the item tagged with its tenant", data)
+			}
+		}
+	}
+
+	if got := tagTenant("not a wrapper", "tenant"); got != "not a wrapper" {
+		t.Errorf("got %v; want the item unchanged", got)
+	}
+}
+
+func TestListAllTenantsEndToEnd(t *testing.T) {
+	tenant := fakeazure.NewTenant(fakeazure.TenantOptions{
+		Seed:             1,
+		Users:            40,
+		Groups:           15,
+		Apps:             10,
+		Devices:          8,
+		Roles:            5,
+		ManagementGroups: 3,
+		Subscriptions:    2,
+		ResourceGroups:   2,
+		KeyVaults:        1,
+		VirtualMachines:  1,
+	})
+
+	tests := []struct {
+		name     string
+		config   fakeazure.Config
+		settings []configSetting
+	}{
+		{name: "healthy", config: fakeazure.Config{PageSize: 7}},
+		{
+			name: "faulty",
+			config: fakeazure.Config{
+				PageSize: 7,
+				// tokens are refreshed 10s before they expire so this one is replaced a second into the collection
+				TokenLifetime: 11 * time.Second,
+				Faults: fakeazure.Faults{
+					ThrottleEvery:    5,
+					ServerErrorEvery: 7,
+					MalformedEvery:   11,
+					SlowEvery:        3,
+					Latency:          20 * time.Millisecond,
+				},
+			},
+			// throttling every fifth request would otherwise hold collection to the rate it started out at
+			settings: []configSetting{{&config.RateLimit, 1000}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := fakeazure.NewServer(tenant, test.config)
+			defer server.Close()
+
+			got, relationships := collectFromFakeAzure(t, tenant, server.URL, test.settings...)
+			want := expectedKinds(tenant)
+			for kind, count := range want {
+				if got[kind] != count {
+					t.Errorf("got %d %s; want %d", got[kind], kind, count)
+				}
+			}
+			for kind, count := range got {
+				if _, ok := want[kind]; !ok {
+					t.Errorf("got %d unexpected %s", count, kind)
+				}
+			}
+			compareRelationships(t, relationships, expectedRelationships(tenant))
+
+			stats := server.Stats()
+			if len(stats.Unhandled) > 0 {
+				t.Errorf("got requests for unimplemented endpoints: %s", strings.Join(stats.Unhandled, ", "))
+			}
+			if faults := test.config.Faults; faults.ThrottleEvery > 0 {
+				if stats.Throttled == 0 || stats.ServerErrors == 0 || stats.Malformed == 0 || stats.Slow == 0 {
+					t.Errorf("got %+v; want every kind of fault injected", stats)
+				}
+				if stats.Tokens <= 2 {
+					t.Errorf("got %d tokens issued; want the graph and resource manager tokens refreshed", stats.Tokens)
+				}
+			}
+		})
+	}
+}
+
+func TestListReplayOffline(t *testing.T) {
+	var (
+		tenant = fakeazure.NewTenant(fakeazure.TenantOptions{
+			Seed:             2,
+			Users:            20,
+			Groups:           10,
+			Apps:             5,
+			Devices:          5,
+			Roles:            3,
+			ManagementGroups: 2,
+			Subscriptions:    1,
+			ResourceGroups:   2,
+			KeyVaults:        1,
+			VirtualMachines:  1,
+		})
+		server    = fakeazure.NewServer(tenant, fakeazure.Config{PageSize: 7})
+		recording = t.TempDir()
+		want      = expectedKinds(tenant)
+	)
+
+	got, recorded := collectFromFakeAzure(t, tenant, server.URL, configSetting{&config.Record, recording})
+	server.Close()
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v recorded; want %v", got, want)
+	}
+
+	// the server is gone so any request that isn't answered from the recording fails
+	got, replayed := collectFromFakeAzure(t, tenant, server.URL, configSetting{&config.Replay, recording})
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v replayed; want %v", got, want)
+	}
+	compareRelationships(t, replayed, recorded)
+}
+
+type configSetting struct {
+	config *config.Config
+	value  interface{}
+}
+
+// collectFromFakeAzure collects everything from a fake tenant the way the list command does and counts the output by
+// kind, also returning the directory relationships found in it. Any extra settings are applied on top of the ones
+// needed to reach the fake tenant.
+func collectFromFakeAzure(t *testing.T, tenant *fakeazure.Tenant, url string, extra ...configSetting) (map[enums.Kind]int, []string) {
+	output := filepath.Join(t.TempDir(), "output.json")
+	settings := []configSetting{
+		{&config.AzTenant, []string{tenant.Id}},
+		{&config.AzAppId, "app"},
+		{&config.AzSecret, "secret"},
+		{&config.AzAuthUrl, url},
+		{&config.AzGraphUrl, url},
+		{&config.AzMgmtUrl, url},
+		{&config.MaxBackoff, "50ms"},
+		{&config.OutputFile, output},
+	}
+	settings = append(settings, extra...)
+	for _, setting := range settings {
+		setting.config.Set(setting.value)
+		defer setting.config.Set(setting.config.Default)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+
+	if err := testConnections(); err != nil {
+		t.Fatal(err)
+	} else if clients, err := newAzureClients(); err != nil {
+		t.Fatal(err)
+	} else {
+		runPreflight(ctx, clients)
+		outputStream(ctx, listAllTenants(ctx, clients))
+	}
+
+	var result struct {
+		Data []struct {
+			Kind enums.Kind      `json:"kind"`
+			Data json.RawMessage `json:"data"`
+		} `json:"data"`
+	}
+	var (
+		counts        = map[enums.Kind]int{}
+		relationships []string
+	)
+	if data, err := os.ReadFile(output); err != nil {
+		t.Fatal(err)
+	} else if err := json.Unmarshal(data, &result); err != nil {
+		t.Fatal(err)
+	} else {
+		for _, item := range result.Data {
+			counts[item.Kind]++
+			if found, err := relationshipsOf(item.Kind, item.Data); err != nil {
+				t.Fatal(err)
+			} else {
+				relationships = append(relationships, found...)
+			}
+		}
+	}
+	return counts, relationships
+}
+
+// relationshipsOf lists the members, owners and role assignments in an item of output as "kind object principal"
+func relationshipsOf(kind enums.Kind, data json.RawMessage) ([]string, error) {
+	type directoryObject struct {
+		Id string `json:"id"`
+	}
+	var (
+		relationships []string
+		item          struct {
+			GroupId            string `json:"groupId"`
+			AppId              string `json:"appId"`
+			ServicePrincipalId string `json:"servicePrincipalId"`
+			DeviceId           string `json:"deviceId"`
+			Members            []struct {
+				Member directoryObject `json:"member"`
+			} `json:"members"`
+			Owners []struct {
+				Owner directoryObject `json:"owner"`
+			} `json:"owners"`
+			ResourceId      string                        `json:"resourceId"`
+			PrincipalId     string                        `json:"principalId"`
+			RoleAssignments []azure.UnifiedRoleAssignment `json:"roleAssignments"`
+		}
+	)
+	if err := json.Unmarshal(data, &item); err != nil {
+		return nil, err
+	}
+
+	switch kind {
+	case enums.KindAZGroupMember:
+		for _, member := range item.Members {
+			relationships = append(relationships, relationship(kind, item.GroupId, member.Member.Id))
+		}
+	case enums.KindAZGroupOwner, enums.KindAZAppOwner, enums.KindAZServicePrincipalOwner, enums.KindAZDeviceOwner:
+		// only one of the ids is set, depending on the kind
+		objectId := item.GroupId + item.AppId + item.ServicePrincipalId + item.DeviceId
+		for _, owner := range item.Owners {
+			relationships = append(relationships, relationship(kind, objectId, owner.Owner.Id))
+		}
+	case enums.KindAZAppRoleAssignment:
+		relationships = append(relationships, relationship(kind, item.ResourceId, item.PrincipalId))
+	case enums.KindAZRoleAssignment:
+		for _, assignment := range item.RoleAssignments {
+			relationships = append(relationships, relationship(kind, assignment.RoleDefinitionId, assignment.PrincipalId))
+		}
+	}
+	return relationships, nil
+}
+
+func relationship(kind enums.Kind, objectId, principalId string) string {
+	return fmt.Sprintf("%s %s %s", kind, objectId, principalId)
+}
+
+// expectedRelationships lists the relationships of the fake tenant that a collection finds
+func expectedRelationships(tenant *fakeazure.Tenant) []string {
+	var relationships []string
+	owners := func(kind enums.Kind, objectId string) {
+		for _, ownerId := range tenant.Owners[objectId] {
+			relationships = append(relationships, relationship(kind, objectId, ownerId))
+		}
+	}
+
+	for _, group := range tenant.Groups {
+		if group.SecurityEnabled {
+			owners(enums.KindAZGroupOwner, group.Id)
+			for _, memberId := range tenant.Members[group.Id] {
+				relationships = append(relationships, relationship(enums.KindAZGroupMember, group.Id, memberId))
+			}
+		}
+	}
+	for _, app := range tenant.Apps {
+		owners(enums.KindAZAppOwner, app.Id)
+	}
+	for _, servicePrincipal := range tenant.ServicePrincipals {
+		owners(enums.KindAZServicePrincipalOwner, servicePrincipal.Id)
+		for _, assignment := range tenant.AppRoleAssignments[servicePrincipal.Id] {
+			relationships = append(relationships, relationship(enums.KindAZAppRoleAssignment, assignment.ResourceId, assignment.PrincipalId.String()))
+		}
+	}
+	for _, device := range tenant.Devices {
+		owners(enums.KindAZDeviceOwner, device.Id)
+	}
+	for _, assignment := range tenant.RoleAssignments {
+		relationships = append(relationships, relationship(enums.KindAZRoleAssignment, assignment.RoleDefinitionId, assignment.PrincipalId))
+	}
+	return relationships
+}
+
+// compareRelationships reports the relationships missing from got and the ones got has but shouldn't
+func compareRelationships(t *testing.T, got, want []string) {
+	counts := map[string]int{}
+	for _, relationship := range want {
+		counts[relationship]++
+	}
+	for _, relationship := range got {
+		counts[relationship]--
+	}
+
+	if len(want) == 0 {
+		t.Error("got no relationships to compare")
+	}
+	for relationship, count := range counts {
+		if count > 0 {
+			t.Errorf("missing %s", relationship)
+		} else if count < 0 {
+			t.Errorf("got unexpected %s", relationship)
+		}
+	}
+}
+
+// expectedKinds counts what a collection of the fake tenant outputs. Each owner and role assignment kind is one item
+// per object listing all of its principals, even when there are none.
+func expectedKinds(tenant *fakeazure.Tenant) map[enums.Kind]int {
+	var (
+		securityGroups     = 0
+		appRoleAssignments = 0
+		descendants        = 0
+		accessPolicies     = 0
+	)
+	for _, group := range tenant.Groups {
+		if group.SecurityEnabled {
+			securityGroups++
+		}
+	}
+	for _, assignments := range tenant.AppRoleAssignments {
+		appRoleAssignments += len(assignments)
+	}
+	for _, group := range tenant.ManagementGroups {
+		descendants += len(tenant.Descendants(group.Name))
+	}
+	for _, keyVault := range tenant.KeyVaults {
+		for _, policy := range keyVault.Properties.AccessPolicies {
+			if contains(policy.Permissions.Certificates, "Get") || contains(policy.Permissions.Keys, "Get") {
+				accessPolicies++
+			}
+		}
+	}
+
+	var (
+		managementGroups = len(tenant.ManagementGroups)
+		subscriptions    = len(tenant.Subscriptions)
+		resourceGroups   = len(tenant.ResourceGroups)
+		keyVaults        = len(tenant.KeyVaults)
+		virtualMachines  = len(tenant.VirtualMachines)
+	)
+	return map[enums.Kind]int{
+		enums.KindAZApp:                            len(tenant.Apps),
+		enums.KindAZAppOwner:                       len(tenant.Apps),
+		enums.KindAZAppRoleAssignment:              appRoleAssignments,
+		enums.KindAZDevice:                         len(tenant.Devices),
+		enums.KindAZDeviceOwner:                    len(tenant.Devices),
+		enums.KindAZGroup:                          securityGroups,
+		enums.KindAZGroupMember:                    securityGroups,
+		enums.KindAZGroupOwner:                     securityGroups,
+		enums.KindAZRole:                           len(tenant.Roles),
+		enums.KindAZRoleAssignment:                 len(tenant.Roles),
+		enums.KindAZServicePrincipal:               len(tenant.ServicePrincipals),
+		enums.KindAZServicePrincipalOwner:          len(tenant.ServicePrincipals),
+		enums.KindAZTenant:                         1,
+		enums.KindAZUser:                           len(tenant.Users),
+		enums.KindAZManagementGroup:                managementGroups,
+		enums.KindAZManagementGroupDescendant:      descendants,
+		enums.KindAZManagementGroupOwner:           managementGroups,
+		enums.KindAZManagementGroupUserAccessAdmin: managementGroups,
+		enums.KindAZSubscription:                   subscriptions,
+		enums.KindAZSubscriptionOwner:              subscriptions,
+		enums.KindAZSubscriptionUserAccessAdmin:    subscriptions,
+		enums.KindAZResourceGroup:                  resourceGroups,
+		enums.KindAZResourceGroupOwner:             resourceGroups,
+		enums.KindAZResourceGroupUserAccessAdmin:   resourceGroups,
+		enums.KindAZKeyVault:                       keyVaults,
+		enums.KindAZKeyVaultAccessPolicy:           accessPolicies,
+		enums.KindAZKeyVaultContributor:            keyVaults,
+		enums.KindAZKeyVaultKVContributor:          keyVaults,
+		enums.KindAZKeyVaultOwner:                  keyVaults,
+		enums.KindAZKeyVaultUserAccessAdmin:        keyVaults,
+		enums.KindAZVM:                             virtualMachines,
+		enums.KindAZVMAdminLogin:                   virtualMachines,
+		enums.KindAZVMAvereContributor:             virtualMachines,
+		enums.KindAZVMContributor:                  virtualMachines,
+		enums.KindAZVMOwner:                        virtualMachines,
+		enums.KindAZVMUserAccessAdmin:              virtualMachines,
+	}
+}
@@ -55,18 +55,18 @@ func listServicePrincipalOwnersCmdImpl(cmd *cobra.Command, args []string) {
 	} else {
 		log.Info("collecting azure service principal owners...")
 		start := time.Now()
-		stream := listServicePrincipalOwners(ctx, azClient, listServicePrincipals(ctx, azClient))
+		stream := listServicePrincipalOwners(ctx, azClient, listServicePrincipals(ctx, azClient), collectorConcurrency("service-principal-owners"))
 		outputStream(ctx, stream)
 		duration := time.Since(start)
 		log.Info("collection completed", "duration", duration.String())
 	}
 }
 
-func listServicePrincipalOwners(ctx context.Context, client client.AzureClient, servicePrincipals <-chan interface{}) <-chan interface{} {
+func listServicePrincipalOwners(ctx context.Context, client client.AzureClient, servicePrincipals <-chan interface{}, concurrency int) <-chan interface{} {
 	var (
 		out     = make(chan interface{})
 		ids     = make(chan string)
-		streams = pipeline.Demux(ctx.Done(), ids, 25)
+		streams = pipeline.Demux(ctx.Done(), ids, concurrency)
 		wg      sync.WaitGroup
 	)
 
@@ -24,8 +24,11 @@ import (
 	"time"
 
 	"github.com/bloodhoundad/azurehound/client"
+	"github.com/bloodhoundad/azurehound/config"
 	"github.com/bloodhoundad/azurehound/enums"
 	"github.com/bloodhoundad/azurehound/models"
+	"github.com/bloodhoundad/azurehound/models/azure"
+	"github.com/bloodhoundad/azurehound/pipeline"
 	"github.com/spf13/cobra"
 )
 
@@ -60,6 +63,10 @@ func listServicePrincipalsCmdImpl(cmd *cobra.Command, args []string) {
 }
 
 func listServicePrincipals(ctx context.Context, client client.AzureClient) <-chan interface{} {
+	if statePath, _ := config.DeltaState.Value().(string); statePath != "" {
+		return listServicePrincipalsDelta(ctx, client, statePath)
+	}
+
 	out := make(chan interface{})
 
 	go func() {
@@ -87,3 +94,23 @@ func listServicePrincipals(ctx context.Context, client client.AzureClient) <-cha
 
 	return out
 }
+
+func listServicePrincipalsDelta(ctx context.Context, client client.AzureClient, statePath string) <-chan interface{} {
+	list := func(deltaLink string) <-chan deltaItem[azure.ServicePrincipal] {
+		return pipeline.Map(ctx.Done(), client.ListAzureADServicePrincipalsDelta(ctx, deltaLink, nil), func(item azure.ServicePrincipalResult) deltaItem[azure.ServicePrincipal] {
+			return deltaItem[azure.ServicePrincipal]{Error: item.Error, Ok: item.Ok, DeltaLink: item.DeltaLink}
+		})
+	}
+
+	return listDelta(ctx, client, statePath, "service principals", list, func(item azure.ServicePrincipal, incremental bool) (AzureWrapper, bool) {
+		return AzureWrapper{
+			Kind: enums.KindAZServicePrincipal,
+			Data: models.ServicePrincipal{
+				ServicePrincipal: item,
+				TenantId:         client.TenantInfo().TenantId,
+				TenantName:       client.TenantInfo().DisplayName,
+				Delta:            deltaChange(item.DirectoryObject, incremental),
+			},
+		}, true
+	})
+}
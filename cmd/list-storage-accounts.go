@@ -55,18 +55,18 @@ func listStorageAccountsCmdImpl(cmd *cobra.Command, args []string) {
 	} else {
 		log.Info("collecting azure storage accounts...")
 		start := time.Now()
-		stream := listStorageAccounts(ctx, azClient, listSubscriptions(ctx, azClient))
+		stream := listStorageAccounts(ctx, azClient, listSubscriptions(ctx, azClient), collectorConcurrency("storage-accounts"))
 		outputStream(ctx, stream)
 		duration := time.Since(start)
 		log.Info("collection completed", "duration", duration.String())
 	}
 }
 
-func listStorageAccounts(ctx context.Context, client client.AzureClient, subscriptions <-chan interface{}) <-chan interface{} {
+func listStorageAccounts(ctx context.Context, client client.AzureClient, subscriptions <-chan interface{}, concurrency int) <-chan interface{} {
 	var (
 		out     = make(chan interface{})
 		ids     = make(chan string)
-		streams = pipeline.Demux(ctx.Done(), ids, 25)
+		streams = pipeline.Demux(ctx.Done(), ids, concurrency)
 		wg      sync.WaitGroup
 	)
 
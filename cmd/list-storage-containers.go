@@ -56,15 +56,15 @@ func listStorageContainersCmdImpl(cmd *cobra.Command, args []string) {
 		log.Info("collecting azure storage containers...")
 		start := time.Now()
 		subscriptions := listSubscriptions(ctx, azClient)
-		storageAccounts := listStorageAccounts(ctx, azClient, subscriptions)
-		stream := listStorageContainers(ctx, azClient, storageAccounts)
+		storageAccounts := listStorageAccounts(ctx, azClient, subscriptions, collectorConcurrency("storage-accounts"))
+		stream := listStorageContainers(ctx, azClient, storageAccounts, collectorConcurrency("storage-containers"))
 		outputStream(ctx, stream)
 		duration := time.Since(start)
 		log.Info("collection completed", "duration", duration.String())
 	}
 }
 
-func listStorageContainers(ctx context.Context, client client.AzureClient, storageAccounts <-chan interface{}) <-chan interface{} {
+func listStorageContainers(ctx context.Context, client client.AzureClient, storageAccounts <-chan interface{}, concurrency int) <-chan interface{} {
 	var (
 		out     = make(chan interface{})
 		ids     = make(chan interface{})
@@ -73,7 +73,7 @@ func listStorageContainers(ctx context.Context, client client.AzureClient, stora
 		// The error message with higher values for size is
 		// "The request was throttled."
 		// See issue #7: https://github.com/BloodHoundAD/AzureHound/issues/7
-		streams = pipeline.Demux(ctx.Done(), ids, 2)
+		streams = pipeline.Demux(ctx.Done(), ids, concurrency)
 		wg      sync.WaitGroup
 	)
 
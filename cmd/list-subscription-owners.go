@@ -57,7 +57,7 @@ func listSubscriptionOwnersCmdImpl(cmd *cobra.Command, args []string) {
 		log.Info("collecting azure subscription owners...")
 		start := time.Now()
 		subscriptions := listSubscriptions(ctx, azClient)
-		roleAssignments := listSubscriptionRoleAssignments(ctx, azClient, subscriptions)
+		roleAssignments := listSubscriptionRoleAssignments(ctx, azClient, subscriptions, collectorConcurrency("subscription-role-assignments"))
 		stream := listSubscriptionOwners(ctx, azClient, roleAssignments)
 		outputStream(ctx, stream)
 		duration := time.Since(start)
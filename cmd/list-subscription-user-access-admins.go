@@ -57,7 +57,7 @@ func listSubscriptionUserAccessAdminsCmdImpl(cmd *cobra.Command, args []string)
 		log.Info("collecting azure subscription user access admins...")
 		start := time.Now()
 		subscriptions := listSubscriptions(ctx, azClient)
-		roleAssignments := listSubscriptionRoleAssignments(ctx, azClient, subscriptions)
+		roleAssignments := listSubscriptionRoleAssignments(ctx, azClient, subscriptions, collectorConcurrency("subscription-role-assignments"))
 		stream := listSubscriptionUserAccessAdmins(ctx, azClient, roleAssignments)
 		outputStream(ctx, stream)
 		duration := time.Since(start)
@@ -24,8 +24,11 @@ import (
 	"time"
 
 	"github.com/bloodhoundad/azurehound/client"
+	"github.com/bloodhoundad/azurehound/config"
 	"github.com/bloodhoundad/azurehound/enums"
 	"github.com/bloodhoundad/azurehound/models"
+	"github.com/bloodhoundad/azurehound/models/azure"
+	"github.com/bloodhoundad/azurehound/pipeline"
 	"github.com/spf13/cobra"
 )
 
@@ -60,6 +63,10 @@ func listUsersCmdImpl(cmd *cobra.Command, args []string) {
 }
 
 func listUsers(ctx context.Context, client client.AzureClient) <-chan interface{} {
+	if statePath, _ := config.DeltaState.Value().(string); statePath != "" {
+		return listUsersDelta(ctx, client, statePath)
+	}
+
 	out := make(chan interface{})
 
 	go func() {
@@ -88,3 +95,23 @@ func listUsers(ctx context.Context, client client.AzureClient) <-chan interface{
 
 	return out
 }
+
+func listUsersDelta(ctx context.Context, client client.AzureClient, statePath string) <-chan interface{} {
+	list := func(deltaLink string) <-chan deltaItem[azure.User] {
+		return pipeline.Map(ctx.Done(), client.ListAzureADUsersDelta(ctx, deltaLink, nil), func(item azure.UserResult) deltaItem[azure.User] {
+			return deltaItem[azure.User]{Error: item.Error, Ok: item.Ok, DeltaLink: item.DeltaLink}
+		})
+	}
+
+	return listDelta(ctx, client, statePath, "users", list, func(item azure.User, incremental bool) (AzureWrapper, bool) {
+		return AzureWrapper{
+			Kind: enums.KindAZUser,
+			Data: models.User{
+				User:       item,
+				TenantId:   client.TenantInfo().TenantId,
+				TenantName: client.TenantInfo().DisplayName,
+				Delta:      deltaChange(item.DirectoryObject, incremental),
+			},
+		}, true
+	})
+}
@@ -55,8 +55,8 @@ func listVirtualMachineAdminLoginsCmdImpl(cmd *cobra.Command, args []string) {
 		log.Info("collecting azure virtual machine admin logins...")
 		start := time.Now()
 		subscriptions := listSubscriptions(ctx, azClient)
-		vms := listVirtualMachines(ctx, azClient, subscriptions)
-		vmRoleAssignments := listVirtualMachineRoleAssignments(ctx, azClient, vms)
+		vms := listVirtualMachines(ctx, azClient, subscriptions, collectorConcurrency("virtual-machines"))
+		vmRoleAssignments := listVirtualMachineRoleAssignments(ctx, azClient, vms, collectorConcurrency("virtual-machine-role-assignments"))
 		stream := listVirtualMachineAdminLogins(ctx, vmRoleAssignments)
 		outputStream(ctx, stream)
 		duration := time.Since(start)
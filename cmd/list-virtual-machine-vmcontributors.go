@@ -55,8 +55,8 @@ func listVirtualMachineVMContributorsCmdImpl(cmd *cobra.Command, args []string)
 		log.Info("collecting azure virtual machine vmcontributors...")
 		start := time.Now()
 		subscriptions := listSubscriptions(ctx, azClient)
-		vms := listVirtualMachines(ctx, azClient, subscriptions)
-		vmRoleAssignments := listVirtualMachineRoleAssignments(ctx, azClient, vms)
+		vms := listVirtualMachines(ctx, azClient, subscriptions, collectorConcurrency("virtual-machines"))
+		vmRoleAssignments := listVirtualMachineRoleAssignments(ctx, azClient, vms, collectorConcurrency("virtual-machine-role-assignments"))
 		stream := listVirtualMachineVMContributors(ctx, vmRoleAssignments)
 		outputStream(ctx, stream)
 		duration := time.Since(start)
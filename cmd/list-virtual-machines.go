@@ -55,18 +55,18 @@ func listVirtualMachinesCmdImpl(cmd *cobra.Command, args []string) {
 	} else {
 		log.Info("collecting azure virtual machines...")
 		start := time.Now()
-		stream := listVirtualMachines(ctx, azClient, listSubscriptions(ctx, azClient))
+		stream := listVirtualMachines(ctx, azClient, listSubscriptions(ctx, azClient), collectorConcurrency("virtual-machines"))
 		outputStream(ctx, stream)
 		duration := time.Since(start)
 		log.Info("collection completed", "duration", duration.String())
 	}
 }
 
-func listVirtualMachines(ctx context.Context, client client.AzureClient, subscriptions <-chan interface{}) <-chan interface{} {
+func listVirtualMachines(ctx context.Context, client client.AzureClient, subscriptions <-chan interface{}, concurrency int) <-chan interface{} {
 	var (
 		out     = make(chan interface{})
 		ids     = make(chan string)
-		streams = pipeline.Demux(ctx.Done(), ids, 25)
+		streams = pipeline.Demux(ctx.Done(), ids, concurrency)
 		wg      sync.WaitGroup
 	)
 
@@ -55,18 +55,18 @@ func listWorkflowsCmdImpl(cmd *cobra.Command, args []string) {
 	} else {
 		log.Info("collecting azure workflows...")
 		start := time.Now()
-		stream := listWorkflows(ctx, azClient, listSubscriptions(ctx, azClient))
+		stream := listWorkflows(ctx, azClient, listSubscriptions(ctx, azClient), collectorConcurrency("workflows"))
 		outputStream(ctx, stream)
 		duration := time.Since(start)
 		log.Info("collection completed", "duration", duration.String())
 	}
 }
 
-func listWorkflows(ctx context.Context, client client.AzureClient, subscriptions <-chan interface{}) <-chan interface{} {
+func listWorkflows(ctx context.Context, client client.AzureClient, subscriptions <-chan interface{}, concurrency int) <-chan interface{} {
 	var (
 		out     = make(chan interface{})
 		ids     = make(chan string)
-		streams = pipeline.Demux(ctx.Done(), ids, 25)
+		streams = pipeline.Demux(ctx.Done(), ids, concurrency)
 		wg      sync.WaitGroup
 	)
 
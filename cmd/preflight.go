@@ -0,0 +1,386 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/bloodhoundad/azurehound/client"
+	"github.com/bloodhoundad/azurehound/client/rest"
+	"github.com/bloodhoundad/azurehound/config"
+	"github.com/bloodhoundad/azurehound/enums"
+	"github.com/bloodhoundad/azurehound/models/azure"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	configs := append(config.AzureConfig, config.CollectionConfig...)
+	config.Init(preflightCmd, configs)
+	rootCmd.AddCommand(preflightCmd)
+}
+
+var preflightCmd = &cobra.Command{
+	Use:               "preflight",
+	Short:             "Checks which Azure objects the configured credentials can collect",
+	Run:               preflightCmdImpl,
+	PersistentPreRunE: persistentPreRunE,
+	SilenceUsage:      true,
+}
+
+func preflightCmdImpl(cmd *cobra.Command, args []string) {
+	if len(args) > 0 {
+		exit(fmt.Errorf("unsupported subcommand: %v", args))
+	}
+
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, os.Kill)
+	defer gracefulShutdown(stop)
+
+	log.V(1).Info("testing connections")
+	if err := testConnections(); err != nil {
+		exit(err)
+	} else if azClients, err := newAzureClients(); err != nil {
+		exit(err)
+	} else {
+		for i, azClient := range azClients {
+			if overrides, err := tenantOverrides(i); err != nil {
+				exit(err)
+			} else {
+				printPreflight(os.Stdout, preflight(ctx, azClient, overrides))
+			}
+		}
+	}
+}
+
+// tenantOverrides returns the settings from the config file section of the tenant newAzureClients created the i-th
+// client for
+func tenantOverrides(i int) (config.Overrides, error) {
+	return config.TenantOverrides(config.AzTenant.Value().([]string)[i])
+}
+
+type preflightStatus string
+
+const (
+	preflightComplete preflightStatus = "complete"
+	preflightPartial  preflightStatus = "partial"
+	preflightEmpty    preflightStatus = "empty"
+)
+
+// preflightResult says whether the kinds produced by a collector will be collected in full and, if not, why
+type preflightResult struct {
+	collector string
+	kinds     []enums.Kind
+	status    preflightStatus
+	reason    string
+}
+
+type preflightReport struct {
+	tenant azure.Tenant
+
+	// the Graph app roles, or scopes for delegated tokens, the token was issued with; nil if it couldn't be read
+	permissions []string
+	delegated   bool
+	results     []preflightResult
+}
+
+// graphPreflightCheck probes a Microsoft Graph collector and lists the permissions, any of which it needs
+type graphPreflightCheck struct {
+	collector   string
+	kinds       []enums.Kind
+	permissions []string
+
+	// nil for collectors that enumerate the relationships of objects another collector lists
+	probe func(ctx context.Context, client client.AzureClient) error
+}
+
+var graphPreflightChecks = []graphPreflightCheck{
+	{"tenants", []enums.Kind{enums.KindAZTenant}, nil, func(ctx context.Context, client client.AzureClient) error {
+		_, err := client.GetAzureADOrganization(ctx, nil)
+		return err
+	}},
+	{"users", []enums.Kind{enums.KindAZUser}, []string{"User.Read.All", "Directory.Read.All"}, func(ctx context.Context, client client.AzureClient) error {
+		_, err := client.GetAzureADUsers(ctx, "", "", "", []string{"id"}, 1, false)
+		return err
+	}},
+	{"groups", []enums.Kind{enums.KindAZGroup}, []string{"Group.Read.All", "Directory.Read.All"}, func(ctx context.Context, client client.AzureClient) error {
+		_, err := client.GetAzureADGroups(ctx, "", "", "", "", []string{"id"}, 1, false)
+		return err
+	}},
+	{"group-members", []enums.Kind{enums.KindAZGroupMember, enums.KindAZGroupOwner}, []string{"GroupMember.Read.All", "Group.Read.All", "Directory.Read.All"}, nil},
+	{"apps", []enums.Kind{enums.KindAZApp, enums.KindAZAppOwner}, []string{"Application.Read.All", "Directory.Read.All"}, func(ctx context.Context, client client.AzureClient) error {
+		_, err := client.GetAzureADApps(ctx, "", "", "", "", []string{"id"}, 1, false)
+		return err
+	}},
+	{"service-principals", []enums.Kind{enums.KindAZServicePrincipal, enums.KindAZServicePrincipalOwner}, []string{"Application.Read.All", "Directory.Read.All"}, func(ctx context.Context, client client.AzureClient) error {
+		_, err := client.GetAzureADServicePrincipals(ctx, "", "", "", "", []string{"id"}, 1, false)
+		return err
+	}},
+	{"app-role-assignments", []enums.Kind{enums.KindAZAppRoleAssignment}, []string{"Application.Read.All", "Directory.Read.All"}, nil},
+	{"devices", []enums.Kind{enums.KindAZDevice, enums.KindAZDeviceOwner}, []string{"Device.Read.All", "Directory.Read.All"}, func(ctx context.Context, client client.AzureClient) error {
+		_, err := client.GetAzureDevices(ctx, "", "", "", "", []string{"id"}, 1, false)
+		return err
+	}},
+	{"roles", []enums.Kind{enums.KindAZRole}, []string{"RoleManagement.Read.Directory", "Directory.Read.All"}, func(ctx context.Context, client client.AzureClient) error {
+		_, err := client.GetAzureADRoles(ctx, "", "")
+		return err
+	}},
+	{"role-assignments", []enums.Kind{enums.KindAZRoleAssignment}, []string{"RoleManagement.Read.Directory", "Directory.Read.All"}, func(ctx context.Context, client client.AzureClient) error {
+		_, err := client.GetAzureADRoleAssignments(ctx, "", "", "", "", []string{"id"}, 1, false)
+		return err
+	}},
+}
+
+// rmPreflightCheck probes an Azure Resource Manager collector against a single subscription
+type rmPreflightCheck struct {
+	collector string
+	kinds     []enums.Kind
+	probe     func(ctx context.Context, client client.AzureClient, subscriptionId string) error
+}
+
+var rmPreflightChecks = []rmPreflightCheck{
+	{"subscription-role-assignments", []enums.Kind{enums.KindAZSubscriptionRoleAssignment, enums.KindAZSubscriptionOwner, enums.KindAZSubscriptionUserAccessAdmin}, func(ctx context.Context, client client.AzureClient, subscriptionId string) error {
+		_, err := client.GetRoleAssignmentsForResource(ctx, "/subscriptions/"+subscriptionId, "atScope()")
+		return err
+	}},
+	{"resource-groups", []enums.Kind{enums.KindAZResourceGroup, enums.KindAZResourceGroupRoleAssignment, enums.KindAZResourceGroupOwner, enums.KindAZResourceGroupUserAccessAdmin}, func(ctx context.Context, client client.AzureClient, subscriptionId string) error {
+		_, err := client.GetAzureResourceGroups(ctx, subscriptionId, "", 1)
+		return err
+	}},
+	{"key-vaults", []enums.Kind{enums.KindAZKeyVault, enums.KindAZKeyVaultAccessPolicy, enums.KindAZKeyVaultRoleAssignment, enums.KindAZKeyVaultOwner, enums.KindAZKeyVaultContributor, enums.KindAZKeyVaultKVContributor, enums.KindAZKeyVaultUserAccessAdmin}, func(ctx context.Context, client client.AzureClient, subscriptionId string) error {
+		_, err := client.GetAzureKeyVaults(ctx, subscriptionId, 1)
+		return err
+	}},
+	{"virtual-machines", []enums.Kind{enums.KindAZVM, enums.KindAZVMRoleAssignment, enums.KindAZVMOwner, enums.KindAZVMContributor, enums.KindAZVMVMContributor, enums.KindAZVMAdminLogin, enums.KindAZVMAvereContributor, enums.KindAZVMUserAccessAdmin}, resourceProbe("Microsoft.Compute/virtualMachines")},
+	{"storage-accounts", []enums.Kind{enums.KindAZStorageAccount, enums.KindAZStorageAccountRoleAssignment, enums.KindAZStorageContainer}, resourceProbe("Microsoft.Storage/storageAccounts")},
+	{"automation-accounts", []enums.Kind{enums.KindAZAutomationAccount, enums.KindAZAutomationAccountRoleAssignment}, resourceProbe("Microsoft.Automation/automationAccounts")},
+	{"workflows", []enums.Kind{enums.KindAZWorkflow, enums.KindAZWorkflowRoleAssignment}, func(ctx context.Context, client client.AzureClient, subscriptionId string) error {
+		return firstResult(ctx, func(ctx context.Context) <-chan azure.WorkflowResult {
+			return client.ListAzureWorkflows(ctx, subscriptionId, "", 1)
+		}).Error
+	}},
+	{"function-apps", []enums.Kind{enums.KindAZFunctionApp, enums.KindAZFunctionAppRoleAssignment}, resourceProbe("Microsoft.Web/sites")},
+	{"resources", []enums.Kind{enums.KindAZResource, enums.KindAZResourceRoleAssignment}, func(ctx context.Context, client client.AzureClient, subscriptionId string) error {
+		_, err := client.GetAzureResources(ctx, subscriptionId, "", "", 1)
+		return err
+	}},
+}
+
+// resourceProbe returns a probe that reads at most one resource of the given type, rather than listing all of them as the
+// collectors of types without a $top parameter have to
+func resourceProbe(resourceType string) func(ctx context.Context, client client.AzureClient, subscriptionId string) error {
+	return func(ctx context.Context, client client.AzureClient, subscriptionId string) error {
+		_, err := client.GetAzureResources(ctx, subscriptionId, fmt.Sprintf("resourceType eq '%s'", resourceType), "", 1)
+		return err
+	}
+}
+
+var (
+	subscriptionKinds    = []enums.Kind{enums.KindAZSubscription}
+	managementGroupKinds = []enums.Kind{enums.KindAZManagementGroup, enums.KindAZManagementGroupRoleAssignment, enums.KindAZManagementGroupOwner, enums.KindAZManagementGroupDescendant, enums.KindAZManagementGroupUserAccessAdmin}
+)
+
+// preflight probes each collector with one cheap request to tell ahead of collection which kinds will be incomplete,
+// using the settings from the tenant's section of the config file, if any
+func preflight(ctx context.Context, client client.AzureClient, overrides config.Overrides) preflightReport {
+	report := preflightReport{tenant: client.TenantInfo()}
+
+	if token, err := client.GraphToken(ctx); err != nil {
+		log.V(1).Info("unable to read the Microsoft Graph token's permissions", "error", err)
+	} else if permissions, delegated, err := graphPermissions(token); err != nil {
+		log.V(1).Info("unable to read the Microsoft Graph token's permissions", "error", err)
+	} else {
+		report.permissions, report.delegated = permissions, delegated
+	}
+
+	for _, check := range graphPreflightChecks {
+		report.results = append(report.results, check.run(ctx, client, report.permissions))
+	}
+	report.results = append(report.results, rmPreflight(ctx, client, overrides)...)
+	return report
+}
+
+func (s graphPreflightCheck) run(ctx context.Context, client client.AzureClient, permissions []string) preflightResult {
+	result := preflightResult{collector: s.collector, kinds: s.kinds, status: preflightComplete}
+	if s.probe != nil {
+		if err := s.probe(ctx, client); err != nil {
+			result.status, result.reason = preflightEmpty, preflightReason(err)
+			return result
+		}
+	}
+
+	if permissions != nil && len(s.permissions) > 0 && !grants(permissions, s.permissions...) {
+		result.reason = fmt.Sprintf("the token grants none of %s", strings.Join(s.permissions, ", "))
+		if s.probe != nil {
+			// delegated tokens may still see the objects the signed in user can
+			result.status = preflightPartial
+		} else {
+			result.status = preflightEmpty
+		}
+	}
+	return result
+}
+
+func rmPreflight(ctx context.Context, client client.AzureClient, overrides config.Overrides) []preflightResult {
+	var (
+		managementGroups = preflightResult{collector: "management-groups", kinds: managementGroupKinds, status: preflightComplete}
+		subscription     = preflightResult{collector: "subscriptions", kinds: subscriptionKinds, status: preflightComplete}
+		subscriptions    []azure.Subscription
+		selected         = config.AzSubId.ValueOf(overrides).([]string)
+	)
+
+	if groups, err := client.GetAzureManagementGroups(ctx); err != nil {
+		managementGroups.status, managementGroups.reason = preflightEmpty, preflightReason(err)
+	} else if len(groups.Value) == 0 {
+		managementGroups.status, managementGroups.reason = preflightEmpty, "no management groups are visible; assign Reader on the tenant root group"
+	}
+
+	// stop listing subscriptions after an error
+	listCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	for item := range client.ListAzureSubscriptions(listCtx) {
+		if item.Error != nil {
+			subscription.status, subscription.reason = preflightEmpty, preflightReason(item.Error)
+			break
+		} else if len(selected) == 0 || contains(selected, item.Ok.SubscriptionId) {
+			subscriptions = append(subscriptions, item.Ok)
+		}
+	}
+	if subscription.status == preflightComplete && len(subscriptions) == 0 {
+		subscription.status, subscription.reason = preflightEmpty, "no subscriptions are visible; assign Reader on them or a management group above them"
+	}
+
+	results := []preflightResult{managementGroups, subscription}
+	for _, check := range rmPreflightChecks {
+		result := preflightResult{collector: check.collector, kinds: check.kinds, status: preflightComplete}
+		if len(subscriptions) == 0 {
+			result.status, result.reason = preflightEmpty, "no subscriptions to collect from"
+		} else if err := check.probe(ctx, client, subscriptions[0].SubscriptionId); err != nil {
+			result.status, result.reason = preflightEmpty, fmt.Sprintf("%s: %s", subscriptions[0].DisplayName, preflightReason(err))
+		} else if len(subscriptions) > 1 {
+			result.reason = fmt.Sprintf("checked on %s, one of %d subscriptions", subscriptions[0].DisplayName, len(subscriptions))
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// graphPermissions returns the app roles a Microsoft Graph token was issued with or, for delegated tokens, its scopes
+func graphPermissions(token rest.Token) ([]string, bool, error) {
+	if claims, err := token.Claims(); err != nil {
+		return nil, false, err
+	} else if scp, ok := claims["scp"].(string); ok {
+		return strings.Fields(scp), true, nil
+	} else {
+		permissions := []string{}
+		roles, _ := claims["roles"].([]interface{})
+		for _, role := range roles {
+			if role, ok := role.(string); ok {
+				permissions = append(permissions, role)
+			}
+		}
+		return permissions, false, nil
+	}
+}
+
+// grants reports whether any of the granted permissions is one of those required. Permissions to read and write
+// include permission to read.
+func grants(granted []string, required ...string) bool {
+	for _, permission := range granted {
+		permission = strings.Replace(permission, ".ReadWrite.", ".Read.", 1)
+		for _, requirement := range required {
+			if strings.EqualFold(permission, requirement) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// preflightReason describes why a probe failed, preferring the message of an API error response
+func preflightReason(err error) string {
+	var resErr rest.ResponseError
+	if errors.As(err, &resErr) {
+		if body, ok := resErr.Body["error"].(map[string]interface{}); ok {
+			return fmt.Sprintf("%d %v: %v", resErr.StatusCode, body["code"], body["message"])
+		}
+	}
+	return err.Error()
+}
+
+// firstResult returns the first result of a listing and cancels the rest of it
+func firstResult[T any](ctx context.Context, list func(ctx context.Context) <-chan T) T {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	return <-list(ctx)
+}
+
+func printPreflight(w io.Writer, report preflightReport) {
+	fmt.Fprintf(w, "Tenant: %s (%s)\n", report.tenant.DisplayName, report.tenant.TenantId)
+	if report.permissions == nil {
+		fmt.Fprintln(w, "Microsoft Graph permissions: unknown")
+	} else if report.delegated {
+		fmt.Fprintf(w, "Microsoft Graph delegated scopes: %s\n", strings.Join(report.permissions, ", "))
+	} else {
+		fmt.Fprintf(w, "Microsoft Graph application permissions: %s\n", strings.Join(report.permissions, ", "))
+	}
+	fmt.Fprintln(w)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "KIND\tCOLLECTOR\tSTATUS\tREASON")
+	for _, result := range report.results {
+		for _, kind := range result.kinds {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", kind, result.collector, result.status, result.reason)
+		}
+	}
+	tw.Flush()
+	fmt.Fprintln(w)
+}
+
+// runPreflight logs what the collection from each tenant will miss unless disabled with --skip-preflight
+func runPreflight(ctx context.Context, clients []client.AzureClient) {
+	if skip, _ := config.SkipPreflight.Value().(bool); !skip {
+		log.V(1).Info("checking permissions")
+		for i, client := range clients {
+			if overrides, err := tenantOverrides(i); err != nil {
+				log.Error(err, "unable to check permissions", "tenant", client.TenantInfo().TenantId)
+			} else {
+				logPreflight(preflight(ctx, client, overrides))
+			}
+		}
+	}
+}
+
+// logPreflight logs the collectors that won't collect everything
+func logPreflight(report preflightReport) {
+	complete := true
+	for _, result := range report.results {
+		if result.status != preflightComplete {
+			complete = false
+			log.Info("preflight: collection will be incomplete", "tenant", report.tenant.TenantId, "collector", result.collector, "status", result.status, "reason", result.reason)
+		}
+	}
+	if complete {
+		log.V(1).Info("preflight: all collectors have the permissions they need", "tenant", report.tenant.TenantId)
+	}
+}
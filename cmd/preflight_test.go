@@ -0,0 +1,162 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/bloodhoundad/azurehound/client/mocks"
+	"github.com/bloodhoundad/azurehound/client/rest"
+	"github.com/bloodhoundad/azurehound/config"
+	"github.com/bloodhoundad/azurehound/models/azure"
+	"github.com/golang/mock/gomock"
+)
+
+func init() {
+	setupLogger()
+}
+
+func testToken(t *testing.T, claims map[string]interface{}) rest.Token {
+	var token rest.Token
+	if body, err := json.Marshal(claims); err != nil {
+		t.Fatal(err)
+	} else if data, err := json.Marshal(map[string]string{"access_token": "e30." + base64.RawURLEncoding.EncodeToString(body) + ".sig"}); err != nil {
+		t.Fatal(err)
+	} else if err := json.Unmarshal(data, &token); err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func TestPreflight(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ctx := context.Background()
+
+	var (
+		mockClient    = mocks.NewMockAzureClient(ctrl)
+		mockForbidden = rest.ResponseError{
+			StatusCode: http.StatusForbidden,
+			Body:       map[string]interface{}{"error": map[string]interface{}{"code": "Authorization_RequestDenied", "message": "Insufficient privileges"}},
+		}
+		subscriptions = make(chan azure.SubscriptionResult, 1)
+	)
+	subscriptions <- azure.SubscriptionResult{Ok: azure.Subscription{SubscriptionId: "sub", DisplayName: "Production"}}
+	close(subscriptions)
+
+	mockClient.EXPECT().TenantInfo().Return(azure.Tenant{TenantId: "tenant"}).AnyTimes()
+	mockClient.EXPECT().GraphToken(gomock.Any()).Return(testToken(t, map[string]interface{}{"roles": []string{"User.Read.All", "Group.Read.All", "Application.ReadWrite.All"}}), nil)
+
+	// microsoft graph
+	mockClient.EXPECT().GetAzureADOrganization(gomock.Any(), gomock.Any()).Return(&azure.Organization{}, nil)
+	mockClient.EXPECT().GetAzureADUsers(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(azure.UserList{}, nil)
+	mockClient.EXPECT().GetAzureADGroups(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(azure.GroupList{}, nil)
+	mockClient.EXPECT().GetAzureADApps(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(azure.ApplicationList{}, nil)
+	mockClient.EXPECT().GetAzureADServicePrincipals(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(azure.ServicePrincipalList{}, nil)
+	mockClient.EXPECT().GetAzureDevices(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(azure.DeviceList{}, nil)
+	mockClient.EXPECT().GetAzureADRoles(gomock.Any(), gomock.Any(), gomock.Any()).Return(azure.RoleList{}, nil)
+	mockClient.EXPECT().GetAzureADRoleAssignments(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(azure.UnifiedRoleAssignmentList{}, fmt.Errorf("wrapped: %w", mockForbidden))
+
+	// azure resource manager
+	mockClient.EXPECT().GetAzureManagementGroups(gomock.Any()).Return(azure.ManagementGroupList{}, nil)
+	mockClient.EXPECT().ListAzureSubscriptions(gomock.Any()).Return(subscriptions)
+	mockClient.EXPECT().GetRoleAssignmentsForResource(gomock.Any(), "/subscriptions/sub", gomock.Any()).Return(azure.RoleAssignmentList{}, nil)
+	mockClient.EXPECT().GetAzureResourceGroups(gomock.Any(), "sub", gomock.Any(), gomock.Any()).Return(azure.ResourceGroupList{}, nil)
+	mockClient.EXPECT().GetAzureKeyVaults(gomock.Any(), "sub", gomock.Any()).Return(azure.KeyVaultList{}, mockForbidden)
+	mockClient.EXPECT().GetAzureResources(gomock.Any(), "sub", "resourceType eq 'Microsoft.Compute/virtualMachines'", "", int32(1)).Return(azure.ResourceList{}, nil)
+	mockClient.EXPECT().GetAzureResources(gomock.Any(), "sub", "resourceType eq 'Microsoft.Storage/storageAccounts'", "", int32(1)).Return(azure.ResourceList{}, nil)
+	mockClient.EXPECT().GetAzureResources(gomock.Any(), "sub", "resourceType eq 'Microsoft.Automation/automationAccounts'", "", int32(1)).Return(azure.ResourceList{}, mockForbidden)
+	mockClient.EXPECT().ListAzureWorkflows(gomock.Any(), "sub", gomock.Any(), gomock.Any()).Return(closedChannel[azure.WorkflowResult]())
+	mockClient.EXPECT().GetAzureResources(gomock.Any(), "sub", "resourceType eq 'Microsoft.Web/sites'", "", int32(1)).Return(azure.ResourceList{}, nil)
+	mockClient.EXPECT().GetAzureResources(gomock.Any(), "sub", "", "", int32(1)).Return(azure.ResourceList{}, nil)
+
+	report := preflight(ctx, mockClient, config.Overrides{})
+	if report.delegated || len(report.permissions) != 3 {
+		t.Errorf("got permissions %v; want the token's app roles", report.permissions)
+	}
+
+	want := map[string]preflightStatus{
+		"tenants":              preflightComplete,
+		"users":                preflightComplete,
+		"groups":               preflightComplete,
+		"group-members":        preflightComplete,
+		"apps":                 preflightComplete,
+		"app-role-assignments": preflightComplete,
+		"devices":              preflightPartial,
+		"roles":                preflightPartial,
+		"role-assignments":     preflightEmpty,
+		"management-groups":    preflightEmpty,
+		"subscriptions":        preflightComplete,
+		"key-vaults":           preflightEmpty,
+		"virtual-machines":     preflightComplete,
+		"automation-accounts":  preflightEmpty,
+	}
+	for _, result := range report.results {
+		if status, ok := want[result.collector]; ok && status != result.status {
+			t.Errorf("%s: got %s (%s); want %s", result.collector, result.status, result.reason, status)
+		}
+	}
+	for _, result := range report.results {
+		if result.collector == "role-assignments" && result.reason != "403 Authorization_RequestDenied: Insufficient privileges" {
+			t.Errorf("got reason %q; want the API's error message", result.reason)
+		}
+	}
+}
+
+func TestPreflightTenantSubscriptions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ctx := context.Background()
+
+	var (
+		mockClient    = mocks.NewMockAzureClient(ctrl)
+		subscriptions = make(chan azure.SubscriptionResult, 2)
+		overrides     = config.Overrides{"subscriptionid": []interface{}{"staging"}}
+	)
+	subscriptions <- azure.SubscriptionResult{Ok: azure.Subscription{SubscriptionId: "production", DisplayName: "Production"}}
+	subscriptions <- azure.SubscriptionResult{Ok: azure.Subscription{SubscriptionId: "staging", DisplayName: "Staging"}}
+	close(subscriptions)
+
+	config.AzSubId.Set([]string{"production"})
+	defer config.AzSubId.Set(config.AzSubId.Default)
+
+	mockClient.EXPECT().GetAzureManagementGroups(gomock.Any()).Return(azure.ManagementGroupList{}, nil)
+	mockClient.EXPECT().ListAzureSubscriptions(gomock.Any()).Return(subscriptions)
+	mockClient.EXPECT().GetRoleAssignmentsForResource(gomock.Any(), "/subscriptions/staging", gomock.Any()).Return(azure.RoleAssignmentList{}, nil)
+	mockClient.EXPECT().GetAzureResourceGroups(gomock.Any(), "staging", gomock.Any(), gomock.Any()).Return(azure.ResourceGroupList{}, nil)
+	mockClient.EXPECT().GetAzureKeyVaults(gomock.Any(), "staging", gomock.Any()).Return(azure.KeyVaultList{}, nil)
+	mockClient.EXPECT().GetAzureResources(gomock.Any(), "staging", gomock.Any(), gomock.Any(), gomock.Any()).Return(azure.ResourceList{}, nil).Times(5)
+	mockClient.EXPECT().ListAzureWorkflows(gomock.Any(), "staging", gomock.Any(), gomock.Any()).Return(closedChannel[azure.WorkflowResult]())
+
+	for _, result := range rmPreflight(ctx, mockClient, overrides) {
+		if result.collector == "subscriptions" && result.status != preflightComplete {
+			t.Errorf("got %s (%s); want the tenant's subscription to be selected", result.status, result.reason)
+		}
+	}
+}
+
+func closedChannel[T any]() <-chan T {
+	channel := make(chan T)
+	close(channel)
+	return channel
+}
@@ -0,0 +1,147 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/bloodhoundad/azurehound/client"
+	"github.com/bloodhoundad/azurehound/constants"
+	"github.com/bloodhoundad/azurehound/enums"
+	"github.com/bloodhoundad/azurehound/models"
+	"github.com/bloodhoundad/azurehound/models/azure"
+	"github.com/bloodhoundad/azurehound/pipeline"
+	"github.com/gofrs/uuid"
+)
+
+// permissionResolver maps the app role and oauth2 permission scope ids exposed by resource service principals to
+// their permission values
+type permissionResolver struct {
+	ready             chan struct{}
+	servicePrincipals map[string]azure.ServicePrincipal
+}
+
+func newPermissionResolver(ctx context.Context, servicePrincipals <-chan interface{}) *permissionResolver {
+	resolver := &permissionResolver{
+		ready:             make(chan struct{}),
+		servicePrincipals: make(map[string]azure.ServicePrincipal),
+	}
+
+	go func() {
+		defer close(resolver.ready)
+
+		for result := range pipeline.OrDone(ctx.Done(), servicePrincipals) {
+			if servicePrincipal, ok := result.(AzureWrapper).Data.(models.ServicePrincipal); !ok {
+				log.Error(fmt.Errorf("failed type assertion"), "unable to continue indexing service principal permissions", "result", result)
+				return
+			} else if len(servicePrincipal.AppRoles) != 0 || len(servicePrincipal.OAuth2PermissionScopes) != 0 {
+				resolver.servicePrincipals[servicePrincipal.AppId] = servicePrincipal.ServicePrincipal
+			}
+		}
+		log.V(1).Info("finished indexing service principal permissions", "count", len(resolver.servicePrincipals))
+	}()
+
+	return resolver
+}
+
+// listPermissionResources lists the permissions exposed by every service principal for newPermissionResolver
+func listPermissionResources(ctx context.Context, client client.AzureClient) <-chan interface{} {
+	out := make(chan interface{})
+
+	go func() {
+		defer close(out)
+		for item := range client.ListAzureADServicePrincipals(ctx, "", "", "", "", []string{"appId", "appRoles", "oauth2PermissionScopes"}) {
+			if item.Error != nil {
+				log.Error(item.Error, "unable to continue indexing service principal permissions")
+				return
+			} else {
+				out <- AzureWrapper{
+					Kind: enums.KindAZServicePrincipal,
+					Data: models.ServicePrincipal{ServicePrincipal: item.Ok},
+				}
+			}
+		}
+	}()
+
+	return out
+}
+
+// Resolve blocks until all service principals have been indexed and returns the permission requested from the given
+// resource application
+func (s *permissionResolver) Resolve(resourceAppId string, access azure.ResourceAccess) models.Permission {
+	<-s.ready
+	return resolvePermission(resourceAppId, s.servicePrincipals[resourceAppId], access.Id, access.Type)
+}
+
+func resolvePermission(resourceAppId string, resource azure.ServicePrincipal, id uuid.UUID, accessType enums.AccessType) models.Permission {
+	permission := models.Permission{
+		Id:            id,
+		Type:          accessType,
+		ResourceAppId: resourceAppId,
+	}
+
+	switch accessType {
+	case enums.AccessTypeRole:
+		for _, appRole := range resource.AppRoles {
+			if appRole.Id == id {
+				permission.Value = appRole.Value
+				break
+			}
+		}
+	case enums.AccessTypeScope:
+		for _, scope := range resource.OAuth2PermissionScopes {
+			if scope.Id == id {
+				permission.Value = scope.Value
+				break
+			}
+		}
+	}
+
+	permission.IsTierZero = constants.IsTierZeroPermission(resourceAppId, permission.Value)
+	return permission
+}
+
+func resolveAppPermissions(ctx context.Context, resolver *permissionResolver, apps <-chan interface{}) <-chan interface{} {
+	out := make(chan interface{})
+
+	go func() {
+		defer close(out)
+
+		for result := range pipeline.OrDone(ctx.Done(), apps) {
+			if app, ok := result.(AzureWrapper).Data.(models.App); !ok {
+				log.Error(fmt.Errorf("failed type assertion"), "unable to continue resolving application permissions", "result", result)
+				return
+			} else {
+				for _, requiredResourceAccess := range app.RequiredResourceAccess {
+					for _, access := range requiredResourceAccess.ResourceAccess {
+						permission := resolver.Resolve(requiredResourceAccess.ResourceAppId, access)
+						log.V(2).Info("resolved application permission", "appId", app.AppId, "permission", permission)
+						app.RequiredPermissions = append(app.RequiredPermissions, permission)
+					}
+				}
+				out <- AzureWrapper{
+					Kind: enums.KindAZApp,
+					Data: app,
+				}
+			}
+		}
+	}()
+
+	return out
+}
@@ -0,0 +1,129 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"testing"
+
+	"github.com/bloodhoundad/azurehound/client/mocks"
+	"github.com/bloodhoundad/azurehound/constants"
+	"github.com/bloodhoundad/azurehound/enums"
+	"github.com/bloodhoundad/azurehound/models"
+	"github.com/bloodhoundad/azurehound/models/azure"
+	"github.com/gofrs/uuid"
+	"github.com/golang/mock/gomock"
+)
+
+func init() {
+	setupLogger()
+}
+
+func TestResolveAppPermissions(t *testing.T) {
+	ctx := context.Background()
+
+	var (
+		mockServicePrincipalsChannel = make(chan interface{})
+		mockAppsChannel              = make(chan interface{})
+		roleId                       = uuid.Must(uuid.NewV4())
+		scopeId                      = uuid.Must(uuid.NewV4())
+	)
+
+	resolver := newPermissionResolver(ctx, mockServicePrincipalsChannel)
+	channel := resolveAppPermissions(ctx, resolver, mockAppsChannel)
+
+	go func() {
+		defer close(mockServicePrincipalsChannel)
+		mockServicePrincipalsChannel <- AzureWrapper{
+			Data: models.ServicePrincipal{
+				ServicePrincipal: azure.ServicePrincipal{
+					AppId:                  constants.MicrosoftGraphAppID,
+					AppRoles:               []azure.AppRole{{Id: roleId, Value: "RoleManagement.ReadWrite.Directory"}},
+					OAuth2PermissionScopes: []azure.PermissionScope{{Id: scopeId, Value: "User.Read"}},
+				},
+			},
+		}
+	}()
+	go func() {
+		defer close(mockAppsChannel)
+		mockAppsChannel <- AzureWrapper{
+			Data: models.App{
+				Application: azure.Application{
+					RequiredResourceAccess: []azure.RequiredResourceAccess{
+						{
+							ResourceAppId: constants.MicrosoftGraphAppID,
+							ResourceAccess: []azure.ResourceAccess{
+								{Id: roleId, Type: enums.AccessTypeRole},
+								{Id: scopeId, Type: enums.AccessTypeScope},
+							},
+						},
+					},
+				},
+			},
+		}
+	}()
+
+	if result, ok := <-channel; !ok {
+		t.Fatalf("failed to receive from channel")
+	} else if wrapper, ok := result.(AzureWrapper); !ok {
+		t.Errorf("failed type assertion: got %T, want %T", result, AzureWrapper{})
+	} else if data, ok := wrapper.Data.(models.App); !ok {
+		t.Errorf("failed type assertion: got %T, want %T", wrapper.Data, models.App{})
+	} else if len(data.RequiredPermissions) != 2 {
+		t.Errorf("got %v, want %v", len(data.RequiredPermissions), 2)
+	} else {
+		if permission := data.RequiredPermissions[0]; permission.Value != "RoleManagement.ReadWrite.Directory" || !permission.IsTierZero {
+			t.Errorf("got %v, want a resolved tier zero permission", permission)
+		}
+		if permission := data.RequiredPermissions[1]; permission.Value != "User.Read" || permission.IsTierZero {
+			t.Errorf("got %v, want a resolved non tier zero permission", permission)
+		}
+	}
+
+	if _, ok := <-channel; ok {
+		t.Error("expected channel to close but it did not")
+	}
+}
+
+func TestListPermissionResources(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ctx := context.Background()
+
+	var (
+		mockClient  = mocks.NewMockAzureClient(ctrl)
+		mockChannel = make(chan azure.ServicePrincipalResult)
+		roleId      = uuid.Must(uuid.NewV4())
+	)
+	mockClient.EXPECT().ListAzureADServicePrincipals(gomock.Any(), "", "", "", "", []string{"appId", "appRoles", "oauth2PermissionScopes"}).Return(mockChannel)
+
+	go func() {
+		defer close(mockChannel)
+		mockChannel <- azure.ServicePrincipalResult{
+			Ok: azure.ServicePrincipal{
+				AppId:    constants.MicrosoftGraphAppID,
+				AppRoles: []azure.AppRole{{Id: roleId, Value: "RoleManagement.ReadWrite.Directory"}},
+			},
+		}
+	}()
+
+	resolver := newPermissionResolver(ctx, listPermissionResources(ctx, mockClient))
+	if permission := resolver.Resolve(constants.MicrosoftGraphAppID, azure.ResourceAccess{Id: roleId, Type: enums.AccessTypeRole}); permission.Value != "RoleManagement.ReadWrite.Directory" {
+		t.Errorf("got %v, want the permission exposed by the listed service principal", permission)
+	}
+}
@@ -18,6 +18,9 @@
 package cmd
 
 import (
+	"context"
+	"sync"
+
 	"github.com/go-logr/logr"
 	"github.com/spf13/cobra"
 
@@ -32,6 +35,11 @@ var (
 		Version: constants.Version,
 	}
 	log logr.Logger
+
+	// stopCtx is the parent of every command's context and is cancelled when the collection cannot continue
+	stopCtx, cancelStop = context.WithCancel(context.Background())
+	stopErr             error
+	stopOnce            sync.Once
 )
 
 func init() {
@@ -39,9 +47,17 @@ func init() {
 }
 
 func Execute() error {
-	return rootCmd.Execute()
+	return rootCmd.ExecuteContext(stopCtx)
 }
 
 func StartService() error {
-	return startCmd.Execute()
+	return startCmd.ExecuteContext(stopCtx)
+}
+
+// stopCollection cancels all running collectors. The error is reported when the command shuts down.
+func stopCollection(err error) {
+	stopOnce.Do(func() {
+		stopErr = err
+		cancelStop()
+	})
 }
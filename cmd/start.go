@@ -44,6 +44,7 @@ const (
 
 func init() {
 	configs := append(config.AzureConfig, config.BloodHoundEnterpriseConfig...)
+	configs = append(configs, config.CollectionConfig...)
 	config.Init(startCmd, configs)
 	rootCmd.AddCommand(startCmd)
 }
@@ -75,13 +76,14 @@ func start(ctx context.Context) {
 	log.V(1).Info("testing connections")
 	if err := testConnections(); err != nil {
 		exit(err)
-	} else if azClient, err := newAzureClient(); err != nil {
+	} else if azClients, err := newAzureClients(); err != nil {
 		exit(err)
 	} else if bheInstance, err := url.Parse(config.BHEUrl.Value().(string)); err != nil {
 		exit(err)
 	} else if bheClient, err := newSigningHttpClient(BHEAuthSignature, config.BHETokenId.Value().(string), config.BHEToken.Value().(string), config.Proxy.Value().(string)); err != nil {
 		exit(err)
 	} else {
+		runPreflight(ctx, azClients)
 
 		if err := updateClient(ctx, *bheInstance, bheClient); err != nil {
 			exit(err)
@@ -132,11 +134,14 @@ func start(ctx context.Context) {
 								start := time.Now()
 
 								// Batch data out for ingestion
-								stream := listAll(ctx, azClient)
+								stream := listAllTenants(ctx, azClients)
 								batches := pipeline.Batch(ctx.Done(), stream, 999, 10*time.Second)
 								if err := ingest(ctx, *bheInstance, bheClient, batches); err != nil {
+									discardDeltaLinks()
 									log.Error(err, "ingestion failed; collection will be re-attempted")
 								} else {
+									saveDeltaLinks(ctx)
+
 									// Notify BHE instance of task end
 									duration := time.Since(start)
 									endTask(ctx, *bheInstance, bheClient)
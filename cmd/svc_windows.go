@@ -44,7 +44,11 @@ func (s *azurehoundSvc) Init(env svc.Environment) error {
 	} else {
 		log = *logr
 
-		config.LoadValues(nil, config.Options())
+		if err := config.LoadValues(nil, config.Options()); err != nil {
+			return err
+		} else if err := resolveCloud(); err != nil {
+			return err
+		}
 		config.SetAzureDefaults()
 
 		if config.ConfigFileUsed() != "" {
@@ -0,0 +1,137 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	client_config "github.com/bloodhoundad/azurehound/client/config"
+	"github.com/bloodhoundad/azurehound/client/rest"
+	"github.com/bloodhoundad/azurehound/config"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	configs := append(config.AzureConfig, config.TokenConfig...)
+	config.Init(tokenCmd, configs)
+	rootCmd.AddCommand(tokenCmd)
+}
+
+var tokenCmd = &cobra.Command{
+	Use:               "token",
+	Short:             "Acquires an access token with the configured credentials and prints it as JSON",
+	Run:               tokenCmdImpl,
+	PersistentPreRunE: persistentPreRunE,
+	SilenceUsage:      true,
+}
+
+func tokenCmdImpl(cmd *cobra.Command, args []string) {
+	if len(args) > 0 {
+		exit(fmt.Errorf("unsupported subcommand: %v", args))
+	}
+
+	// an interrupt stops waiting for a device code sign in
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, os.Kill)
+	defer stop()
+
+	if tenant, err := singleTenant(); err != nil {
+		exit(err)
+	} else if clientConfig, err := newClientConfig(tenant); err != nil {
+		exit(err)
+	} else if audience, err := tokenAudience(config.TokenAudience.Value().(string), clientConfig); err != nil {
+		exit(err)
+	} else if restClient, err := rest.NewRestClient(audience, clientConfig); err != nil {
+		exit(err)
+	} else if token, err := restClient.Token(ctx); err != nil {
+		exit(fmt.Errorf("unable to acquire a token for %s: %w", audience, err))
+	} else if err := writeToken(newTokenOutput(tenant, audience, token, config.IncludeRefreshToken.Value().(bool))); err != nil {
+		exit(err)
+	}
+}
+
+// tokenOutput is what the token command prints. AccessToken can be passed straight to --jwt.
+type tokenOutput struct {
+	TenantId     string    `json:"tenantId"`
+	Audience     string    `json:"audience"`
+	AccessToken  string    `json:"accessToken"`
+	RefreshToken string    `json:"refreshToken,omitempty"`
+	ExpiresOn    time.Time `json:"expiresOn"`
+}
+
+func newTokenOutput(tenant, audience string, token rest.Token, includeRefreshToken bool) tokenOutput {
+	output := tokenOutput{
+		TenantId:    tenant,
+		Audience:    audience,
+		AccessToken: token.AccessToken(),
+		ExpiresOn:   token.Expires().UTC(),
+	}
+	if includeRefreshToken {
+		output.RefreshToken = token.RefreshToken()
+	}
+	return output
+}
+
+// tokenAudience resolves the well-known audience names against the configured cloud; anything else must be an absolute URL
+func tokenAudience(audience string, clientConfig client_config.Config) (string, error) {
+	switch strings.ToLower(audience) {
+	case "", "graph":
+		return clientConfig.GraphUrl(), nil
+	case "arm":
+		return clientConfig.ResourceManagerUrl(), nil
+	case "vault":
+		if suffix := config.AzKeyVaultSuffix.Value().(string); suffix == "" {
+			return "", fmt.Errorf("the key vault DNS suffix of this cloud is unknown; set --%s", config.AzKeyVaultSuffix.Name)
+		} else {
+			return "https://" + strings.TrimPrefix(suffix, "."), nil
+		}
+	default:
+		if parsed, err := url.Parse(audience); err != nil {
+			return "", fmt.Errorf("invalid audience %q: %w", audience, err)
+		} else if !parsed.IsAbs() || parsed.Host == "" {
+			return "", fmt.Errorf("invalid audience %q: expected graph, arm, vault or an absolute URL", audience)
+		} else {
+			return audience, nil
+		}
+	}
+}
+
+func writeToken(output tokenOutput) error {
+	if path := config.OutputFile.Value().(string); path != "" {
+		if file, err := os.OpenFile(path, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0600); err != nil {
+			return err
+		} else {
+			defer file.Close()
+			return encodeToken(file, output)
+		}
+	} else {
+		return encodeToken(os.Stdout, output)
+	}
+}
+
+func encodeToken(w io.Writer, output tokenOutput) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(output)
+}
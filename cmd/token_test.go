@@ -0,0 +1,85 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	client_config "github.com/bloodhoundad/azurehound/client/config"
+	"github.com/bloodhoundad/azurehound/config"
+	"github.com/bloodhoundad/azurehound/constants"
+)
+
+func init() {
+	setupLogger()
+}
+
+func TestTokenAudience(t *testing.T) {
+	defer config.AzKeyVaultSuffix.Set(config.AzKeyVaultSuffix.Default)
+	config.AzKeyVaultSuffix.Set("vault.usgovcloudapi.net")
+
+	clientConfig := client_config.Config{Region: constants.USGovL4}
+	for audience, expected := range map[string]string{
+		"":                          constants.AzureUSGovernment().MicrosoftGraphUrl,
+		"graph":                     constants.AzureUSGovernment().MicrosoftGraphUrl,
+		"ARM":                       constants.AzureUSGovernment().ResourceManagerUrl,
+		"vault":                     "https://vault.usgovcloudapi.net",
+		"https://storage.azure.com": "https://storage.azure.com",
+	} {
+		if actual, err := tokenAudience(audience, clientConfig); err != nil {
+			t.Errorf("%q: %v", audience, err)
+		} else if actual != expected {
+			t.Errorf("%q: got %s, want %s", audience, actual, expected)
+		}
+	}
+
+	for _, audience := range []string{"storage", "/relative", "https://"} {
+		if _, err := tokenAudience(audience, clientConfig); err == nil {
+			t.Errorf("%q: expected an error", audience)
+		}
+	}
+
+	config.AzKeyVaultSuffix.Set("")
+	if _, err := tokenAudience("vault", clientConfig); err == nil {
+		t.Error("expected an error without a key vault suffix")
+	}
+}
+
+func TestTokenOutput(t *testing.T) {
+	var token = testToken(t, map[string]interface{}{"aud": "https://graph.microsoft.com"})
+
+	var buf bytes.Buffer
+	if err := encodeToken(&buf, newTokenOutput("tenant", "https://graph.microsoft.com", token, false)); err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatal(err)
+	} else if decoded["accessToken"] != token.AccessToken() {
+		t.Errorf("got access token %v", decoded["accessToken"])
+	} else if decoded["tenantId"] != "tenant" || decoded["audience"] != "https://graph.microsoft.com" {
+		t.Errorf("unexpected output %v", decoded)
+	} else if _, ok := decoded["expiresOn"]; !ok {
+		t.Error("expected expiresOn")
+	} else if _, ok := decoded["refreshToken"]; ok {
+		t.Error("expected no refresh token")
+	}
+}
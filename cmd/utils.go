@@ -63,7 +63,13 @@ func persistentPreRunE(cmd *cobra.Command, args []string) error {
 		}
 	}
 
-	config.LoadValues(cmd, config.Options())
+	if err := config.LoadValues(cmd, config.Options()); err != nil {
+		return err
+	} else if err := resolveCloud(); err != nil {
+		return err
+	} else if err := validateConcurrencyOverrides(); err != nil {
+		return err
+	}
 	config.SetAzureDefaults()
 
 	if logr, err := logger.GetLogger(); err != nil {
@@ -85,12 +91,19 @@ func persistentPreRunE(cmd *cobra.Command, args []string) error {
 
 func gracefulShutdown(stop context.CancelFunc) {
 	stop()
+	if stopCtx.Err() != nil {
+		exit(stopErr)
+	}
 	fmt.Fprintln(os.Stderr, "\nshutting down gracefully, press ctrl+c again to force")
 	// TODO timeout context
 }
 
+// testConnections checks that the Azure APIs can be reached, unless requests are replayed from a recording which needs
+// no network access
 func testConnections() error {
-	if _, err := dial(config.AzAuthUrl.Value().(string)); err != nil {
+	if replay, _ := config.Replay.Value().(string); replay != "" {
+		return nil
+	} else if _, err := dial(config.AzAuthUrl.Value().(string)); err != nil {
 		return fmt.Errorf("unable to connect to %s: %w", config.AzAuthUrl.Value(), err)
 	} else if _, err := dial(config.AzGraphUrl.Value().(string)); err != nil {
 		return fmt.Errorf("unable to connect to %s: %w", config.AzGraphUrl.Value(), err)
@@ -210,17 +223,84 @@ func dial(targetUrl string) (string, error) {
 	}
 }
 
+// checkTokenExpiry logs how long each provided JWT lasts. Without a refresh token to replace them, an expired JWT is an
+// error and the collection is stopped when one expires mid-run.
+func checkTokenExpiry(jwts []string, canRefresh bool) error {
+	for _, jwt := range jwts {
+		if aud, err := rest.ParseAud(jwt); err != nil {
+			return err
+		} else if exp, err := rest.ParseExp(jwt); err != nil {
+			return err
+		} else if lifetime := time.Until(exp); canRefresh {
+			log.Info("using provided token until it expires, then the refresh token", "audience", aud, "expiresIn", lifetime.Round(time.Second).String())
+		} else if lifetime <= 0 {
+			return fmt.Errorf("the token for %s expired at %s", aud, exp.Format(time.RFC3339))
+		} else {
+			log.Info("using provided token", "audience", aud, "expiresIn", lifetime.Round(time.Second).String())
+			time.AfterFunc(lifetime, func() {
+				stopCollection(fmt.Errorf("the token for %s expired at %s, collection stopped before it completed; provide a new token or a refresh token and try again", aud, exp.Format(time.RFC3339)))
+			})
+		}
+	}
+	return nil
+}
+
+// newAzureClient creates a client for the one tenant commands other than list and start collect from
 func newAzureClient() (client.AzureClient, error) {
+	if tenant, err := singleTenant(); err != nil {
+		return nil, err
+	} else {
+		return newTenantClient(tenant)
+	}
+}
+
+func singleTenant() (string, error) {
+	if tenants := config.AzTenant.Value().([]string); len(tenants) != 1 {
+		return "", fmt.Errorf("expected a single tenant but got %d; use list or start to collect from several tenants", len(tenants))
+	} else {
+		return tenants[0], nil
+	}
+}
+
+// newAzureClients creates a client for each tenant to collect from
+func newAzureClients() ([]client.AzureClient, error) {
+	tenants := config.AzTenant.Value().([]string)
+	clients := make([]client.AzureClient, 0, len(tenants))
+	for _, tenant := range tenants {
+		if azClient, err := newTenantClient(tenant); err != nil {
+			return nil, fmt.Errorf("unable to create client for tenant %s: %w", tenant, err)
+		} else {
+			clients = append(clients, azClient)
+		}
+	}
+	return clients, nil
+}
+
+// newTenantClient creates a client for tenant using the settings from its section of the config file, if any
+func newTenantClient(tenant string) (client.AzureClient, error) {
+	if config, err := newClientConfig(tenant); err != nil {
+		return nil, err
+	} else {
+		return client.NewClient(config)
+	}
+}
+
+func newClientConfig(tenant string) (client_config.Config, error) {
+	overrides, err := config.TenantOverrides(tenant)
+	if err != nil {
+		return client_config.Config{}, err
+	}
+
 	var (
-		certFile   = config.AzCert.Value()
-		keyFile    = config.AzKey.Value()
+		certFile   = config.AzCert.ValueOf(overrides)
+		keyFile    = config.AzKey.ValueOf(overrides)
 		clientCert string
 		clientKey  string
 	)
 
 	if file, ok := certFile.(string); ok && file != "" {
 		if content, err := ioutil.ReadFile(certFile.(string)); err != nil {
-			return nil, fmt.Errorf("unable to read provided certificate: %w", err)
+			return client_config.Config{}, fmt.Errorf("unable to read provided certificate: %w", err)
 		} else {
 			clientCert = string(content)
 		}
@@ -229,32 +309,72 @@ func newAzureClient() (client.AzureClient, error) {
 
 	if file, ok := keyFile.(string); ok && file != "" {
 		if content, err := ioutil.ReadFile(keyFile.(string)); err != nil {
-			return nil, fmt.Errorf("unable to read provided key file: %w", err)
+			return client_config.Config{}, fmt.Errorf("unable to read provided key file: %w", err)
 		} else {
 			clientKey = string(content)
 		}
 	}
 
+	federatedTokenFile := config.AzFederatedTokenFile.ValueOf(overrides).(string)
+	if federatedTokenFile == "" && config.AzFederatedTokenCommand.ValueOf(overrides).(string) == "" {
+		// set by AKS workload identity and other projected token integrations
+		federatedTokenFile = os.Getenv("AZURE_FEDERATED_TOKEN_FILE")
+	}
+
+	jwts := config.JWT.ValueOf(overrides).([]string)
+	if err := checkTokenExpiry(jwts, config.RefreshToken.ValueOf(overrides).(string) != ""); err != nil {
+		return client_config.Config{}, err
+	}
+
+	maxBackoff, err := time.ParseDuration(config.MaxBackoff.ValueOf(overrides).(string))
+	if err != nil {
+		return client_config.Config{}, fmt.Errorf("invalid max backoff: %w", err)
+	}
+
 	config := client_config.Config{
-		ApplicationId:  config.AzAppId.Value().(string),
-		Authority:      config.AzAuthUrl.Value().(string),
-		ClientSecret:   config.AzSecret.Value().(string),
-		ClientCert:     clientCert,
-		ClientKey:      clientKey,
-		ClientKeyPass:  config.AzKeyPass.Value().(string),
-		Graph:          config.AzGraphUrl.Value().(string),
-		JWT:            config.JWT.Value().(string),
-		Management:     config.AzMgmtUrl.Value().(string),
-		MgmtGroupId:    config.AzMgmtGroupId.Value().([]string),
-		Password:       config.AzPassword.Value().(string),
-		ProxyUrl:       config.Proxy.Value().(string),
-		RefreshToken:   config.RefreshToken.Value().(string),
-		Region:         config.AzRegion.Value().(string),
-		SubscriptionId: config.AzSubId.Value().([]string),
-		Tenant:         config.AzTenant.Value().(string),
-		Username:       config.AzUsername.Value().(string),
-	}
-	return client.NewClient(config)
+		ApplicationId:         config.AzAppId.ValueOf(overrides).(string),
+		AuthMode:              config.AzAuthMode.ValueOf(overrides).(string),
+		Authority:             config.EndpointOf(config.AzAuthUrl, overrides),
+		ClientSecret:          config.AzSecret.ValueOf(overrides).(string),
+		ClientCert:            clientCert,
+		ClientKey:             clientKey,
+		ClientKeyPass:         config.AzKeyPass.ValueOf(overrides).(string),
+		FederatedTokenCommand: config.AzFederatedTokenCommand.ValueOf(overrides).(string),
+		FederatedTokenFile:    federatedTokenFile,
+		Graph:                 config.EndpointOf(config.AzGraphUrl, overrides),
+		IdentityEndpoint:      os.Getenv("IDENTITY_ENDPOINT"),
+		IdentityHeader:        os.Getenv("IDENTITY_HEADER"),
+		JWT:                   jwts,
+		Management:            config.EndpointOf(config.AzMgmtUrl, overrides),
+		MaxBackoff:            maxBackoff,
+		MaxRetries:            config.MaxRetries.ValueOf(overrides).(int),
+		MSALCache:             config.AzMSALCache.ValueOf(overrides).(string),
+		MgmtGroupId:           config.AzMgmtGroupId.ValueOf(overrides).([]string),
+		Password:              config.AzPassword.ValueOf(overrides).(string),
+		ProxyUrl:              config.Proxy.ValueOf(overrides).(string),
+		RateLimit:             config.RateLimit.ValueOf(overrides).(int),
+		RateLimitBurst:        config.RateLimitBurst.ValueOf(overrides).(int),
+		RecordPath:            cassettePath(config.Record.Value().(string), tenant),
+		RefreshToken:          config.RefreshToken.ValueOf(overrides).(string),
+		Region:                config.AzRegion.ValueOf(overrides).(string),
+		ReplayPath:            cassettePath(config.Replay.Value().(string), tenant),
+		SubscriptionId:        config.AzSubId.ValueOf(overrides).([]string),
+		Tenant:                tenant,
+		TokenCache:            config.AzTokenCache.ValueOf(overrides).(string),
+		TokenCacheKey:         config.AzTokenCacheKey.ValueOf(overrides).(string),
+		Username:              config.AzUsername.ValueOf(overrides).(string),
+	}
+	return config, nil
+}
+
+// cassettePath is the file in a recording directory that holds the requests sent for name, e.g. a tenant, so that
+// tenants sharing API URLs don't answer each other's requests on replay
+func cassettePath(dir, name string) string {
+	if dir == "" {
+		return ""
+	} else {
+		return filepath.Join(dir, filepath.Base(name)+".jsonl")
+	}
 }
 
 func newSigningHttpClient(signature, tokenId, token, proxyUrl string) (*http.Client, error) {
@@ -375,13 +495,34 @@ func setupLogger() {
 
 // deprecated: use azureWrapper instead
 type AzureWrapper struct {
-	Kind enums.Kind  `json:"kind"`
-	Data interface{} `json:"data"`
+	Kind     enums.Kind  `json:"kind"`
+	Data     interface{} `json:"data"`
+	TenantId string      `json:"tenantId,omitempty"`
+}
+
+func (s AzureWrapper) withTenant(tenantId string) interface{} {
+	s.TenantId = tenantId
+	return s
 }
 
 type azureWrapper[T any] struct {
-	Kind enums.Kind `json:"kind"`
-	Data T          `json:"data"`
+	Kind     enums.Kind `json:"kind"`
+	Data     T          `json:"data"`
+	TenantId string     `json:"tenantId,omitempty"`
+}
+
+func (s azureWrapper[T]) withTenant(tenantId string) interface{} {
+	s.TenantId = tenantId
+	return s
+}
+
+// tagTenant attributes a collected item to the tenant it was collected from
+func tagTenant(item interface{}, tenantId string) interface{} {
+	if wrapper, ok := item.(interface{ withTenant(string) interface{} }); ok {
+		return wrapper.withTenant(tenantId)
+	} else {
+		return item
+	}
 }
 
 func NewAzureWrapper[T any](kind enums.Kind, data T) azureWrapper[T] {
@@ -400,6 +541,7 @@ func outputStream[T any](ctx context.Context, stream <-chan T) {
 	} else {
 		sinks.WriteToConsole(ctx, formatted)
 	}
+	saveDeltaLinks(ctx)
 }
 
 func kvRoleAssignmentFilter(roleId string) func(models.KeyVaultRoleAssignment) bool {
@@ -0,0 +1,71 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"testing"
+
+	"github.com/bloodhoundad/azurehound/config"
+	"github.com/bloodhoundad/azurehound/constants"
+	"github.com/spf13/viper"
+)
+
+func init() {
+	setupLogger()
+}
+
+func TestNewClientConfigRegion(t *testing.T) {
+	for _, setting := range []config.Config{config.AzRegion, config.AzAuthUrl, config.AzGraphUrl, config.AzMgmtUrl, config.AzKeyVaultSuffix, config.AzStorageSuffix} {
+		defer setting.Set(setting.Default)
+	}
+	config.AzRegion.Set(constants.Cloud)
+	config.SetAzureDefaults()
+
+	viper.Set("tenants", map[string]interface{}{
+		"gov.onmicrosoft.us": map[string]interface{}{
+			config.AzRegion.Name: constants.USGovL4,
+		},
+		"proxied.onmicrosoft.us": map[string]interface{}{
+			config.AzRegion.Name:   constants.USGovL4,
+			config.AzGraphUrl.Name: "https://graph.example.com",
+		},
+	})
+	defer viper.Set("tenants", nil)
+
+	var (
+		cloud = constants.AzureCloud()
+		gov   = constants.AzureUSGovernment()
+	)
+	tests := []struct {
+		tenant    string
+		authority string
+		graph     string
+		mgmt      string
+	}{
+		{"contoso.onmicrosoft.com", cloud.ActiveDirectoryAuthority, cloud.MicrosoftGraphUrl, cloud.ResourceManagerUrl},
+		{"gov.onmicrosoft.us", gov.ActiveDirectoryAuthority, gov.MicrosoftGraphUrl, gov.ResourceManagerUrl},
+		{"proxied.onmicrosoft.us", gov.ActiveDirectoryAuthority, "https://graph.example.com", gov.ResourceManagerUrl},
+	}
+	for _, test := range tests {
+		if got, err := newClientConfig(test.tenant); err != nil {
+			t.Fatal(err)
+		} else if got.Authority != test.authority || got.Graph != test.graph || got.Management != test.mgmt {
+			t.Errorf("got %s, %s and %s for %s; want %s, %s and %s", got.Authority, got.Graph, got.Management, test.tenant, test.authority, test.graph, test.mgmt)
+		}
+	}
+}
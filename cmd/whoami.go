@@ -0,0 +1,266 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/bloodhoundad/azurehound/client"
+	"github.com/bloodhoundad/azurehound/client/rest"
+	"github.com/bloodhoundad/azurehound/config"
+	"github.com/bloodhoundad/azurehound/constants"
+	"github.com/bloodhoundad/azurehound/models/azure"
+	"github.com/spf13/cobra"
+)
+
+func init() {
+	config.Init(whoamiCmd, config.AzureConfig)
+	rootCmd.AddCommand(whoamiCmd)
+}
+
+var whoamiCmd = &cobra.Command{
+	Use:               "whoami",
+	Short:             "Describes the identity AzureHound authenticates as and what it can see",
+	Run:               whoamiCmdImpl,
+	PersistentPreRunE: persistentPreRunE,
+	SilenceUsage:      true,
+}
+
+func whoamiCmdImpl(cmd *cobra.Command, args []string) {
+	if len(args) > 0 {
+		exit(fmt.Errorf("unsupported subcommand: %v", args))
+	}
+
+	ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, os.Kill)
+	defer gracefulShutdown(stop)
+
+	if azClients, err := newAzureClients(); err != nil {
+		exit(err)
+	} else {
+		for _, azClient := range azClients {
+			printIdentity(os.Stdout, whoami(ctx, azClient))
+		}
+	}
+}
+
+// identity describes a signed in principal. Whatever couldn't be looked up is explained in notes.
+type identity struct {
+	tenant         azure.Tenant
+	principalType  string
+	objectId       string
+	appId          string
+	name           string
+	tokens         []tokenInfo
+	permissions    []string
+	delegated      bool
+	directoryRoles []string
+	subscriptions  []subscriptionAccess
+	notes          []string
+}
+
+type tokenInfo struct {
+	audience string
+	expires  time.Time
+}
+
+// subscriptionAccess is the highest Azure RBAC role a principal holds over a whole subscription
+type subscriptionAccess struct {
+	subscription azure.Subscription
+	role         string
+	scope        string
+}
+
+// rbacRanks orders the built-in roles that matter most to attack paths, highest first
+var rbacRanks = []struct {
+	id   string
+	name string
+}{
+	{constants.OwnerRoleID, "Owner"},
+	{constants.UserAccessAdminRoleID, "User Access Administrator"},
+	{constants.ContributorRoleID, "Contributor"},
+	{constants.ReaderRoleID, "Reader"},
+}
+
+func whoami(ctx context.Context, client client.AzureClient) identity {
+	var (
+		result = identity{tenant: client.TenantInfo()}
+		claims map[string]interface{}
+	)
+
+	for _, get := range []func(context.Context) (rest.Token, error){client.GraphToken, client.ResourceManagerToken} {
+		if token, err := get(ctx); err != nil {
+			result.notes = append(result.notes, fmt.Sprintf("unable to get token: %v", err))
+		} else if tokenClaims, err := token.Claims(); err != nil {
+			result.notes = append(result.notes, fmt.Sprintf("unable to read token: %v", err))
+		} else {
+			aud, _ := tokenClaims["aud"].(string)
+			result.tokens = append(result.tokens, tokenInfo{aud, token.Expires()})
+			if claims == nil {
+				claims = tokenClaims
+				result.permissions, result.delegated, _ = graphPermissions(token)
+			}
+		}
+	}
+
+	if claims == nil {
+		return result
+	}
+	result.objectId, _ = claims["oid"].(string)
+	result.appId, _ = claims["appid"].(string)
+
+	if result.delegated {
+		result.principalType = "User"
+		if user, err := client.GetAzureADUser(ctx, result.objectId, []string{"displayName", "userPrincipalName"}); err != nil {
+			result.notes = append(result.notes, fmt.Sprintf("unable to look up the signed in user: %s", preflightReason(err)))
+		} else {
+			result.name = fmt.Sprintf("%s (%s)", user.DisplayName, user.UserPrincipalName)
+		}
+	} else {
+		result.principalType = "ServicePrincipal"
+		if sp, err := client.GetAzureADServicePrincipal(ctx, result.objectId, []string{"displayName", "appId"}); err != nil {
+			result.notes = append(result.notes, fmt.Sprintf("unable to look up the service principal: %s", preflightReason(err)))
+		} else {
+			result.name = sp.DisplayName
+		}
+	}
+
+	if roles, err := directoryRoles(ctx, client, result.objectId); err != nil {
+		result.notes = append(result.notes, fmt.Sprintf("unable to list directory roles: %s", preflightReason(err)))
+	} else {
+		result.directoryRoles = roles
+	}
+
+	for item := range client.ListAzureSubscriptions(ctx) {
+		if item.Error != nil {
+			result.notes = append(result.notes, fmt.Sprintf("unable to list subscriptions: %s", preflightReason(item.Error)))
+			break
+		} else if access, err := highestRole(ctx, client, item.Ok, result.objectId); err != nil {
+			result.notes = append(result.notes, fmt.Sprintf("unable to list role assignments of %s: %s", item.Ok.DisplayName, preflightReason(err)))
+		} else {
+			result.subscriptions = append(result.subscriptions, access)
+		}
+	}
+	return result
+}
+
+// directoryRoles returns the names of the Entra roles assigned to the principal directly
+func directoryRoles(ctx context.Context, client client.AzureClient, objectId string) ([]string, error) {
+	filter := fmt.Sprintf("principalId eq '%s'", objectId)
+	if assignments, err := client.GetAzureADRoleAssignments(ctx, filter, "", "", "", nil, 0, false); err != nil {
+		return nil, err
+	} else if len(assignments.Value) == 0 {
+		return nil, nil
+	} else if roles, err := client.GetAzureADRoles(ctx, "", ""); err != nil {
+		return nil, err
+	} else {
+		names := map[string]string{}
+		for _, role := range roles.Value {
+			names[role.Id] = role.DisplayName
+		}
+
+		result := []string{}
+		for _, assignment := range assignments.Value {
+			if name, ok := names[assignment.RoleDefinitionId]; ok {
+				result = append(result, name)
+			} else {
+				result = append(result, assignment.RoleDefinitionId)
+			}
+		}
+		return unique(result), nil
+	}
+}
+
+// highestRole finds the highest role the principal holds, directly or through a group, over the entire subscription
+func highestRole(ctx context.Context, client client.AzureClient, subscription azure.Subscription, objectId string) (subscriptionAccess, error) {
+	var (
+		result = subscriptionAccess{subscription: subscription}
+		scope  = "/subscriptions/" + subscription.SubscriptionId
+		rank   = len(rbacRanks) + 1
+	)
+
+	assignments, err := client.GetRoleAssignmentsForResource(ctx, scope, fmt.Sprintf("assignedTo('%s')", objectId))
+	if err != nil {
+		return result, err
+	}
+
+	for _, assignment := range assignments.Value {
+		// skip assignments to resource groups and resources within the subscription
+		if assignmentScope := assignment.Properties.Scope; strings.HasPrefix(strings.ToLower(assignmentScope), strings.ToLower(scope)+"/") {
+			continue
+		} else {
+			roleId := path.Base(assignment.Properties.RoleDefinitionId)
+			assignmentRank, name := len(rbacRanks), roleId
+			for i, role := range rbacRanks {
+				if role.id == roleId {
+					assignmentRank, name = i, role.name
+				}
+			}
+			if assignmentRank < rank {
+				rank, result.role, result.scope = assignmentRank, name, assignmentScope
+			}
+		}
+	}
+	return result, nil
+}
+
+func printIdentity(w io.Writer, identity identity) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Tenant:\t%s (%s)\n", identity.tenant.DisplayName, identity.tenant.TenantId)
+	fmt.Fprintf(tw, "Principal:\t%s %s\n", identity.principalType, identity.name)
+	fmt.Fprintf(tw, "Object ID:\t%s\n", identity.objectId)
+	if identity.appId != "" {
+		fmt.Fprintf(tw, "App ID:\t%s\n", identity.appId)
+	}
+	for _, token := range identity.tokens {
+		fmt.Fprintf(tw, "Token:\t%s, expires %s (in %s)\n", token.audience, token.expires.Format(time.RFC3339), time.Until(token.expires).Round(time.Second))
+	}
+	if identity.delegated {
+		fmt.Fprintf(tw, "Delegated scopes:\t%s\n", strings.Join(identity.permissions, ", "))
+	} else {
+		fmt.Fprintf(tw, "App roles:\t%s\n", strings.Join(identity.permissions, ", "))
+	}
+	fmt.Fprintf(tw, "Directory roles:\t%s\n", strings.Join(identity.directoryRoles, ", "))
+	tw.Flush()
+
+	if len(identity.subscriptions) > 0 {
+		fmt.Fprintln(w)
+		tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "SUBSCRIPTION\tID\tROLE\tSCOPE")
+		for _, access := range identity.subscriptions {
+			role := access.role
+			if role == "" {
+				role = "-"
+			}
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", access.subscription.DisplayName, access.subscription.SubscriptionId, role, access.scope)
+		}
+		tw.Flush()
+	}
+
+	for _, note := range identity.notes {
+		fmt.Fprintf(w, "note: %s\n", note)
+	}
+	fmt.Fprintln(w)
+}
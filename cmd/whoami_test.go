@@ -0,0 +1,83 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package cmd
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/bloodhoundad/azurehound/client/mocks"
+	"github.com/bloodhoundad/azurehound/constants"
+	"github.com/bloodhoundad/azurehound/models/azure"
+	"github.com/golang/mock/gomock"
+)
+
+func init() {
+	setupLogger()
+}
+
+func testRoleAssignment(scope, roleId string) azure.RoleAssignment {
+	return azure.RoleAssignment{
+		Properties: azure.RoleAssignmentPropertiesWithScope{
+			Scope:            scope,
+			RoleDefinitionId: scope + "/providers/Microsoft.Authorization/roleDefinitions/" + roleId,
+		},
+	}
+}
+
+func TestHighestRole(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ctx := context.Background()
+
+	mockClient := mocks.NewMockAzureClient(ctrl)
+	mockClient.EXPECT().GetRoleAssignmentsForResource(gomock.Any(), "/subscriptions/sub", "assignedTo('principal')").Return(azure.RoleAssignmentList{
+		Value: []azure.RoleAssignment{
+			testRoleAssignment("/subscriptions/sub/resourceGroups/rg", constants.OwnerRoleID),
+			testRoleAssignment("/subscriptions/sub", constants.ReaderRoleID),
+			testRoleAssignment("/providers/Microsoft.Management/managementGroups/root", constants.ContributorRoleID),
+		},
+	}, nil)
+
+	if access, err := highestRole(ctx, mockClient, azure.Subscription{SubscriptionId: "sub"}, "principal"); err != nil {
+		t.Fatal(err)
+	} else if access.role != "Contributor" || access.scope != "/providers/Microsoft.Management/managementGroups/root" {
+		t.Errorf("got %s at %s; want Contributor inherited from the management group", access.role, access.scope)
+	}
+}
+
+func TestDirectoryRoles(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ctx := context.Background()
+
+	mockClient := mocks.NewMockAzureClient(ctrl)
+	mockClient.EXPECT().GetAzureADRoleAssignments(gomock.Any(), "principalId eq 'principal'", gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(azure.UnifiedRoleAssignmentList{
+		Value: []azure.UnifiedRoleAssignment{{RoleDefinitionId: "role"}, {RoleDefinitionId: "custom"}, {RoleDefinitionId: "role"}},
+	}, nil)
+	mockClient.EXPECT().GetAzureADRoles(gomock.Any(), gomock.Any(), gomock.Any()).Return(azure.RoleList{
+		Value: []azure.Role{{DirectoryObject: azure.DirectoryObject{Id: "role"}, DisplayName: "Global Reader"}},
+	}, nil)
+
+	if roles, err := directoryRoles(ctx, mockClient, "principal"); err != nil {
+		t.Fatal(err)
+	} else if want := []string{"Global Reader", "custom"}; !reflect.DeepEqual(roles, want) {
+		t.Errorf("got %v; want %v", roles, want)
+	}
+}
@@ -30,6 +30,38 @@ import (
 )
 
 type Config = config.Config
+type Overrides = config.Overrides
+
+// Collectors are the collectors whose concurrency --concurrency-overrides can set
+var Collectors = []string{
+	"app-owners",
+	"app-role-assignments",
+	"automation-account-role-assignments",
+	"automation-accounts",
+	"device-owners",
+	"function-app-role-assignments",
+	"function-apps",
+	"group-members",
+	"group-owners",
+	"key-vault-role-assignments",
+	"key-vaults",
+	"management-group-descendants",
+	"management-group-role-assignments",
+	"resource-group-role-assignments",
+	"resource-groups",
+	"resource-role-assignments",
+	"resources",
+	"role-assignments",
+	"service-principal-owners",
+	"storage-account-role-assignments",
+	"storage-accounts",
+	"storage-containers",
+	"subscription-role-assignments",
+	"virtual-machine-role-assignments",
+	"virtual-machines",
+	"workflow-role-assignments",
+	"workflows",
+}
 
 var (
 	homeDir, _ = os.UserHomeDir()
@@ -86,6 +118,12 @@ var (
 		Persistent: true,
 		Default:    DefaultConfigFile,
 	}
+	Profile = Config{
+		Name:       "profile",
+		Usage:      "Named profile from the \"profiles\" section of the configuration file to use. Its settings override those outside of the section.",
+		Persistent: true,
+		Default:    "",
+	}
 	VerbosityLevel = Config{
 		Name:       "verbosity",
 		Shorthand:  "v",
@@ -103,9 +141,9 @@ var (
 	JWT = Config{
 		Name:       "jwt",
 		Shorthand:  "j",
-		Usage:      "Use an acquired JWT to authenticate into Azure",
+		Usage:      "Use acquired JWTs to authenticate into Azure; repeat to provide both a Microsoft Graph and an Azure Resource Manager token",
 		Persistent: true,
-		Default:    "",
+		Default:    []string{},
 	}
 	LogFile = Config{
 		Name:       "log-file",
@@ -121,12 +159,55 @@ var (
 		Persistent: true,
 		Default:    "",
 	}
+	Record = Config{
+		Name:       "record",
+		Shorthand:  "",
+		Usage:      "The directory in which to record every request and response, with credentials scrubbed, for replay",
+		Persistent: true,
+		Default:    "",
+	}
+	Replay = Config{
+		Name:       "replay",
+		Shorthand:  "",
+		Usage:      "The directory of a recording to answer requests from instead of the Azure APIs",
+		Persistent: true,
+		Default:    "",
+	}
+	MaxRetries = Config{
+		Name:       "max-retries",
+		Shorthand:  "",
+		Usage:      "The number of times a throttled, failed or timed out request is retried after its first attempt",
+		Persistent: true,
+		Default:    2,
+	}
+	MaxBackoff = Config{
+		Name:       "max-backoff",
+		Shorthand:  "",
+		Usage:      "The longest time to wait between retries of a request, e.g. \"90s\" or \"2m\"",
+		Persistent: true,
+		Default:    "2m",
+	}
+	RateLimit = Config{
+		Name:       "rate-limit",
+		Shorthand:  "",
+		Usage:      "The number of requests per second sent to each API host and Azure resource provider, slowed down automatically when throttled. By default requests are sent as fast as the API allows and slowed down once it pushes back",
+		Persistent: true,
+		Default:    0,
+	}
+	RateLimitBurst = Config{
+		Name:       "rate-limit-burst",
+		Shorthand:  "",
+		Usage:      "The number of requests that may be sent at once before the rate limit applies",
+		Persistent: true,
+		Default:    40,
+	}
 	RefreshToken = Config{
 		Name:       "refresh-token",
 		Shorthand:  "r",
 		Usage:      "Use an acquired refresh token to authenticate into Azure",
 		Persistent: true,
 		Default:    "",
+		Sensitive:  true,
 	}
 
 	// Azure Configurations
@@ -143,27 +224,29 @@ var (
 		Usage:      "The Application Secret that was generated for the app in the app registration portal.",
 		Persistent: true,
 		Default:    "",
+		Sensitive:  true,
 	}
 	AzCert = Config{
 		Name:       "cert",
 		Shorthand:  "",
-		Usage:      "The path to the certificate uploaded to the app registration portal.",
+		Usage:      "The path to the certificate uploaded to the app registration portal. Either PEM, optionally bundling its chain and key, or PKCS#12 (.pfx).",
 		Persistent: true,
 		Default:    "",
 	}
 	AzKey = Config{
 		Name:       "key",
 		Shorthand:  "k",
-		Usage:      "The path to the key file for a certificate uploaded to the app registration portal.",
+		Usage:      "The path to the key file for a certificate uploaded to the app registration portal. Not needed if --cert includes the key.",
 		Persistent: true,
 		Default:    "",
 	}
 	AzKeyPass = Config{
 		Name:       "keypass",
 		Shorthand:  "",
-		Usage:      "The passphrase to use in conjuction with --key ${key file}.",
+		Usage:      "The passphrase to use in conjuction with --key ${key file} or a PKCS#12 --cert ${pfx file}.",
 		Persistent: true,
 		Default:    "",
+		Sensitive:  true,
 	}
 	AzRegion = Config{
 		Name:       "region",
@@ -175,10 +258,10 @@ var (
 	AzTenant = Config{
 		Name:       "tenant",
 		Shorthand:  "t",
-		Usage:      "The directory tenant that you want to request permission from. This can be in GUID or friendly name format.",
+		Usage:      "The directory tenant that you want to request permission from. This can be in GUID or friendly name format. Can be specified multiple times to collect from several tenants, with settings specific to each given under the \"tenants\" section of the config file, or taken from the profile its \"profile\" setting names.",
 		Required:   true,
 		Persistent: true,
-		Default:    "",
+		Default:    []string{},
 	}
 	AzAuthUrl = Config{
 		Name:       "auth",
@@ -187,6 +270,49 @@ var (
 		Persistent: true,
 		Default:    "",
 	}
+	AzAuthMode = Config{
+		Name:       "auth-mode",
+		Shorthand:  "",
+		Usage:      "Acquire tokens with the given flow instead of the provided credentials. Supported: managed-identity, device-code, workload-identity, azure-cli",
+		Persistent: true,
+		Default:    "",
+	}
+	AzTokenCache = Config{
+		Name:       "token-cache",
+		Shorthand:  "",
+		Usage:      "The path of an encrypted file to keep the device code sign in in between runs",
+		Persistent: true,
+		Default:    "",
+	}
+	AzTokenCacheKey = Config{
+		Name:       "token-cache-key",
+		Shorthand:  "",
+		Usage:      "The passphrase used to encrypt the token cache",
+		Persistent: true,
+		Default:    "",
+		Sensitive:  true,
+	}
+	AzFederatedTokenFile = Config{
+		Name:       "federated-token-file",
+		Shorthand:  "",
+		Usage:      "The file holding the OIDC token exchanged by workload identity federation. Defaults to AZURE_FEDERATED_TOKEN_FILE",
+		Persistent: true,
+		Default:    "",
+	}
+	AzFederatedTokenCommand = Config{
+		Name:       "federated-token-command",
+		Shorthand:  "",
+		Usage:      "A command printing the OIDC token exchanged by workload identity federation, run whenever a new token is needed",
+		Persistent: true,
+		Default:    "",
+	}
+	AzMSALCache = Config{
+		Name:       "msal-cache",
+		Shorthand:  "",
+		Usage:      "The Azure CLI or exported MSAL token cache used by --auth-mode azure-cli. Defaults to ~/.azure/msal_token_cache.json",
+		Persistent: true,
+		Default:    "",
+	}
 	AzGraphUrl = Config{
 		Name:       "graph",
 		Shorthand:  "",
@@ -201,6 +327,34 @@ var (
 		Persistent: true,
 		Default:    "",
 	}
+	AzKeyVaultSuffix = Config{
+		Name:       "keyvault-suffix",
+		Shorthand:  "",
+		Usage:      "The DNS suffix of key vaults in the Azure Cloud deployment, e.g. vault.azure.net, used by the token command; collectors only call Azure Resource Manager",
+		Persistent: true,
+		Default:    "",
+	}
+	AzStorageSuffix = Config{
+		Name:       "storage-suffix",
+		Shorthand:  "",
+		Usage:      "The DNS suffix of storage accounts in the Azure Cloud deployment, e.g. core.windows.net",
+		Persistent: true,
+		Default:    "",
+	}
+	AzCloudMetadataUrl = Config{
+		Name:       "cloud-metadata-url",
+		Shorthand:  "",
+		Usage:      "The URL of an Azure Resource Manager to discover the endpoints of its cloud from, e.g. for Azure Stack Hub",
+		Persistent: true,
+		Default:    "",
+	}
+	AzCloudConfig = Config{
+		Name:       "cloud-config",
+		Shorthand:  "",
+		Usage:      "A JSON file with the output of an Azure Resource Manager metadata endpoint, for clouds that can't be reached beforehand",
+		Persistent: true,
+		Default:    "",
+	}
 	AzUsername = Config{
 		Name:       "username",
 		Shorthand:  "u",
@@ -214,6 +368,7 @@ var (
 		Usage:      "The user's password for the Azure Portal",
 		Persistent: true,
 		Default:    "",
+		Sensitive:  true,
 	}
 	AzSubId = Config{
 		Name:       "subscriptionId",
@@ -247,6 +402,7 @@ var (
 		Persistent: true,
 		Required:   true,
 		Default:    "",
+		Sensitive:  true,
 	}
 
 	BHETokenId = Config{
@@ -267,6 +423,70 @@ var (
 		Default:    []enums.KeyVaultAccessType{},
 	}
 
+	IncludeDeleted = Config{
+		Name:       "include-deleted",
+		Shorthand:  "",
+		Usage:      "Include soft-deleted users, groups, applications and service principals when collecting Azure AD objects",
+		Persistent: true,
+		Default:    false,
+	}
+
+	SkipPreflight = Config{
+		Name:       "skip-preflight",
+		Shorthand:  "",
+		Usage:      "Skip checking which objects the credentials can collect before collection starts",
+		Persistent: true,
+		Default:    false,
+	}
+
+	IncludeResources = Config{
+		Name:       "include-resources",
+		Shorthand:  "",
+		Usage:      "Include every Azure resource as a generic resource, along with the role assignments of the --resource-types, when collecting Azure RM objects",
+		Persistent: true,
+		Default:    false,
+	}
+
+	ResourceTypes = Config{
+		Name:       "resource-types",
+		Shorthand:  "",
+		Usage:      "Collect role assignments for generic resources of one or more resource type (e.g. Microsoft.Web/sites)\n\tNote: may be used multiple times or values may be provided as comma-separated list\n",
+		Persistent: true,
+		Default:    []string{},
+	}
+
+	ResourceGraph = Config{
+		Name:       "resource-graph",
+		Shorthand:  "",
+		Usage:      "Collect resource groups, key vaults, virtual machines and their role assignments in bulk via Azure Resource Graph queries",
+		Persistent: true,
+		Default:    false,
+	}
+
+	DeltaState = Config{
+		Name:       "delta-state",
+		Shorthand:  "",
+		Usage:      "Path of a state file used to only collect users, groups, apps and service principals that changed since the previous collection",
+		Persistent: true,
+		Default:    "",
+	}
+
+	Concurrency = Config{
+		Name:       "concurrency",
+		Shorthand:  "",
+		Usage:      "The number of concurrent requests each collector sends while enumerating relationships",
+		Persistent: true,
+		Default:    25,
+	}
+
+	ConcurrencyOverrides = Config{
+		Name:       "concurrency-overrides",
+		Shorthand:  "",
+		Usage:      "Per-collector concurrency in the form <collector>=<n>, e.g. group-members=50,storage-containers=5. Collectors: " + strings.Join(Collectors, ", "),
+		Persistent: true,
+		Default:    []string{},
+	}
+
 	OutputFile = Config{
 		Name:       "output",
 		Shorthand:  "o",
@@ -275,13 +495,36 @@ var (
 		Default:    "",
 	}
 
+	TokenAudience = Config{
+		Name:       "audience",
+		Shorthand:  "",
+		Usage:      "The API to acquire a token for: graph, arm, vault or the URL of any other resource",
+		Persistent: true,
+		Default:    "graph",
+	}
+
+	IncludeRefreshToken = Config{
+		Name:       "include-refresh-token",
+		Shorthand:  "",
+		Usage:      "Include the refresh token, if one was issued, in the output",
+		Persistent: true,
+		Default:    false,
+	}
+
 	GlobalConfig = []Config{
 		ConfigFile,
+		Profile,
 		VerbosityLevel,
 		JsonLogs,
 		JWT,
 		LogFile,
 		Proxy,
+		Record,
+		Replay,
+		MaxRetries,
+		MaxBackoff,
+		RateLimit,
+		RateLimitBurst,
 		RefreshToken,
 	}
 
@@ -294,14 +537,41 @@ var (
 		AzRegion,
 		AzTenant,
 		AzAuthUrl,
+		AzAuthMode,
+		AzTokenCache,
+		AzTokenCacheKey,
+		AzFederatedTokenFile,
+		AzFederatedTokenCommand,
+		AzMSALCache,
 		AzGraphUrl,
 		AzMgmtUrl,
+		AzKeyVaultSuffix,
+		AzStorageSuffix,
+		AzCloudMetadataUrl,
+		AzCloudConfig,
 		AzUsername,
 		AzPassword,
 		AzSubId,
 		AzMgmtGroupId,
 	}
 
+	CollectionConfig = []Config{
+		IncludeDeleted,
+		IncludeResources,
+		ResourceTypes,
+		ResourceGraph,
+		DeltaState,
+		Concurrency,
+		ConcurrencyOverrides,
+		SkipPreflight,
+	}
+
+	TokenConfig = []Config{
+		TokenAudience,
+		IncludeRefreshToken,
+		OutputFile,
+	}
+
 	BloodHoundEnterpriseConfig = []Config{
 		BHEUrl,
 		BHETokenId,
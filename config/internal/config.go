@@ -36,11 +36,19 @@ type Config struct {
 	Required   bool
 	Persistent bool
 	Default    interface{}
+
+	// Sensitive values may be given as credential references (see ResolveCredential) and are masked in logs
+	Sensitive bool
 }
 
 func (s Config) Value() interface{} {
 	if reflect.ValueOf(s.Default).Kind() == reflect.Slice {
 		return viper.GetStringSlice(s.Name)
+	} else if credential, ok := resolvedCredential(s.Name); s.Sensitive && ok {
+		return credential
+	} else if _, ok := s.Default.(int); ok {
+		// numbers in a JSON config file are read as float64
+		return viper.GetInt(s.Name)
 	} else {
 		return viper.Get(s.Name)
 	}
@@ -48,6 +56,9 @@ func (s Config) Value() interface{} {
 
 func (s Config) Set(value interface{}) {
 	viper.Set(s.Name, value)
+	if s.Sensitive {
+		forgetCredential(s.Name)
+	}
 }
 
 type Options struct {
@@ -56,11 +67,17 @@ type Options struct {
 	ConfigType  string
 	ConfigPaths []string
 	EnvPrefix   string
+
+	// Profile names the profile of the config file to use, if any
+	Profile Config
 }
 
 func Init(cmd *cobra.Command, configs []Config) {
 	for _, config := range configs {
 		viper.SetDefault(config.Name, config.Default)
+		if config.Sensitive {
+			registerSensitive(config)
+		}
 		if cmd != nil {
 			if config.Persistent {
 				setFlag(config, cmd.PersistentFlags(), cmd.MarkPersistentFlagRequired)
@@ -90,7 +107,7 @@ func setFlag(config Config, flagSet *pflag.FlagSet, markRequired func(string) er
 	}
 }
 
-func LoadValues(cmd *cobra.Command, options Options) {
+func LoadValues(cmd *cobra.Command, options Options) error {
 	if cmd != nil {
 		viper.BindPFlags(cmd.Flags())
 	}
@@ -121,6 +138,10 @@ func LoadValues(cmd *cobra.Command, options Options) {
 		}
 	}
 
+	if err := applyProfile(options.Profile); err != nil {
+		return err
+	}
+
 	if cmd != nil {
 		// Ensure all required values that actually have been set don't return an error. (See https://github.com/spf13/viper/issues/397)
 		cmd.Flags().VisitAll(func(flag *pflag.Flag) {
@@ -135,6 +156,8 @@ func LoadValues(cmd *cobra.Command, options Options) {
 			}
 		})
 	}
+
+	return resolveCredentials()
 }
 
 func setConfigSearchPaths(name string, extension string, paths []string) {
@@ -84,6 +84,17 @@ func TestBarConfig(t *testing.T) {
 	}
 }
 
+func TestIntConfigFromJSON(t *testing.T) {
+	cmd.Execute()
+
+	// JSON config files hold numbers as float64
+	barConfig.Set(float64(3))
+
+	if actual := barConfig.Value(); actual != 3 {
+		t.Errorf("got %v (%T), want %v\n", actual, actual, 3)
+	}
+}
+
 func TestBazConfig(t *testing.T) {
 	cmd.Execute()
 
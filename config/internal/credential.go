@@ -0,0 +1,178 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package internal
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/bloodhoundad/azurehound/internal/shell"
+	"github.com/spf13/viper"
+)
+
+// Prefixes of credential references, which say where a sensitive value is kept instead of containing it
+const (
+	fileReference = "file:"
+	envReference  = "env:"
+	execReference = "exec:"
+)
+
+const redacted = "********"
+
+var (
+	credentialsMutex sync.RWMutex
+
+	// the sensitive configs registered by Init, keyed by name
+	sensitiveConfigs = map[string]Config{}
+
+	// the values sensitive configs resolved to when the configuration was loaded, keyed by name
+	credentials = map[string]string{}
+
+	// resolved values that don't belong to a config, such as those from a tenant's section of the config file
+	otherSecrets = []string{}
+
+	// the values Redact masks, longest first. They are collected whenever a sensitive value changes rather than for
+	// every log message so that logging never reads viper while it is being written to.
+	secrets = []string{}
+)
+
+// ResolveCredential returns the value a credential reference points to: the contents of a file for `file:/path`, an
+// environment variable for `env:VAR` or what a command prints for `exec:command`. Any other value is returned as is.
+func ResolveCredential(value string) (string, error) {
+	switch {
+	case strings.HasPrefix(value, fileReference):
+		if data, err := os.ReadFile(strings.TrimPrefix(value, fileReference)); err != nil {
+			return "", err
+		} else {
+			return strings.TrimRight(string(data), "\r\n"), nil
+		}
+	case strings.HasPrefix(value, envReference):
+		name := strings.TrimPrefix(value, envReference)
+		if env, ok := os.LookupEnv(name); !ok {
+			return "", fmt.Errorf("environment variable %s is not set", name)
+		} else {
+			return env, nil
+		}
+	case strings.HasPrefix(value, execReference):
+		return runCredentialProcess(strings.TrimPrefix(value, execReference))
+	default:
+		return value, nil
+	}
+}
+
+// runCredentialProcess runs command and returns what it printed, in the spirit of AWS' credential_process
+func runCredentialProcess(command string) (string, error) {
+	if output, err := shell.Run(command); err != nil {
+		return "", err
+	} else {
+		return strings.TrimRight(output, "\r\n"), nil
+	}
+}
+
+func registerSensitive(config Config) {
+	credentialsMutex.Lock()
+	defer credentialsMutex.Unlock()
+	sensitiveConfigs[config.Name] = config
+}
+
+// resolveCredentials resolves the credential references of every sensitive config. Viper keeps the references so
+// that writing the configuration back out, e.g. by `configure`, never writes a resolved secret.
+func resolveCredentials() error {
+	credentialsMutex.Lock()
+	defer credentialsMutex.Unlock()
+	defer updateSecrets()
+
+	for name := range sensitiveConfigs {
+		if value, ok := viper.Get(name).(string); !ok {
+			continue
+		} else if credential, err := ResolveCredential(value); err != nil {
+			return fmt.Errorf("unable to resolve credential reference for %s: %w", name, err)
+		} else {
+			credentials[name] = credential
+		}
+	}
+	return nil
+}
+
+func isSensitive(name string) bool {
+	credentialsMutex.RLock()
+	defer credentialsMutex.RUnlock()
+	_, ok := sensitiveConfigs[name]
+	return ok
+}
+
+func addSecret(value string) {
+	credentialsMutex.Lock()
+	defer credentialsMutex.Unlock()
+	otherSecrets = append(otherSecrets, value)
+	updateSecrets()
+}
+
+func resolvedCredential(name string) (string, bool) {
+	credentialsMutex.RLock()
+	defer credentialsMutex.RUnlock()
+	value, ok := credentials[name]
+	return value, ok
+}
+
+func forgetCredential(name string) {
+	credentialsMutex.Lock()
+	defer credentialsMutex.Unlock()
+	delete(credentials, name)
+	updateSecrets()
+}
+
+// updateSecrets collects the values of every sensitive config. Callers hold credentialsMutex for writing.
+func updateSecrets() {
+	values := []string{}
+	for name := range sensitiveConfigs {
+		if value, ok := credentials[name]; ok && value != "" {
+			values = append(values, value)
+		} else if value, ok := viper.Get(name).(string); ok && value != "" {
+			values = append(values, value)
+		}
+	}
+	for _, value := range otherSecrets {
+		if value != "" {
+			values = append(values, value)
+		}
+	}
+
+	// mask secrets that contain others in full
+	sort.Slice(values, func(i, j int) bool { return len(values[i]) > len(values[j]) })
+	secrets = values
+}
+
+// Secrets returns the values of every sensitive config as of when the configuration was loaded or a sensitive value
+// last changed, longest first. The slice is shared and must not be modified.
+func Secrets() []string {
+	credentialsMutex.RLock()
+	defer credentialsMutex.RUnlock()
+	return secrets
+}
+
+// Redact masks the value of every sensitive config in s
+func Redact(s string) string {
+	for _, secret := range Secrets() {
+		s = strings.ReplaceAll(s, secret, redacted)
+	}
+	return s
+}
@@ -0,0 +1,90 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestResolveCredential(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "secret")
+	if err := os.WriteFile(path, []byte("from-file\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("AZUREHOUND_TEST_SECRET", "from-env")
+
+	tests := map[string]string{
+		"plain":                      "plain",
+		"file:" + path:               "from-file",
+		"env:AZUREHOUND_TEST_SECRET": "from-env",
+	}
+	if runtime.GOOS != "windows" {
+		tests["exec:echo from-exec"] = "from-exec"
+	}
+
+	for reference, want := range tests {
+		if got, err := ResolveCredential(reference); err != nil {
+			t.Errorf("ResolveCredential(%s) failed: %v", reference, err)
+		} else if got != want {
+			t.Errorf("ResolveCredential(%s) = %s; want %s", reference, got, want)
+		}
+	}
+
+	if _, err := ResolveCredential("env:AZUREHOUND_TEST_UNSET"); err == nil {
+		t.Error("expected an error for an unset environment variable")
+	} else if _, err := ResolveCredential("file:" + filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("expected an error for a missing file")
+	}
+}
+
+func TestSensitiveConfig(t *testing.T) {
+	secretConfig := Config{
+		Name:      "test-secret",
+		Usage:     "configure a secret",
+		Default:   "",
+		Sensitive: true,
+	}
+	Init(nil, []Config{secretConfig})
+	t.Setenv("AZUREHOUND_TEST_SECRET", "hunter2")
+
+	secretConfig.Set("env:AZUREHOUND_TEST_SECRET")
+	if err := resolveCredentials(); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := secretConfig.Value(); got != "hunter2" {
+		t.Errorf("got %v; want the resolved secret", got)
+	} else if got := viper.Get(secretConfig.Name); got != "env:AZUREHOUND_TEST_SECRET" {
+		t.Errorf("viper holds %v; want the reference", got)
+	} else if got := Redact("password is hunter2"); got != "password is ********" {
+		t.Errorf("got %s; want the secret masked", got)
+	}
+
+	// setting a new value replaces the resolved one
+	secretConfig.Set("hunter3")
+	if got := secretConfig.Value(); got != "hunter3" {
+		t.Errorf("got %v; want the new value", got)
+	} else if got := Redact("password is hunter3"); got != "password is ********" {
+		t.Errorf("got %s; want the new secret masked", got)
+	}
+}
@@ -0,0 +1,67 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// the section of the config file holding named profiles, each of which overrides the settings outside of it
+const profilesSection = "profiles"
+
+// applyProfile merges the profile named by config, if any, over the settings read from the config file. Flags and
+// environment variables still take precedence over both.
+func applyProfile(config Config) error {
+	if config.Name == "" {
+		return nil
+	} else if name, _ := config.Value().(string); name == "" {
+		return nil
+	} else if profile, ok := viper.GetStringMap(profilesSection)[strings.ToLower(name)].(map[string]interface{}); !ok {
+		return fmt.Errorf("profile %s not found in %s", name, viper.ConfigFileUsed())
+	} else {
+		return viper.MergeConfigMap(profile)
+	}
+}
+
+// SaveProfile writes the values of configs to the named profile of the config file at path, or outside of the
+// profiles section if profile is empty. Everything else in the file is kept as is.
+func SaveProfile(path string, profile string, configs []Config) error {
+	prefix := ""
+	if strings.Contains(profile, ".") {
+		return fmt.Errorf("invalid profile name %s: profile names cannot contain '.'", profile)
+	} else if profile != "" {
+		prefix = profilesSection + "." + profile + "."
+	}
+
+	file := viper.New()
+	file.SetConfigFile(path)
+	if err := file.ReadInConfig(); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+
+	for _, config := range configs {
+		// viper holds credential references rather than what they resolve to
+		file.Set(prefix+config.Name, viper.Get(config.Name))
+	}
+	return file.WriteConfigAs(path)
+}
@@ -0,0 +1,95 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+const testProfiles = `{
+	"test-app": "default-app",
+	"test-url": "https://default",
+	"profiles": {
+		"contoso": {"test-app": "contoso-app"}
+	}
+}`
+
+func TestLoadValuesProfile(t *testing.T) {
+	var (
+		path          = filepath.Join(t.TempDir(), "config.json")
+		appConfig     = Config{Name: "test-app", Default: ""}
+		urlConfig     = Config{Name: "test-url", Default: ""}
+		profileConfig = Config{Name: "test-profile", Default: ""}
+		options       = Options{ConfigFile: path, Profile: profileConfig}
+	)
+	Init(nil, []Config{appConfig, urlConfig, profileConfig})
+	defer profileConfig.Set("")
+
+	if err := os.WriteFile(path, []byte(testProfiles), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	profileConfig.Set("contoso")
+	if err := LoadValues(nil, options); err != nil {
+		t.Fatal(err)
+	} else if got := appConfig.Value(); got != "contoso-app" {
+		t.Errorf("got %v; want the profile's value", got)
+	} else if got := urlConfig.Value(); got != "https://default" {
+		t.Errorf("got %v; want the value inherited from the default section", got)
+	}
+
+	profileConfig.Set("fabrikam")
+	if err := LoadValues(nil, options); err == nil {
+		t.Error("expected an error for a missing profile")
+	}
+}
+
+func TestSaveProfile(t *testing.T) {
+	var (
+		path      = filepath.Join(t.TempDir(), "config.json")
+		appConfig = Config{Name: "test-saved-app", Default: ""}
+	)
+	Init(nil, []Config{appConfig})
+
+	if err := os.WriteFile(path, []byte(testProfiles), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	appConfig.Set("fabrikam-app")
+	if err := SaveProfile(path, "fabrikam", []Config{appConfig}); err != nil {
+		t.Fatal(err)
+	} else if err := SaveProfile(path, "fabri.kam", []Config{appConfig}); err == nil {
+		t.Error("expected an error for a profile name containing '.'")
+	}
+
+	file := viper.New()
+	file.SetConfigFile(path)
+	if err := file.ReadInConfig(); err != nil {
+		t.Fatal(err)
+	} else if got := file.GetString("profiles.fabrikam.test-saved-app"); got != "fabrikam-app" {
+		t.Errorf("got %s; want the value saved to the new profile", got)
+	} else if got := file.GetString("profiles.contoso.test-app"); got != "contoso-app" {
+		t.Errorf("got %s; want the existing profile kept", got)
+	} else if got := file.GetString("test-url"); got != "https://default" {
+		t.Errorf("got %s; want the default section kept", got)
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package internal
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cast"
+	"github.com/spf13/viper"
+)
+
+// the section of the config file holding the settings specific to each tenant, keyed by tenant
+const tenantsSection = "tenants"
+
+// Overrides are config values that take precedence over the ones loaded by LoadValues, keyed by lowercased config name
+type Overrides map[string]interface{}
+
+// the key of a tenant's section naming the profile its settings start out from
+const tenantProfileKey = "profile"
+
+// TenantOverrides returns the settings given for tenant under the tenants section of the config file, e.g. its
+// own app registration and secret. A tenant's section may name a profile from the profiles section whose settings
+// apply unless the section sets them itself. Sensitive values among them are resolved and masked like any other.
+func TenantOverrides(tenant string) (Overrides, error) {
+	var (
+		overrides = Overrides{}
+		settings  = map[string]interface{}{}
+	)
+
+	// viper lowercases the keys of the config file
+	section, _ := viper.GetStringMap(tenantsSection)[strings.ToLower(tenant)].(map[string]interface{})
+	if name, ok := section[tenantProfileKey].(string); ok {
+		if profile, ok := viper.GetStringMap(profilesSection)[strings.ToLower(name)].(map[string]interface{}); !ok {
+			return nil, fmt.Errorf("profile %s of tenant %s not found in %s", name, tenant, viper.ConfigFileUsed())
+		} else {
+			for name, value := range profile {
+				settings[name] = value
+			}
+		}
+	}
+	for name, value := range section {
+		if name != tenantProfileKey {
+			settings[name] = value
+		}
+	}
+
+	for name, value := range settings {
+		if reference, ok := value.(string); ok && isSensitive(name) {
+			if credential, err := ResolveCredential(reference); err != nil {
+				return nil, fmt.Errorf("unable to resolve credential reference for %s of tenant %s: %w", name, tenant, err)
+			} else {
+				addSecret(credential)
+				value = credential
+			}
+		}
+		overrides[strings.ToLower(name)] = value
+	}
+	return overrides, nil
+}
+
+// ValueOf returns the value of the config from overrides if it is set there, and Value otherwise
+func (s Config) ValueOf(overrides Overrides) interface{} {
+	if value, ok := overrides[strings.ToLower(s.Name)]; !ok {
+		return s.Value()
+	} else {
+		switch s.Default.(type) {
+		case int:
+			return cast.ToInt(value)
+		case bool:
+			return cast.ToBool(value)
+		case []string:
+			return cast.ToStringSlice(value)
+		default:
+			return cast.ToString(value)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package internal
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+func TestTenantOverrides(t *testing.T) {
+	var (
+		appConfig    = Config{Name: "test-app", Default: ""}
+		secretConfig = Config{Name: "test-tenant-secret", Default: "", Sensitive: true}
+		subsConfig   = Config{Name: "test-subscriptionIds", Default: []string{}}
+		retryConfig  = Config{Name: "test-retries", Default: 3}
+	)
+	Init(nil, []Config{appConfig, secretConfig, subsConfig, retryConfig})
+	appConfig.Set("global-app")
+	t.Setenv("AZUREHOUND_TEST_TENANT_SECRET", "fabrikam-secret")
+
+	viper.Set(tenantsSection, map[string]interface{}{
+		"fabrikam.onmicrosoft.com": map[string]interface{}{
+			"test-tenant-secret": "env:AZUREHOUND_TEST_TENANT_SECRET",
+			// viper lowercases the keys read from a config file
+			"test-subscriptionids": []interface{}{"a", "b"},
+			"test-retries":         "5",
+		},
+	})
+	defer viper.Set(tenantsSection, nil)
+
+	if overrides, err := TenantOverrides("Fabrikam.onmicrosoft.com"); err != nil {
+		t.Fatal(err)
+	} else if got := appConfig.ValueOf(overrides); got != "global-app" {
+		t.Errorf("got %v; want the global value", got)
+	} else if got := secretConfig.ValueOf(overrides); got != "fabrikam-secret" {
+		t.Errorf("got %v; want the resolved secret", got)
+	} else if got := subsConfig.ValueOf(overrides); !reflect.DeepEqual(got, []string{"a", "b"}) {
+		t.Errorf("got %v; want [a b]", got)
+	} else if got := retryConfig.ValueOf(overrides); got != 5 {
+		t.Errorf("got %v; want 5", got)
+	} else if got := Redact("fabrikam-secret"); got != redacted {
+		t.Errorf("got %s; want the tenant's secret masked", got)
+	}
+
+	if overrides, err := TenantOverrides("contoso.onmicrosoft.com"); err != nil {
+		t.Fatal(err)
+	} else if len(overrides) != 0 {
+		t.Errorf("got %v; want no overrides for a tenant without a section", overrides)
+	}
+}
+
+func TestTenantProfile(t *testing.T) {
+	var (
+		appConfig    = Config{Name: "test-profile-app", Default: ""}
+		secretConfig = Config{Name: "test-profile-secret", Default: "", Sensitive: true}
+	)
+	Init(nil, []Config{appConfig, secretConfig})
+	t.Setenv("AZUREHOUND_TEST_PROFILE_SECRET", "contoso-secret")
+
+	viper.Set(profilesSection, map[string]interface{}{
+		"contoso": map[string]interface{}{
+			"test-profile-app":    "contoso-app",
+			"test-profile-secret": "env:AZUREHOUND_TEST_PROFILE_SECRET",
+		},
+	})
+	viper.Set(tenantsSection, map[string]interface{}{
+		"contoso.onmicrosoft.com":  map[string]interface{}{"profile": "Contoso"},
+		"fabrikam.onmicrosoft.com": map[string]interface{}{"profile": "contoso", "test-profile-app": "fabrikam-app"},
+		"tailspin.onmicrosoft.com": map[string]interface{}{"profile": "tailspin"},
+	})
+	defer viper.Set(profilesSection, nil)
+	defer viper.Set(tenantsSection, nil)
+
+	if overrides, err := TenantOverrides("contoso.onmicrosoft.com"); err != nil {
+		t.Fatal(err)
+	} else if got := appConfig.ValueOf(overrides); got != "contoso-app" {
+		t.Errorf("got %v; want the profile's value", got)
+	} else if got := secretConfig.ValueOf(overrides); got != "contoso-secret" {
+		t.Errorf("got %v; want the profile's resolved secret", got)
+	}
+
+	// the tenant's own settings take precedence over its profile's
+	if overrides, err := TenantOverrides("fabrikam.onmicrosoft.com"); err != nil {
+		t.Fatal(err)
+	} else if got := appConfig.ValueOf(overrides); got != "fabrikam-app" {
+		t.Errorf("got %v; want the tenant's value", got)
+	} else if got := secretConfig.ValueOf(overrides); got != "contoso-secret" {
+		t.Errorf("got %v; want the profile's resolved secret", got)
+	}
+
+	if _, err := TenantOverrides("tailspin.onmicrosoft.com"); err == nil {
+		t.Error("expected an error for a missing profile")
+	}
+}
@@ -28,25 +28,55 @@ import (
 
 var Init = config.Init
 var LoadValues = config.LoadValues
+var Secrets = config.Secrets
+var TenantOverrides = config.TenantOverrides
+var SaveProfile = config.SaveProfile
 
 func SetAzureDefaults() {
-	if AzAuthUrl.Value() == "" {
-		region := AzRegion.Value().(string)
-		url := client.AuthorityUrl(region, constants.AzureCloud().ActiveDirectoryAuthority)
-		AzAuthUrl.Set(url)
+	if env, ok := client.Environment(AzRegion.Value().(string)); ok {
+		SetCloud(env)
+	} else {
+		SetCloud(constants.AzureCloud())
 	}
+}
 
-	if AzGraphUrl.Value() == "" {
-		region := AzRegion.Value().(string)
-		url := client.GraphUrl(region, constants.AzureCloud().MicrosoftGraphUrl)
-		AzGraphUrl.Set(url)
+// cloudEndpoints are the settings that default to an endpoint of the cloud
+var cloudEndpoints = []struct {
+	config   Config
+	endpoint func(constants.Environment) string
+}{
+	{AzAuthUrl, func(env constants.Environment) string { return env.ActiveDirectoryAuthority }},
+	{AzGraphUrl, func(env constants.Environment) string { return env.MicrosoftGraphUrl }},
+	{AzMgmtUrl, func(env constants.Environment) string { return env.ResourceManagerUrl }},
+	{AzKeyVaultSuffix, func(env constants.Environment) string { return env.KeyVaultDNSSuffix }},
+	{AzStorageSuffix, func(env constants.Environment) string { return env.StorageEndpointSuffix }},
+}
+
+// SetCloud uses the endpoints of env for those that haven't been configured explicitly
+func SetCloud(env constants.Environment) {
+	for _, setting := range cloudEndpoints {
+		if setting.config.Value() == "" {
+			setting.config.Set(setting.endpoint(env))
+		}
 	}
+}
 
-	if AzMgmtUrl.Value() == "" {
-		region := AzRegion.Value().(string)
-		url := client.ResourceManagerUrl(region, constants.AzureCloud().ResourceManagerUrl)
-		AzMgmtUrl.Set(url)
+// EndpointOf returns the endpoint a tenant uses for one of the cloud endpoint settings. The global endpoints are those
+// of the global region, so a tenant given a region of its own uses the endpoints of that region instead unless it sets
+// the endpoint itself.
+func EndpointOf(config Config, overrides Overrides) string {
+	if _, ok := overrides[config.Name]; !ok {
+		if _, ok := overrides[AzRegion.Name]; ok {
+			if env, ok := client.Environment(AzRegion.ValueOf(overrides).(string)); ok {
+				for _, setting := range cloudEndpoints {
+					if setting.config.Name == config.Name {
+						return setting.endpoint(env)
+					}
+				}
+			}
+		}
 	}
+	return config.ValueOf(overrides).(string)
 }
 
 func ValidateURL(input string) error {
@@ -65,5 +95,6 @@ func Options() config.Options {
 		ConfigName:  "config",
 		ConfigPaths: SystemConfigDirs(),
 		EnvPrefix:   EnvPrefix,
+		Profile:     Profile,
 	}
 }
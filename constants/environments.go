@@ -19,9 +19,12 @@ package constants
 
 // Azure deployment regions
 const (
-	China   string = "china"
-	Cloud   string = "cloud"
+	China string = "china"
+	Cloud string = "cloud"
+
+	// Deprecated: Microsoft Cloud Deutschland was closed in October 2021
 	Germany string = "germany"
+
 	USGovL4 string = "usgovl4"
 	USGovL5 string = "usgovl5"
 )
@@ -30,6 +33,8 @@ type Environment struct {
 	ActiveDirectoryAuthority string
 	MicrosoftGraphUrl        string
 	ResourceManagerUrl       string
+	KeyVaultDNSSuffix        string
+	StorageEndpointSuffix    string
 }
 
 func AzureCloud() Environment {
@@ -37,6 +42,8 @@ func AzureCloud() Environment {
 		"https://login.microsoftonline.com",
 		"https://graph.microsoft.com",
 		"https://management.azure.com",
+		"vault.azure.net",
+		"core.windows.net",
 	}
 }
 
@@ -45,6 +52,8 @@ func AzureUSGovernment() Environment {
 		"https://login.microsoftonline.us",
 		"https://graph.microsoft.us",
 		"https://management.usgovcloudapi.net",
+		"vault.usgovcloudapi.net",
+		"core.usgovcloudapi.net",
 	}
 }
 
@@ -59,13 +68,18 @@ func AzureChina() Environment {
 		"https://login.chinacloudapi.cn",
 		"https://microsoftgraph.chinacloudapi.cn",
 		"https://management.chinacloudapi.cn",
+		"vault.azure.cn",
+		"core.chinacloudapi.cn",
 	}
 }
 
+// Deprecated: Microsoft Cloud Deutschland was closed in October 2021
 func AzureGermany() Environment {
 	return Environment{
 		"https://login.microsoftonline.de",
 		"https://graph.microsoft.de",
 		"https://management.microsoftazure.de",
+		"vault.microsoftazure.de",
+		"core.cloudapi.de",
 	}
 }
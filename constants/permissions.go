@@ -0,0 +1,66 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package constants
+
+// First-party resource applications
+// See https://learn.microsoft.com/en-us/troubleshoot/azure/active-directory/verify-first-party-apps-sign-in for more info.
+const (
+	// The resource application exposing the Microsoft Graph API.
+	MicrosoftGraphAppID string = "00000003-0000-0000-c000-000000000000"
+
+	// The resource application exposing the Exchange Online APIs.
+	ExchangeOnlineAppID string = "00000002-0000-0ff1-ce00-000000000000"
+
+	// The resource application exposing the Azure Active Directory Graph API.
+	AzureADGraphAppID string = "00000002-0000-0000-c000-000000000000"
+)
+
+// Tier zero permissions
+// Permissions that allow the holder to escalate to Global Administrator or an equivalent level of control over the
+// tenant, keyed by the app id of the resource application that exposes them.
+var tierZeroPermissions = map[string][]string{
+	MicrosoftGraphAppID: {
+		"AppRoleAssignment.ReadWrite.All",
+		"Application.ReadWrite.All",
+		"Directory.ReadWrite.All",
+		"Domain.ReadWrite.All",
+		"Policy.ReadWrite.PermissionGrant",
+		"PrivilegedAccess.ReadWrite.AzureADGroup",
+		"RoleAssignmentSchedule.ReadWrite.Directory",
+		"RoleManagement.ReadWrite.Directory",
+		"UserAuthenticationMethod.ReadWrite.All",
+	},
+	ExchangeOnlineAppID: {
+		"Exchange.ManageAsApp",
+		"full_access_as_app",
+	},
+	AzureADGraphAppID: {
+		"Directory.ReadWrite.All",
+	},
+}
+
+// IsTierZeroPermission reports whether the permission value exposed by the given resource application is a known tier
+// zero permission.
+func IsTierZeroPermission(resourceAppId string, value string) bool {
+	for _, permission := range tierZeroPermissions[resourceAppId] {
+		if permission == value {
+			return true
+		}
+	}
+	return false
+}
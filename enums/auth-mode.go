@@ -0,0 +1,44 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package enums
+
+// The flow used to acquire access tokens instead of the credentials provided via flags or the config file.
+type AuthMode = string
+
+const (
+	// Acquire tokens from the managed identity of the Azure VM, container or Function AzureHound runs on.
+	ManagedIdentity AuthMode = "managed-identity"
+
+	// Sign in interactively on another device, e.g. to satisfy MFA, and reuse the resulting refresh token.
+	DeviceCode AuthMode = "device-code"
+
+	// Exchange an OIDC token issued to a workload, e.g. by Kubernetes or GitHub Actions, via a federated credential.
+	WorkloadIdentity AuthMode = "workload-identity"
+
+	// Refresh tokens with the account an operator signed in to with `az login` or another MSAL based tool.
+	AzureCLI AuthMode = "azure-cli"
+)
+
+func AuthModes() []AuthMode {
+	return []AuthMode{
+		ManagedIdentity,
+		DeviceCode,
+		WorkloadIdentity,
+		AzureCLI,
+	}
+}
@@ -72,4 +72,6 @@ const (
 	KindAZWorkflowRoleAssignment          Kind = "AZWorkflowRoleAssignment"
 	KindAZFunctionApp                     Kind = "AZFunctionApp"
 	KindAZFunctionAppRoleAssignment       Kind = "AZFunctionAppRoleAssignment"
+	KindAZResource                        Kind = "AZResource"
+	KindAZResourceRoleAssignment          Kind = "AZResourceRoleAssignment"
 )
@@ -0,0 +1,153 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package fakeazure
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/bloodhoundad/azurehound/models/azure"
+)
+
+const roleAssignmentsPath = "/providers/microsoft.authorization/roleassignments"
+
+var assignedToFilter = regexp.MustCompile(`^assignedTo\('([^']*)'\)$`)
+
+// resourceManager answers Azure Resource Manager requests. Paths are case insensitive.
+func (s *Server) resourceManager(w http.ResponseWriter, r *http.Request) {
+	var (
+		tenant   = s.tenant
+		path     = strings.ToLower(strings.TrimSuffix(r.URL.Path, "/"))
+		segments = strings.Split(strings.TrimPrefix(path, "/"), "/")
+	)
+
+	if strings.HasSuffix(path, roleAssignmentsPath) {
+		s.roleAssignments(w, r, r.URL.Path[:len(path)-len(roleAssignmentsPath)])
+	} else if path == "/tenants" {
+		s.page(w, r, items([]azure.Tenant{{
+			Id:             "/tenants/" + tenant.Id,
+			TenantId:       tenant.Id,
+			DisplayName:    tenant.DisplayName,
+			DefaultDomain:  tenant.Domain,
+			Domains:        []string{tenant.Domain},
+			TenantCategory: "Home",
+		}}), false)
+	} else if path == "/subscriptions" {
+		s.page(w, r, items(tenant.Subscriptions), false)
+	} else if segments[0] == "subscriptions" && len(segments) >= 2 {
+		subscriptionId := "/subscriptions/" + segments[1]
+		if !s.subscriptionExists(subscriptionId) {
+			writeError(w, http.StatusNotFound, "SubscriptionNotFound", fmt.Sprintf("The subscription '%s' could not be found.", segments[1]))
+		} else if len(segments) == 3 && segments[2] == "resourcegroups" {
+			s.page(w, r, items(within(subscriptionId, tenant.ResourceGroups, func(group azure.ResourceGroup) string { return group.Id })), false)
+		} else if len(segments) == 3 && segments[2] == "resources" {
+			s.page(w, r, s.resources(subscriptionId, ""), false)
+		} else if len(segments) == 5 && segments[2] == "providers" {
+			s.page(w, r, s.resources(subscriptionId, segments[3]+"/"+segments[4]), false)
+		} else {
+			s.unhandled(w, r)
+		}
+	} else if path == "/providers/microsoft.management/managementgroups" {
+		s.page(w, r, items(tenant.ManagementGroups), false)
+	} else if len(segments) == 5 && strings.HasPrefix(path, "/providers/microsoft.management/managementgroups/") && segments[4] == "descendants" {
+		if name := strings.Split(strings.Trim(r.URL.Path, "/"), "/")[3]; !s.managementGroupExists(name) {
+			writeError(w, http.StatusNotFound, "NotFound", fmt.Sprintf("The management group '%s' could not be found.", name))
+		} else {
+			s.page(w, r, items(tenant.Descendants(name)), false)
+		}
+	} else {
+		s.unhandled(w, r)
+	}
+}
+
+// resources are the resources of a type in a subscription, or all of them if resourceType is empty. Types other than
+// key vaults and virtual machines have no resources.
+func (s *Server) resources(subscriptionId, resourceType string) []interface{} {
+	var resources []interface{}
+	if resourceType == "" || strings.EqualFold(resourceType, typeKeyVault) {
+		resources = append(resources, items(within(subscriptionId, s.tenant.KeyVaults, func(vault azure.KeyVault) string { return vault.Id }))...)
+	}
+	if resourceType == "" || strings.EqualFold(resourceType, typeVirtualMachine) {
+		resources = append(resources, items(within(subscriptionId, s.tenant.VirtualMachines, func(vm azure.VirtualMachine) string { return vm.Id }))...)
+	}
+	return resources
+}
+
+// roleAssignments pages through the role assignments that apply to scope: without a filter those at, above or below
+// it, with atScope() those at or above it and with assignedTo('{id}') those of a principal at, above or below it
+func (s *Server) roleAssignments(w http.ResponseWriter, r *http.Request, scope string) {
+	var (
+		filter   = r.URL.Query().Get("$filter")
+		matches  func(azure.RoleAssignment) bool
+		matching []azure.RoleAssignment
+		related  = func(assignment azure.RoleAssignment) bool {
+			return s.tenant.Contains(assignment.Properties.Scope, scope) || s.tenant.Contains(scope, assignment.Properties.Scope)
+		}
+	)
+	if filter == "" {
+		matches = related
+	} else if filter == "atScope()" {
+		matches = func(assignment azure.RoleAssignment) bool {
+			return s.tenant.Contains(assignment.Properties.Scope, scope)
+		}
+	} else if match := assignedToFilter.FindStringSubmatch(filter); match != nil {
+		matches = func(assignment azure.RoleAssignment) bool {
+			return assignment.Properties.PrincipalId == match[1] && related(assignment)
+		}
+	} else {
+		writeError(w, http.StatusBadRequest, "InvalidFilterInQueryString", fmt.Sprintf("The filter '%s' is not supported.", filter))
+		return
+	}
+
+	for _, assignment := range s.tenant.AzureRoleAssignments {
+		if matches(assignment) {
+			matching = append(matching, assignment)
+		}
+	}
+	s.page(w, r, items(matching), false)
+}
+
+func (s *Server) subscriptionExists(id string) bool {
+	for _, subscription := range s.tenant.Subscriptions {
+		if sameId(subscription.Id, id) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Server) managementGroupExists(name string) bool {
+	for _, group := range s.tenant.ManagementGroups {
+		if strings.EqualFold(group.Name, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func within[T any](scope string, values []T, id func(T) string) []T {
+	var result []T
+	for _, value := range values {
+		if strings.HasPrefix(strings.ToLower(id(value)), strings.ToLower(scope)+"/") {
+			result = append(result, value)
+		}
+	}
+	return result
+}
@@ -0,0 +1,127 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package fakeazure
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/bloodhoundad/azurehound/models/azure"
+)
+
+// Graph supports filtering collections on a single property compared with eq
+var graphFilter = regexp.MustCompile(`^(\w+) eq '?([^']*)'?$`)
+
+// graph answers Microsoft Graph requests; path is the request path without the API version
+func (s *Server) graph(w http.ResponseWriter, r *http.Request, path []string) {
+	var (
+		tenant     = s.tenant
+		collection = path[0]
+		id         string
+		relation   string
+	)
+	if len(path) > 1 {
+		id = path[1]
+	}
+	if len(path) > 2 {
+		relation = path[2]
+	}
+
+	switch {
+	case len(path) == 1 && collection == "organization":
+		s.page(w, r, items([]azure.Organization{tenant.Organization()}), true)
+	case len(path) == 1 && collection == "users":
+		s.page(w, r, items(tenant.Users), true)
+	case len(path) == 1 && collection == "groups":
+		s.filtered(w, r, items(tenant.Groups), map[string]func(interface{}) string{
+			"securityEnabled": func(group interface{}) string { return fmt.Sprint(group.(azure.Group).SecurityEnabled) },
+		})
+	case len(path) == 1 && collection == "applications":
+		s.page(w, r, items(tenant.Apps), true)
+	case len(path) == 1 && collection == "servicePrincipals":
+		s.page(w, r, items(tenant.ServicePrincipals), true)
+	case len(path) == 1 && collection == "devices":
+		s.page(w, r, items(tenant.Devices), true)
+	case len(path) == 3 && relation == "owners" && (collection == "groups" || collection == "applications" || collection == "servicePrincipals"):
+		s.directoryObjects(w, r, id, tenant.Owners)
+	case len(path) == 3 && relation == "registeredOwners" && collection == "devices":
+		s.directoryObjects(w, r, id, tenant.Owners)
+	case len(path) == 3 && relation == "members" && collection == "groups":
+		s.directoryObjects(w, r, id, tenant.Members)
+	case len(path) == 3 && relation == "appRoleAssignedTo" && collection == "servicePrincipals":
+		if assignments, ok := tenant.AppRoleAssignments[id]; ok || s.exists(id) {
+			s.page(w, r, items(assignments), true)
+		} else {
+			writeError(w, http.StatusNotFound, "Request_ResourceNotFound", fmt.Sprintf("Resource '%s' does not exist.", id))
+		}
+	case len(path) == 3 && collection == "directory" && id == "deletedItems":
+		s.page(w, r, nil, true)
+	case len(path) == 3 && collection == "roleManagement" && id == "directory" && relation == "roleDefinitions":
+		s.page(w, r, items(tenant.Roles), true)
+	case len(path) == 3 && collection == "roleManagement" && id == "directory" && relation == "roleAssignments":
+		s.filtered(w, r, items(tenant.RoleAssignments), map[string]func(interface{}) string{
+			"roleDefinitionId": func(assignment interface{}) string { return assignment.(azure.UnifiedRoleAssignment).RoleDefinitionId },
+			"principalId":      func(assignment interface{}) string { return assignment.(azure.UnifiedRoleAssignment).PrincipalId },
+		})
+	default:
+		s.unhandled(w, r)
+	}
+}
+
+// filtered pages through the items matching the request's $filter on one of the properties
+func (s *Server) filtered(w http.ResponseWriter, r *http.Request, values []interface{}, properties map[string]func(interface{}) string) {
+	if filter := r.URL.Query().Get("$filter"); filter == "" {
+		s.page(w, r, values, true)
+	} else if match := graphFilter.FindStringSubmatch(filter); match == nil || properties[match[1]] == nil {
+		writeError(w, http.StatusBadRequest, "Request_UnsupportedQuery", fmt.Sprintf("Unsupported query: %s", filter))
+	} else {
+		var (
+			property = properties[match[1]]
+			matching []interface{}
+		)
+		for _, value := range values {
+			if strings.EqualFold(property(value), match[2]) {
+				matching = append(matching, value)
+			}
+		}
+		s.page(w, r, matching, true)
+	}
+}
+
+// directoryObjects pages through the owners or members of an object
+func (s *Server) directoryObjects(w http.ResponseWriter, r *http.Request, id string, relations map[string][]string) {
+	if !s.exists(id) {
+		writeError(w, http.StatusNotFound, "Request_ResourceNotFound", fmt.Sprintf("Resource '%s' does not exist.", id))
+	} else {
+		var objects []json.RawMessage
+		for _, related := range relations[id] {
+			if object, ok := s.tenant.directoryObject(related); ok {
+				objects = append(objects, object)
+			}
+		}
+		s.page(w, r, items(objects), true)
+	}
+}
+
+func (s *Server) exists(id string) bool {
+	_, ok := s.tenant.objects[id]
+	return ok
+}
@@ -0,0 +1,401 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package fakeazure
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultPageSize      = 100
+	defaultTokenLifetime = time.Hour
+	defaultRetryAfter    = 10 * time.Millisecond
+)
+
+// The application permissions granted to every token, which are all the permissions AzureHound asks for
+var tokenRoles = []string{
+	"Application.Read.All",
+	"Device.Read.All",
+	"Directory.Read.All",
+	"Group.Read.All",
+	"GroupMember.Read.All",
+	"RoleManagement.Read.Directory",
+	"User.Read.All",
+}
+
+// Faults makes a Server misbehave the way the Azure APIs do under load. Each fault hits every nth distinct request,
+// counted over all requests, but never a request that was sent before so that a client retrying it succeeds. A
+// request may only suffer one fault; zero disables a fault.
+type Faults struct {
+	ThrottleEvery    int           // 429 Too Many Requests
+	ServerErrorEvery int           // 503 Service Unavailable
+	MalformedEvery   int           // a response body cut off halfway
+	SlowEvery        int           // a response delayed by Latency
+	Latency          time.Duration // how long slow responses take
+	RetryAfter       time.Duration // the delay asked for by throttled responses, 10ms if zero
+}
+
+type Config struct {
+	PageSize      int           // the most items in a page, 100 if zero
+	TokenLifetime time.Duration // how long access tokens are valid, an hour if zero
+	Faults        Faults
+}
+
+// Stats counts what a Server has served. Unhandled lists the requests for endpoints the server doesn't implement.
+type Stats struct {
+	Requests     int
+	Tokens       int
+	Throttled    int
+	ServerErrors int
+	Malformed    int
+	Slow         int
+	Unhandled    []string
+}
+
+// Server serves a Tenant over the Graph, Resource Manager and token endpoints, which all share its URL. Requests
+// other than token requests need a bearer token issued by the server.
+type Server struct {
+	*httptest.Server
+
+	tenant *Tenant
+	config Config
+
+	mutex    sync.Mutex
+	tokens   map[string]time.Time
+	seen     map[string]bool
+	distinct int
+	stats    Stats
+}
+
+type fault int
+
+const (
+	noFault fault = iota
+	throttle
+	serverError
+	malformed
+	slow
+)
+
+// NewServer starts a Server; Close stops it
+func NewServer(tenant *Tenant, config Config) *Server {
+	if config.PageSize <= 0 {
+		config.PageSize = defaultPageSize
+	}
+	if config.TokenLifetime <= 0 {
+		config.TokenLifetime = defaultTokenLifetime
+	}
+	if config.Faults.RetryAfter <= 0 {
+		config.Faults.RetryAfter = defaultRetryAfter
+	}
+
+	server := &Server{
+		tenant: tenant,
+		config: config,
+		tokens: map[string]time.Time{},
+		seen:   map[string]bool{},
+	}
+	server.Server = httptest.NewServer(server)
+	return server
+}
+
+func (s *Server) Stats() Stats {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	stats := s.stats
+	stats.Unhandled = append([]string(nil), s.stats.Unhandled...)
+	return stats
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	s.stats.Requests++
+	s.mutex.Unlock()
+
+	if strings.HasSuffix(r.URL.Path, "/oauth2/v2.0/token") {
+		s.token(w, r)
+	} else if !s.authorized(r) {
+		writeError(w, http.StatusUnauthorized, "InvalidAuthenticationToken", "Access token is missing, invalid or has expired.")
+	} else {
+		s.serve(w, r, false)
+	}
+}
+
+// serve answers a Graph or Resource Manager request, injecting faults. Requests in a batch can't be malformed or slow
+// on their own.
+func (s *Server) serve(w http.ResponseWriter, r *http.Request, batched bool) {
+	switch s.fault(r, batched) {
+	case throttle:
+		retryAfter := s.config.Faults.RetryAfter
+		w.Header().Set("Retry-After", strconv.Itoa(int(retryAfter.Round(time.Second)/time.Second)))
+		w.Header().Set("x-ms-retry-after-ms", strconv.FormatInt(retryAfter.Milliseconds(), 10))
+		writeError(w, http.StatusTooManyRequests, "TooManyRequests", "Too many requests.")
+	case serverError:
+		writeError(w, http.StatusServiceUnavailable, "ServiceUnavailable", "The service is temporarily unavailable.")
+	case malformed:
+		recorder := httptest.NewRecorder()
+		s.route(recorder, r)
+		for key, values := range recorder.Header() {
+			w.Header()[key] = values
+		}
+		w.WriteHeader(recorder.Code)
+		body := recorder.Body.Bytes()
+		w.Write(body[:len(body)/2])
+	case slow:
+		select {
+		case <-r.Context().Done():
+			return
+		case <-time.After(s.config.Faults.Latency):
+			s.route(w, r)
+		}
+	default:
+		s.route(w, r)
+	}
+}
+
+func (s *Server) fault(r *http.Request, batched bool) fault {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	key := r.Method + " " + r.URL.RequestURI()
+	if s.seen[key] {
+		return noFault
+	}
+	s.seen[key] = true
+	s.distinct++
+
+	faults := s.config.Faults
+	if hits(s.distinct, faults.ThrottleEvery) {
+		s.stats.Throttled++
+		return throttle
+	} else if hits(s.distinct, faults.ServerErrorEvery) {
+		s.stats.ServerErrors++
+		return serverError
+	} else if !batched && hits(s.distinct, faults.MalformedEvery) {
+		s.stats.Malformed++
+		return malformed
+	} else if !batched && hits(s.distinct, faults.SlowEvery) {
+		s.stats.Slow++
+		return slow
+	} else {
+		return noFault
+	}
+}
+
+func hits(n, every int) bool {
+	return every > 0 && n%every == 0
+}
+
+func (s *Server) route(w http.ResponseWriter, r *http.Request) {
+	segments := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(segments) > 1 && segments[1] == "$batch" && r.Method == http.MethodPost {
+		s.batch(w, r, segments[0])
+	} else if r.Method != http.MethodGet {
+		s.unhandled(w, r)
+	} else if segments[0] == "v1.0" || segments[0] == "beta" {
+		s.graph(w, r, segments[1:])
+	} else {
+		s.resourceManager(w, r)
+	}
+}
+
+func (s *Server) unhandled(w http.ResponseWriter, r *http.Request) {
+	s.mutex.Lock()
+	s.stats.Unhandled = append(s.stats.Unhandled, r.Method+" "+r.URL.Path)
+	s.mutex.Unlock()
+
+	writeError(w, http.StatusNotFound, "NotFound", fmt.Sprintf("%s %s is not implemented", r.Method, r.URL.Path))
+}
+
+// token issues an unsigned access token for the client credentials grant of the tenant. The credentials themselves
+// aren't checked.
+func (s *Server) token(w http.ResponseWriter, r *http.Request) {
+	tenant := strings.Split(strings.Trim(r.URL.Path, "/"), "/")[0]
+	if err := r.ParseForm(); err != nil {
+		writeOAuthError(w, "invalid_request", err.Error())
+	} else if tenant != s.tenant.Id && !strings.EqualFold(tenant, s.tenant.Domain) {
+		writeOAuthError(w, "invalid_tenant", fmt.Sprintf("Tenant '%s' not found.", tenant))
+	} else if grantType := r.PostForm.Get("grant_type"); grantType != "client_credentials" {
+		writeOAuthError(w, "unsupported_grant_type", fmt.Sprintf("The grant type %s is not supported.", grantType))
+	} else if clientId := r.PostForm.Get("client_id"); clientId == "" {
+		writeOAuthError(w, "invalid_request", "The request body must contain the client_id parameter.")
+	} else if scope := strings.Fields(r.PostForm.Get("scope")); len(scope) == 0 {
+		writeOAuthError(w, "invalid_request", "The request body must contain the scope parameter.")
+	} else {
+		s.mutex.Lock()
+		s.stats.Tokens++
+		var (
+			expires = time.Now().Add(s.config.TokenLifetime)
+			claims  = map[string]interface{}{
+				"aud":   strings.TrimSuffix(scope[0], "/.default"),
+				"appid": clientId,
+				"exp":   expires.Unix(),
+				"iat":   time.Now().Unix(),
+				"jti":   strconv.Itoa(s.stats.Tokens),
+				"oid":   clientId,
+				"roles": tokenRoles,
+				"tid":   s.tenant.Id,
+			}
+			header, _  = json.Marshal(map[string]string{"alg": "none", "typ": "JWT"})
+			payload, _ = json.Marshal(claims)
+			token      = base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload) + "."
+		)
+		s.tokens[token] = expires
+		s.mutex.Unlock()
+
+		writeJson(w, http.StatusOK, map[string]interface{}{
+			"token_type":     "Bearer",
+			"access_token":   token,
+			"expires_in":     int(s.config.TokenLifetime.Seconds()),
+			"ext_expires_in": int(s.config.TokenLifetime.Seconds()),
+		})
+	}
+}
+
+func (s *Server) authorized(r *http.Request) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	expires, ok := s.tokens[token]
+	return ok && time.Now().Before(expires)
+}
+
+type batchRequest struct {
+	Requests []struct {
+		Id      string            `json:"id"`
+		Method  string            `json:"method"`
+		Url     string            `json:"url"`
+		Headers map[string]string `json:"headers"`
+	} `json:"requests"`
+}
+
+type batchResponse struct {
+	Id      string            `json:"id"`
+	Status  int               `json:"status"`
+	Headers map[string]string `json:"headers"`
+	Body    json.RawMessage   `json:"body,omitempty"`
+}
+
+// batch answers a Graph JSON batch, treating every request in it as if it was sent on its own
+func (s *Server) batch(w http.ResponseWriter, r *http.Request, version string) {
+	var body batchRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "BadRequest", err.Error())
+	} else if len(body.Requests) > 20 {
+		writeError(w, http.StatusBadRequest, "BadRequest", "A batch may contain at most 20 requests.")
+	} else {
+		responses := make([]batchResponse, len(body.Requests))
+		for i, item := range body.Requests {
+			var (
+				recorder = httptest.NewRecorder()
+				req      = httptest.NewRequest(item.Method, "/"+version+item.Url, nil).WithContext(r.Context())
+			)
+			req.Host = r.Host
+			for key, value := range item.Headers {
+				req.Header.Set(key, value)
+			}
+			s.serve(recorder, req, true)
+
+			headers := map[string]string{}
+			for key := range recorder.Header() {
+				headers[key] = recorder.Header().Get(key)
+			}
+			responses[i] = batchResponse{
+				Id:      item.Id,
+				Status:  recorder.Code,
+				Headers: headers,
+				Body:    bytes.TrimSpace(recorder.Body.Bytes()),
+			}
+		}
+		writeJson(w, http.StatusOK, map[string]interface{}{"responses": responses})
+	}
+}
+
+// page writes the page of items the request asks for along with the link to the next page, if there is one. Graph
+// and Resource Manager name the link differently.
+func (s *Server) page(w http.ResponseWriter, r *http.Request, items []interface{}, graph bool) {
+	var (
+		query    = r.URL.Query()
+		pageSize = s.config.PageSize
+		offset   = 0
+		response = map[string]interface{}{}
+	)
+	if top, err := strconv.Atoi(query.Get("$top")); err == nil && top > 0 && top < pageSize {
+		pageSize = top
+	}
+	if skipToken := query.Get("$skiptoken"); skipToken != "" {
+		if skip, err := strconv.Atoi(skipToken); err != nil || skip < 0 || skip > len(items) {
+			writeError(w, http.StatusBadRequest, "BadRequest", fmt.Sprintf("Invalid skip token %s.", skipToken))
+			return
+		} else {
+			offset = skip
+		}
+	}
+
+	end := offset + pageSize
+	if end >= len(items) {
+		end = len(items)
+	} else {
+		query.Set("$skiptoken", strconv.Itoa(end))
+		next := url.URL{Scheme: "http", Host: r.Host, Path: r.URL.Path, RawQuery: query.Encode()}
+		if graph {
+			response["@odata.nextLink"] = next.String()
+		} else {
+			response["nextLink"] = next.String()
+		}
+	}
+	response["value"] = items[offset:end]
+	writeJson(w, http.StatusOK, response)
+}
+
+func writeJson(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
+
+// writeError writes an error in the shape shared by Graph and Resource Manager
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	writeJson(w, status, map[string]interface{}{
+		"error": map[string]string{"code": code, "message": message},
+	})
+}
+
+func writeOAuthError(w http.ResponseWriter, code, description string) {
+	writeJson(w, http.StatusBadRequest, map[string]string{"error": code, "error_description": description})
+}
+
+func items[T any](values []T) []interface{} {
+	result := make([]interface{}, len(values))
+	for i, value := range values {
+		result[i] = value
+	}
+	return result
+}
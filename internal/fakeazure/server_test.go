@@ -0,0 +1,121 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package fakeazure
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+)
+
+func newTestServer(t *testing.T, config Config) (*Server, string) {
+	server := NewServer(NewTenant(TenantOptions{Seed: 1, Users: 5}), config)
+	t.Cleanup(server.Close)
+
+	res, err := http.PostForm(server.URL+"/"+server.tenant.Domain+"/oauth2/v2.0/token", url.Values{
+		"grant_type": {"client_credentials"},
+		"client_id":  {"app"},
+		"scope":      {"https://graph.microsoft.com/.default"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	var body struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	} else if body.AccessToken == "" {
+		t.Fatalf("got status %d and no access token", res.StatusCode)
+	}
+	return server, body.AccessToken
+}
+
+func get(t *testing.T, target, token string) (*http.Response, map[string]interface{}) {
+	req, err := http.NewRequest(http.MethodGet, target, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	var body map[string]interface{}
+	json.NewDecoder(res.Body).Decode(&body)
+	return res, body
+}
+
+func TestServerAuthorization(t *testing.T) {
+	server, token := newTestServer(t, Config{})
+
+	if res, _ := get(t, server.URL+"/v1.0/users", ""); res.StatusCode != http.StatusUnauthorized {
+		t.Errorf("got %d without a token; want %d", res.StatusCode, http.StatusUnauthorized)
+	}
+	if res, _ := get(t, server.URL+"/v1.0/users", token); res.StatusCode != http.StatusOK {
+		t.Errorf("got %d with a token; want %d", res.StatusCode, http.StatusOK)
+	}
+}
+
+func TestServerPaging(t *testing.T) {
+	server, token := newTestServer(t, Config{PageSize: 2})
+
+	var (
+		next  = server.URL + "/v1.0/users"
+		users = 0
+		pages = 0
+	)
+	for next != "" {
+		res, body := get(t, next, token)
+		if res.StatusCode != http.StatusOK {
+			t.Fatalf("got %d for %s", res.StatusCode, next)
+		}
+		users += len(body["value"].([]interface{}))
+		pages++
+		next, _ = body["@odata.nextLink"].(string)
+	}
+
+	if users != 5 || pages != 3 {
+		t.Errorf("got %d users in %d pages; want 5 users in 3 pages", users, pages)
+	}
+}
+
+func TestServerFaults(t *testing.T) {
+	server, token := newTestServer(t, Config{Faults: Faults{ThrottleEvery: 1}})
+	target := server.URL + "/v1.0/users"
+
+	if res, _ := get(t, target, token); res.StatusCode != http.StatusTooManyRequests {
+		t.Errorf("got %d; want the first request throttled", res.StatusCode)
+	} else if res.Header.Get("x-ms-retry-after-ms") == "" {
+		t.Error("got no retry delay for a throttled request")
+	}
+	if res, _ := get(t, target, token); res.StatusCode != http.StatusOK {
+		t.Errorf("got %d; want the retried request to succeed", res.StatusCode)
+	}
+	if stats := server.Stats(); stats.Throttled != 1 {
+		t.Errorf("got %d throttled requests; want 1", stats.Throttled)
+	}
+}
@@ -0,0 +1,474 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+// Package fakeazure serves a synthetic tenant over the Microsoft Graph, Azure Resource Manager and identity platform
+// endpoints AzureHound collects from so that collections can be tested end to end without an Azure subscription.
+package fakeazure
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"strings"
+
+	"github.com/bloodhoundad/azurehound/constants"
+	"github.com/bloodhoundad/azurehound/models/azure"
+	"github.com/gofrs/uuid"
+)
+
+const (
+	odataUser             = "#microsoft.graph.user"
+	odataGroup            = "#microsoft.graph.group"
+	odataServicePrincipal = "#microsoft.graph.servicePrincipal"
+	odataApplication      = "#microsoft.graph.application"
+	odataDevice           = "#microsoft.graph.device"
+
+	typeManagementGroup = "Microsoft.Management/managementGroups"
+	typeSubscription    = "Microsoft.Management/managementGroups/subscriptions"
+	typeKeyVault        = "Microsoft.KeyVault/vaults"
+	typeVirtualMachine  = "Microsoft.Compute/virtualMachines"
+)
+
+// TenantOptions sizes a synthetic tenant. Counts of resource groups are per subscription and counts of key vaults and
+// virtual machines are per resource group; management groups are created beneath the tenant root group.
+type TenantOptions struct {
+	Seed             int64
+	Users            int
+	Groups           int
+	Apps             int
+	Devices          int
+	Roles            int
+	ManagementGroups int
+	Subscriptions    int
+	ResourceGroups   int
+	KeyVaults        int
+	VirtualMachines  int
+}
+
+// Tenant is the directory and resource hierarchy served by a Server. Relationships are kept by object id.
+type Tenant struct {
+	Id          string
+	DisplayName string
+	Domain      string
+
+	Users              []azure.User
+	Groups             []azure.Group
+	Apps               []azure.Application
+	ServicePrincipals  []azure.ServicePrincipal
+	Devices            []azure.Device
+	Roles              []azure.Role
+	RoleAssignments    []azure.UnifiedRoleAssignment
+	AppRoleAssignments map[string][]azure.AppRoleAssignment // by service principal id
+	Owners             map[string][]string                  // by group, app, service principal or device id
+	Members            map[string][]string                  // by group id
+
+	ManagementGroups     []azure.ManagementGroup // the tenant root group first
+	Subscriptions        []azure.Subscription
+	ResourceGroups       []azure.ResourceGroup
+	KeyVaults            []azure.KeyVault
+	VirtualMachines      []azure.VirtualMachine
+	AzureRoleAssignments []azure.RoleAssignment
+
+	parents map[string]string      // management group or subscription id to the id of its management group
+	objects map[string]interface{} // every directory object by id
+	rng     *rand.Rand
+}
+
+// NewTenant generates a tenant. The same options always generate the same tenant.
+func NewTenant(options TenantOptions) *Tenant {
+	tenant := &Tenant{
+		AppRoleAssignments: map[string][]azure.AppRoleAssignment{},
+		Owners:             map[string][]string{},
+		Members:            map[string][]string{},
+		parents:            map[string]string{},
+		objects:            map[string]interface{}{},
+		rng:                rand.New(rand.NewSource(options.Seed)),
+	}
+	tenant.Id = tenant.newId()
+	tenant.DisplayName = fmt.Sprintf("Fake Tenant %d", options.Seed)
+	tenant.Domain = fmt.Sprintf("fake%d.onmicrosoft.com", options.Seed)
+
+	tenant.generateDirectory(options)
+	tenant.generateResources(options)
+	return tenant
+}
+
+// Organization is the tenant as described by Microsoft Graph
+func (s *Tenant) Organization() azure.Organization {
+	return azure.Organization{
+		DirectoryObject: azure.DirectoryObject{Id: s.Id},
+		DisplayName:     s.DisplayName,
+		VerifiedDomains: []azure.VerifiedDomain{{Name: s.Domain, IsDefault: true}},
+	}
+}
+
+// Descendants are the management groups and subscriptions beneath a management group, at any depth
+func (s *Tenant) Descendants(managementGroupName string) []azure.DescendantInfo {
+	var (
+		descendants []azure.DescendantInfo
+		parentId    = managementGroupId(managementGroupName)
+	)
+	for _, group := range s.ManagementGroups {
+		if group.Id != parentId && s.isDescendant(group.Id, parentId) {
+			descendants = append(descendants, azure.DescendantInfo{
+				Id:         group.Id,
+				Name:       group.Name,
+				Type:       typeManagementGroup,
+				Properties: azure.DescendantInfoProperties{DisplayName: group.Properties.DisplayName, Parent: azure.DescendantParentGroupInfo{Id: s.parents[group.Id]}},
+			})
+		}
+	}
+	for _, subscription := range s.Subscriptions {
+		if s.isDescendant(subscription.Id, parentId) {
+			descendants = append(descendants, azure.DescendantInfo{
+				Id:         fmt.Sprintf("%s/subscriptions/%s", parentId, subscription.SubscriptionId),
+				Name:       subscription.SubscriptionId,
+				Type:       typeSubscription,
+				Properties: azure.DescendantInfoProperties{DisplayName: subscription.DisplayName, Parent: azure.DescendantParentGroupInfo{Id: s.parents[subscription.Id]}},
+			})
+		}
+	}
+	return descendants
+}
+
+// Contains reports whether scope is at or beneath ancestor. Beneath a subscription means a path beneath its id while
+// beneath a management group means beneath one of its descendants.
+func (s *Tenant) Contains(ancestor, scope string) bool {
+	if sameId(ancestor, scope) {
+		return true
+	}
+
+	parts := strings.Split(strings.Trim(scope, "/"), "/")
+	if len(parts) >= 2 && strings.EqualFold(parts[0], "subscriptions") {
+		for i := len(parts) - 1; i >= 2; i-- {
+			if sameId(ancestor, "/"+strings.Join(parts[:i], "/")) {
+				return true
+			}
+		}
+		scope = "/subscriptions/" + strings.ToLower(parts[1])
+	}
+	for parent, ok := s.parents[scope]; ok; parent, ok = s.parents[parent] {
+		if sameId(ancestor, parent) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Tenant) isDescendant(id, ancestorId string) bool {
+	for parent, ok := s.parents[id]; ok; parent, ok = s.parents[parent] {
+		if parent == ancestorId {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Tenant) generateDirectory(options TenantOptions) {
+	for i := 0; i < options.Users; i++ {
+		user := azure.User{
+			DirectoryObject:   azure.DirectoryObject{Id: s.newId(), Type: odataUser},
+			DisplayName:       fmt.Sprintf("User %d", i),
+			UserPrincipalName: fmt.Sprintf("user%d@%s", i, s.Domain),
+			AccountEnabled:    true,
+		}
+		s.Users = append(s.Users, user)
+		s.objects[user.Id] = user
+	}
+
+	for i := 0; i < options.Groups; i++ {
+		// every third group is a Microsoft 365 group which isn't collected
+		group := azure.Group{
+			DirectoryObject: azure.DirectoryObject{Id: s.newId(), Type: odataGroup},
+			DisplayName:     fmt.Sprintf("Group %d", i),
+			SecurityEnabled: i%3 != 2,
+		}
+		s.Groups = append(s.Groups, group)
+		s.objects[group.Id] = group
+		s.Owners[group.Id] = s.pickUsers(2)
+		s.Members[group.Id] = s.pickUsers(5)
+		if i > 0 && s.rng.Intn(2) == 0 {
+			s.Members[group.Id] = append(s.Members[group.Id], s.Groups[s.rng.Intn(i)].Id)
+		}
+	}
+
+	for i := 0; i < options.Apps; i++ {
+		var (
+			appId = s.newId()
+			app   = azure.Application{
+				DirectoryObject: azure.DirectoryObject{Id: s.newId(), Type: odataApplication},
+				AppId:           appId,
+				DisplayName:     fmt.Sprintf("App %d", i),
+			}
+			servicePrincipal = azure.ServicePrincipal{
+				DirectoryObject:      azure.DirectoryObject{Id: s.newId(), Type: odataServicePrincipal},
+				AppId:                appId,
+				AppDisplayName:       app.DisplayName,
+				DisplayName:          app.DisplayName,
+				AccountEnabled:       true,
+				ServicePrincipalType: "Application",
+			}
+		)
+		// every other application exposes app roles that are assigned to users
+		if i%2 == 0 {
+			servicePrincipal.AppRoles = []azure.AppRole{{
+				AllowedMemberTypes: []string{"User"},
+				DisplayName:        "Reader",
+				Id:                 uuid.FromStringOrNil(s.newId()),
+				IsEnabled:          true,
+				Value:              "Reader",
+			}}
+		}
+		s.Apps = append(s.Apps, app)
+		s.ServicePrincipals = append(s.ServicePrincipals, servicePrincipal)
+		s.objects[app.Id] = app
+		s.objects[servicePrincipal.Id] = servicePrincipal
+		s.Owners[app.Id] = s.pickUsers(2)
+		s.Owners[servicePrincipal.Id] = s.pickUsers(2)
+	}
+
+	for _, servicePrincipal := range s.ServicePrincipals {
+		for _, appRole := range servicePrincipal.AppRoles {
+			for _, userId := range s.pickUsers(3) {
+				s.AppRoleAssignments[servicePrincipal.Id] = append(s.AppRoleAssignments[servicePrincipal.Id], azure.AppRoleAssignment{
+					AppRoleId:           appRole.Id,
+					Id:                  s.newId(),
+					PrincipalId:         uuid.FromStringOrNil(userId),
+					PrincipalType:       "User",
+					ResourceDisplayName: servicePrincipal.DisplayName,
+					ResourceId:          servicePrincipal.Id,
+				})
+			}
+		}
+	}
+
+	for i := 0; i < options.Devices; i++ {
+		device := azure.Device{
+			DirectoryObject: azure.DirectoryObject{Id: s.newId(), Type: odataDevice},
+			DeviceId:        s.newId(),
+			DisplayName:     fmt.Sprintf("DEVICE-%d", i),
+			AccountEnabled:  true,
+		}
+		s.Devices = append(s.Devices, device)
+		s.objects[device.Id] = device
+		s.Owners[device.Id] = s.pickUsers(1)
+	}
+
+	for i := 0; i < options.Roles; i++ {
+		role := azure.Role{
+			DirectoryObject: azure.DirectoryObject{Id: s.newId()},
+			DisplayName:     fmt.Sprintf("Role %d", i),
+			IsBuiltIn:       true,
+			IsEnabled:       true,
+		}
+		role.TemplateId = role.Id
+		s.Roles = append(s.Roles, role)
+		for _, principalId := range append(s.pickUsers(2), s.pickServicePrincipals(1)...) {
+			s.RoleAssignments = append(s.RoleAssignments, azure.UnifiedRoleAssignment{
+				Entity:           azure.Entity{Id: s.newId()},
+				RoleDefinitionId: role.Id,
+				PrincipalId:      principalId,
+				DirectoryScopeId: "/",
+			})
+		}
+	}
+}
+
+func (s *Tenant) generateResources(options TenantOptions) {
+	root := s.newManagementGroup(s.Id, "Tenant Root Group", "")
+	for i := 0; i < options.ManagementGroups; i++ {
+		parent := s.ManagementGroups[s.rng.Intn(len(s.ManagementGroups))]
+		s.newManagementGroup(fmt.Sprintf("mg-%d", i), fmt.Sprintf("Management Group %d", i), parent.Id)
+	}
+
+	for i := 0; i < options.Subscriptions; i++ {
+		var (
+			subscriptionId = s.newId()
+			subscription   = azure.Subscription{
+				Entity:         azure.Entity{Id: "/subscriptions/" + subscriptionId},
+				DisplayName:    fmt.Sprintf("Subscription %d", i),
+				State:          "Enabled",
+				SubscriptionId: subscriptionId,
+				TenantId:       s.Id,
+			}
+			parent = root
+		)
+		if len(s.ManagementGroups) > 1 {
+			parent = s.ManagementGroups[1+s.rng.Intn(len(s.ManagementGroups)-1)]
+		}
+		s.Subscriptions = append(s.Subscriptions, subscription)
+		s.parents[subscription.Id] = parent.Id
+		s.assignRoles(subscription.Id, subscription.Id, constants.OwnerRoleID, constants.UserAccessAdminRoleID)
+
+		for j := 0; j < options.ResourceGroups; j++ {
+			name := fmt.Sprintf("rg-%d", j)
+			resourceGroup := azure.ResourceGroup{
+				Entity:   azure.Entity{Id: fmt.Sprintf("%s/resourceGroups/%s", subscription.Id, name)},
+				Location: "eastus",
+				Name:     name,
+				Type:     "Microsoft.Resources/resourceGroups",
+			}
+			s.ResourceGroups = append(s.ResourceGroups, resourceGroup)
+			s.assignRoles(subscription.Id, resourceGroup.Id, constants.OwnerRoleID, constants.UserAccessAdminRoleID)
+
+			for k := 0; k < options.KeyVaults; k++ {
+				name := fmt.Sprintf("kv-%d-%d-%d", i, j, k)
+				keyVault := azure.KeyVault{
+					Entity:   azure.Entity{Id: fmt.Sprintf("%s/providers/%s/%s", resourceGroup.Id, typeKeyVault, name)},
+					Location: "eastus",
+					Name:     name,
+					Type:     typeKeyVault,
+				}
+				keyVault.Properties.TenantId = s.Id
+				keyVault.Properties.VaultUri = fmt.Sprintf("https://%s.vault.azure.net/", name)
+				keyVault.Properties.AccessPolicies = s.accessPolicies()
+				s.KeyVaults = append(s.KeyVaults, keyVault)
+				s.assignRoles(subscription.Id, keyVault.Id, constants.OwnerRoleID, constants.UserAccessAdminRoleID, constants.ContributorRoleID, constants.KeyVaultContributorRoleID)
+			}
+
+			for k := 0; k < options.VirtualMachines; k++ {
+				name := fmt.Sprintf("vm-%d-%d-%d", i, j, k)
+				virtualMachine := azure.VirtualMachine{
+					Entity:   azure.Entity{Id: fmt.Sprintf("%s/providers/%s/%s", resourceGroup.Id, typeVirtualMachine, name)},
+					Location: "eastus",
+					Name:     name,
+					Type:     typeVirtualMachine,
+				}
+				virtualMachine.Properties.VMId = s.newId()
+				s.VirtualMachines = append(s.VirtualMachines, virtualMachine)
+				s.assignRoles(subscription.Id, virtualMachine.Id, constants.OwnerRoleID, constants.UserAccessAdminRoleID, constants.ContributorRoleID, constants.AvereContributorRoleID, constants.VirtualMachineAdministratorLoginRoleID)
+			}
+		}
+	}
+}
+
+func (s *Tenant) newManagementGroup(name, displayName, parentId string) azure.ManagementGroup {
+	group := azure.ManagementGroup{
+		Entity: azure.Entity{Id: managementGroupId(name)},
+		Name:   name,
+		Type:   typeManagementGroup,
+	}
+	group.Properties.DisplayName = displayName
+	group.Properties.TenantId = s.Id
+	s.ManagementGroups = append(s.ManagementGroups, group)
+	if parentId != "" {
+		s.parents[group.Id] = parentId
+	}
+	s.assignRoles("", group.Id, constants.OwnerRoleID, constants.UserAccessAdminRoleID)
+	return group
+}
+
+// assignRoles assigns some of the roles at scope, each to a random user or service principal
+func (s *Tenant) assignRoles(subscriptionId, scope string, roleIds ...string) {
+	for _, roleId := range roleIds {
+		var principalId string
+		if i := s.rng.Intn(len(s.Users) + len(s.ServicePrincipals) + 1); i < len(s.Users) {
+			principalId = s.Users[i].Id
+		} else if i -= len(s.Users); i < len(s.ServicePrincipals) {
+			principalId = s.ServicePrincipals[i].Id
+		} else {
+			continue
+		}
+		id := s.newId()
+		s.AzureRoleAssignments = append(s.AzureRoleAssignments, azure.RoleAssignment{
+			Id:   scope + "/providers/Microsoft.Authorization/roleAssignments/" + id,
+			Name: id,
+			Type: "Microsoft.Authorization/roleAssignments",
+			Properties: azure.RoleAssignmentPropertiesWithScope{
+				PrincipalId:      principalId,
+				RoleDefinitionId: subscriptionId + "/providers/Microsoft.Authorization/roleDefinitions/" + roleId,
+				Scope:            scope,
+			},
+		})
+	}
+}
+
+// accessPolicies grants a random user a mix of permissions; only some of them are Get permissions on keys or certificates
+func (s *Tenant) accessPolicies() []azure.AccessPolicyEntry {
+	var (
+		policies    []azure.AccessPolicyEntry
+		permissions = [][]string{{"Get", "List"}, {"List"}, {}}
+	)
+	for _, objectId := range s.pickUsers(2) {
+		policies = append(policies, azure.AccessPolicyEntry{
+			ObjectId: objectId,
+			TenantId: s.Id,
+			Permissions: azure.KeyVaultPermissions{
+				Certificates: permissions[s.rng.Intn(len(permissions))],
+				Keys:         permissions[s.rng.Intn(len(permissions))],
+				Secrets:      []string{"Get"},
+			},
+		})
+	}
+	return policies
+}
+
+// pickUsers returns the ids of up to n distinct random users
+func (s *Tenant) pickUsers(n int) []string {
+	ids := make([]string, len(s.Users))
+	for i, user := range s.Users {
+		ids[i] = user.Id
+	}
+	return s.pick(ids, n)
+}
+
+func (s *Tenant) pickServicePrincipals(n int) []string {
+	ids := make([]string, len(s.ServicePrincipals))
+	for i, servicePrincipal := range s.ServicePrincipals {
+		ids[i] = servicePrincipal.Id
+	}
+	return s.pick(ids, n)
+}
+
+func (s *Tenant) pick(ids []string, n int) []string {
+	if n > len(ids) {
+		n = len(ids)
+	}
+	var picked []string
+	for _, i := range s.rng.Perm(len(ids))[:s.rng.Intn(n+1)] {
+		picked = append(picked, ids[i])
+	}
+	return picked
+}
+
+func (s *Tenant) newId() string {
+	var id uuid.UUID
+	s.rng.Read(id[:])
+	id.SetVersion(uuid.V4)
+	id.SetVariant(uuid.VariantRFC4122)
+	return id.String()
+}
+
+// directoryObject is the JSON of an owner or member
+func (s *Tenant) directoryObject(id string) (json.RawMessage, bool) {
+	if object, ok := s.objects[id]; !ok {
+		return nil, false
+	} else if data, err := json.Marshal(object); err != nil {
+		return nil, false
+	} else {
+		return data, true
+	}
+}
+
+func managementGroupId(name string) string {
+	return "/providers/" + typeManagementGroup + "/" + name
+}
+
+// sameId compares ARM ids, which are case insensitive
+func sameId(a, b string) bool {
+	return strings.EqualFold(strings.TrimSuffix(a, "/"), strings.TrimSuffix(b, "/"))
+}
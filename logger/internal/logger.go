@@ -18,6 +18,8 @@
 package internal
 
 import (
+	"bytes"
+	"encoding/json"
 	"io"
 	"os"
 	"path"
@@ -56,6 +58,8 @@ type Options struct {
 	Writers []io.Writer
 	// Level defines the logging verbosity; defaults to MinInfoLevel
 	Level int
+	// Redact returns values such as secrets that are masked wherever they appear in a log message
+	Redact func() []string
 }
 
 // NewLogger returns a new logr.Logger instance
@@ -64,6 +68,12 @@ func NewLogger(options Options) logr.Logger {
 		options.Writers = append(options.Writers, os.Stderr)
 	}
 
+	if options.Redact != nil {
+		for i, writer := range options.Writers {
+			options.Writers[i] = redactWriter{writer, options.Redact}
+		}
+	}
+
 	if !options.Structured {
 		for i, writer := range options.Writers {
 			options.Writers[i] = zerolog.ConsoleWriter{Out: writer, NoColor: !options.Colors, TimeFormat: time.RFC3339}
@@ -174,3 +184,42 @@ func calcLevel(level int) zerolog.Level {
 	}
 	return zerolog.InfoLevel - zerolog.Level(lvl)
 }
+
+// redactWriter masks sensitive values in each log message before passing it on
+type redactWriter struct {
+	writer io.Writer
+	redact func() []string
+}
+
+func (s redactWriter) mask(p []byte) []byte {
+	for _, value := range s.redact() {
+		if value == "" {
+			continue
+		}
+		p = bytes.ReplaceAll(p, []byte(value), []byte("********"))
+
+		// structured messages contain the value as a JSON string
+		if encoded, err := json.Marshal(value); err == nil {
+			p = bytes.ReplaceAll(p, encoded[1:len(encoded)-1], []byte("********"))
+		}
+	}
+	return p
+}
+
+func (s redactWriter) Write(p []byte) (int, error) {
+	if _, err := s.writer.Write(s.mask(p)); err != nil {
+		return 0, err
+	} else {
+		return len(p), nil
+	}
+}
+
+func (s redactWriter) WriteLevel(level zerolog.Level, p []byte) (int, error) {
+	if levelWriter, ok := s.writer.(zerolog.LevelWriter); !ok {
+		return s.Write(p)
+	} else if _, err := levelWriter.WriteLevel(level, s.mask(p)); err != nil {
+		return 0, err
+	} else {
+		return len(p), nil
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"bytes"
 	"fmt"
 	"io"
+	"strings"
 	"testing"
 	"time"
 
@@ -133,3 +134,23 @@ func logError(logger logr.Logger) func() {
 func now() string {
 	return time.Now().Format(time.RFC3339)
 }
+
+func TestRedact(t *testing.T) {
+	writer := &bytes.Buffer{}
+	options := Options{
+		Structured: true,
+		Writers:    []io.Writer{writer},
+		Redact:     func() []string { return []string{"bar", `I'm`} },
+	}
+	logger := NewLogger(options)
+	logInfo(logger)()
+	logError(logger)()
+
+	got := writer.String()
+	want := fmt.Sprintf(LogInfoTemplate, now(), "\n") + fmt.Sprintf(LogErrorTemplate, now(), "\n")
+	want = strings.ReplaceAll(strings.ReplaceAll(want, "bar", "********"), "I'm", "********")
+
+	if got != want {
+		t.Errorf("got: %v\nwant: %v", got, want)
+	}
+}
@@ -35,6 +35,7 @@ func setupLogger() (*logr.Logger, error) {
 		Structured: config.JsonLogs.Value().(bool),
 		Colors:     true,
 		Writers:    []io.Writer{os.Stderr},
+		Redact:     config.Secrets,
 	}
 
 	// emit logs to file if configured
@@ -39,6 +39,7 @@ func setupLogger() (*logr.Logger, error) {
 			Structured: config.JsonLogs.Value().(bool),
 			Colors:     false,
 			Writers:    []io.Writer{os.Stderr},
+			Redact:     config.Secrets,
 		}
 	)
 
@@ -50,7 +51,9 @@ func setupLogger() (*logr.Logger, error) {
 	// XXX: This is gross, however, reading in the config file when starting the process as a windows service before
 	// initializing the eventLogWriter causes the program to panic. It doesn't make sense as to why it does that but
 	// this call will have to remain here until we can figure out what's going on.
-	config.LoadValues(nil, config.Options())
+	if err := config.LoadValues(nil, config.Options()); err != nil {
+		return nil, err
+	}
 
 	// emit logs to file if configured
 	if fileLogWriter := getFileLogLevelWriter(); fileLogWriter != nil {
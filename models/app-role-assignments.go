@@ -23,6 +23,8 @@ import (
 
 type AppRoleAssignment struct {
 	azure.AppRoleAssignment
-	AppId    string `json:"appId"`
-	TenantId string `json:"tenantId"`
+	AppId        string `json:"appId"`
+	TenantId     string `json:"tenantId"`
+	AppRoleValue string `json:"appRoleValue,omitempty"`
+	IsTierZero   bool   `json:"isTierZero"`
 }
@@ -18,11 +18,15 @@
 package models
 
 import (
+	"github.com/bloodhoundad/azurehound/enums"
 	"github.com/bloodhoundad/azurehound/models/azure"
 )
 
 type App struct {
 	azure.Application
-	TenantId   string `json:"tenantId"`
-	TenantName string `json:"tenantName"`
+	TenantId            string            `json:"tenantId"`
+	TenantName          string            `json:"tenantName"`
+	RequiredPermissions []Permission      `json:"requiredPermissions,omitempty"`
+	Deleted             bool              `json:"deleted,omitempty"`
+	Delta               enums.DeltaChange `json:"delta,omitempty"`
 }
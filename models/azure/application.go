@@ -173,14 +173,18 @@ type Application struct {
 }
 
 type ApplicationList struct {
-	Count    int           `json:"@odata.count,omitempty"`    // The total count of all results
-	NextLink string        `json:"@odata.nextLink,omitempty"` // The URL to use for getting the next set of values.
-	Value    []Application `json:"value"`                     // A list of applications.
+	Count     int           `json:"@odata.count,omitempty"`     // The total count of all results
+	NextLink  string        `json:"@odata.nextLink,omitempty"`  // The URL to use for getting the next set of values.
+	DeltaLink string        `json:"@odata.deltaLink,omitempty"` // The URL to use for getting the changes since this round of a delta query.
+	Value     []Application `json:"value"`                      // A list of applications.
 }
 
 type ApplicationResult struct {
 	Error error
 	Ok    Application
+
+	// Set on the final result of a delta query, which carries no object
+	DeltaLink string
 }
 
 type AppOwnerResult struct {
@@ -30,6 +30,15 @@ type DirectoryObject struct {
 	Id string `json:"id"`
 
 	Type string `json:"@odata.type,omitempty"`
+
+	// Present on objects returned by a delta query when the object was deleted or is no longer visible since the
+	// previous round of the query.
+	Removed *DeltaRemoved `json:"@removed,omitempty"`
+}
+
+type DeltaRemoved struct {
+	// Either "changed" when the object was soft-deleted and can be restored or "deleted" when it was permanently deleted.
+	Reason string `json:"reason"`
 }
 
 type DirectoryObjectList struct {
@@ -283,14 +283,18 @@ type Group struct {
 }
 
 type GroupList struct {
-	Count    int     `json:"@odata.count,omitempty"`    // The total count of all results
-	NextLink string  `json:"@odata.nextLink,omitempty"` // The URL to use for getting the next set of values.
-	Value    []Group `json:"value"`                     // A list of groups.
+	Count     int     `json:"@odata.count,omitempty"`     // The total count of all results
+	NextLink  string  `json:"@odata.nextLink,omitempty"`  // The URL to use for getting the next set of values.
+	DeltaLink string  `json:"@odata.deltaLink,omitempty"` // The URL to use for getting the changes since this round of a delta query.
+	Value     []Group `json:"value"`                      // A list of groups.
 }
 
 type GroupResult struct {
 	Error error
 	Ok    Group
+
+	// Set on the final result of a delta query, which carries no object
+	DeltaLink string
 }
 
 type GroupOwnerResult struct {
@@ -0,0 +1,67 @@
+// Copyright (C) 2022 Specter Ops, Inc.
+//
+// This file is part of AzureHound.
+//
+// AzureHound is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// AzureHound is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package azure
+
+import "encoding/json"
+
+type ResourceGraphQueryOptions struct {
+	// Continuation token for pagination, capturing the next page size and offset, as well as the context of the query.
+	SkipToken string `json:"$skipToken,omitempty"`
+
+	// The maximum number of rows that the query should return.
+	Top int32 `json:"$top,omitempty"`
+
+	// Defines in which format query result returned. Either "table" or "objectArray".
+	ResultFormat string `json:"resultFormat,omitempty"`
+}
+
+type ResourceGraphQueryRequest struct {
+	// Azure subscriptions against which to execute the query.
+	Subscriptions []string `json:"subscriptions,omitempty"`
+
+	// Azure management groups against which to execute the query.
+	ManagementGroups []string `json:"managementGroups,omitempty"`
+
+	// The resources query.
+	Query string `json:"query"`
+
+	// The query evaluation options.
+	Options ResourceGraphQueryOptions `json:"options,omitempty"`
+}
+
+type ResourceGraphQueryResponse struct {
+	// Number of total records matching the query.
+	TotalRecords int64 `json:"totalRecords"`
+
+	// Number of records returned in the current response.
+	Count int64 `json:"count"`
+
+	// Indicates whether the query results are truncated.
+	ResultTruncated string `json:"resultTruncated"`
+
+	// When present, the value can be passed to a subsequent query call to retrieve the next page of results.
+	SkipToken string `json:"$skipToken,omitempty"`
+
+	// Query output in an object array format.
+	Data []json.RawMessage `json:"data"`
+}
+
+type ResourceGraphResult struct {
+	Error error
+	Ok    json.RawMessage
+}
@@ -181,14 +181,18 @@ type ServicePrincipal struct {
 }
 
 type ServicePrincipalList struct {
-	Count    int                `json:"@odata.count,omitempty"`    // The total count of all results
-	NextLink string             `json:"@odata.nextLink,omitempty"` // The URL to use for getting the next set of values.
-	Value    []ServicePrincipal `json:"value"`                     // A list of ServicePrincipals.
+	Count     int                `json:"@odata.count,omitempty"`     // The total count of all results
+	NextLink  string             `json:"@odata.nextLink,omitempty"`  // The URL to use for getting the next set of values.
+	DeltaLink string             `json:"@odata.deltaLink,omitempty"` // The URL to use for getting the changes since this round of a delta query.
+	Value     []ServicePrincipal `json:"value"`                      // A list of ServicePrincipals.
 }
 
 type ServicePrincipalResult struct {
 	Error error
 	Ok    ServicePrincipal
+
+	// Set on the final result of a delta query, which carries no object
+	DeltaLink string
 }
 
 type ServicePrincipalOwnerResult struct {
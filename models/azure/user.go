@@ -467,12 +467,16 @@ type User struct {
 }
 
 type UserList struct {
-	Count    int    `json:"@odata.count,omitempty"`    // The total count of all results
-	NextLink string `json:"@odata.nextLink,omitempty"` // The URL to use for getting the next set of values.
-	Value    []User `json:"value"`                     // A list of users.
+	Count     int    `json:"@odata.count,omitempty"`     // The total count of all results
+	NextLink  string `json:"@odata.nextLink,omitempty"`  // The URL to use for getting the next set of values.
+	DeltaLink string `json:"@odata.deltaLink,omitempty"` // The URL to use for getting the changes since this round of a delta query.
+	Value     []User `json:"value"`                      // A list of users.
 }
 
 type UserResult struct {
 	Error error
 	Ok    User
+
+	// Set on the final result of a delta query, which carries no object
+	DeltaLink string
 }
@@ -17,10 +17,15 @@
 
 package models
 
-import "github.com/bloodhoundad/azurehound/models/azure"
+import (
+	"github.com/bloodhoundad/azurehound/enums"
+	"github.com/bloodhoundad/azurehound/models/azure"
+)
 
 type ServicePrincipal struct {
 	azure.ServicePrincipal
-	TenantId   string `json:"tenantId"`
-	TenantName string `json:"tenantName"`
+	TenantId   string            `json:"tenantId"`
+	TenantName string            `json:"tenantName"`
+	Deleted    bool              `json:"deleted,omitempty"`
+	Delta      enums.DeltaChange `json:"delta,omitempty"`
 }
@@ -18,11 +18,14 @@
 package models
 
 import (
+	"github.com/bloodhoundad/azurehound/enums"
 	"github.com/bloodhoundad/azurehound/models/azure"
 )
 
 type User struct {
 	azure.User
-	TenantId   string `json:"tenantId"`
-	TenantName string `json:"tenantName"`
+	TenantId   string            `json:"tenantId"`
+	TenantName string            `json:"tenantName"`
+	Deleted    bool              `json:"deleted,omitempty"`
+	Delta      enums.DeltaChange `json:"delta,omitempty"`
 }